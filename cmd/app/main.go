@@ -2,6 +2,7 @@ package main
 
 import (
 	"effective-mobile/internal/app"
+	"os"
 
 	_ "github.com/swaggo/http-swagger"
 )
@@ -9,6 +10,17 @@ import (
 // @title Online song library
 // @version beta 0.1
 // @description API Server for  online song library
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer token: "Bearer <jwt>"
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		app.Import(os.Args[2:])
+		return
+	}
 	app.Run()
 }
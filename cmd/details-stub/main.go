@@ -0,0 +1,23 @@
+package main
+
+import (
+	"effective-mobile/internal/services/details/stub"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// A local stand-in for the external music info API used by POST /song/add.
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8081", "address to listen on")
+	flag.Parse()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	log.Info("starting details stub", slog.String("addr", *addr))
+
+	if err := http.ListenAndServe(*addr, stub.NewHandler(log)); err != nil {
+		log.Error("details stub stopped", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
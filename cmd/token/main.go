@@ -0,0 +1,51 @@
+package main
+
+import (
+	"effective-mobile/internal/services/auth"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Issues an HS256 token signed with AUTH_JWT_SECRET for local runs.
+func main() {
+	_ = godotenv.Load()
+
+	subject := flag.String("sub", "local", "subject of the token")
+	role := flag.String("role", string(auth.RoleEditor), "role: reader, editor or admin")
+	ttl := flag.Duration("ttl", time.Hour, "lifetime of the token")
+	secret := flag.String("secret", os.Getenv("AUTH_JWT_SECRET"), "HS256 secret")
+	flag.Parse()
+
+	if *secret == "" {
+		log.Fatal("no secret: set AUTH_JWT_SECRET or pass -secret")
+	}
+	if _, err := auth.ParseRole(*role); err != nil {
+		log.Fatal(err)
+	}
+
+	now := time.Now()
+	token, err := auth.SignHS256(*secret, auth.Claims{
+		Subject:   *subject,
+		Role:      *role,
+		Issuer:    os.Getenv("AUTH_JWT_ISSUER"),
+		Audience:  audience(os.Getenv("AUTH_JWT_AUDIENCE")),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(*ttl).Unix(),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(token)
+}
+
+func audience(aud string) auth.Audience {
+	if aud == "" {
+		return nil
+	}
+	return auth.Audience{aud}
+}
@@ -15,326 +15,4190 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/song/library": {
+        "/albums": {
             "get": {
-                "description": "Retrieves the user's entire song library, optionally filtered by group, song, or release date.",
+                "description": "Returns albums ordered by title.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "albums"
                 ],
-                "summary": "Retrieve the user's song library",
+                "summary": "List albums",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Group name",
-                        "name": "group",
+                        "type": "integer",
+                        "description": "Albums per page (20 by default, at most 100)",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Song name",
-                        "name": "song",
+                        "type": "integer",
+                        "description": "Number of albums to skip",
+                        "name": "offset",
                         "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of albums",
+                        "schema": {
+                            "$ref": "#/definitions/receive_albums.AlbumsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
                     },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Release date (YYYY-MM-DD)",
-                        "name": "releaseDate",
-                        "in": "query"
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an empty album of a group. Songs are added with POST /albums/{id}/tracks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Add an album",
+                "parameters": [
+                    {
+                        "description": "Information about the album, releaseDate in YYYY-MM-DD",
+                        "name": "album",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/add_album.Album"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "List of songs"
+                    "201": {
+                        "description": "The created album",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Album"
+                        }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid request parameters",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The group was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/song/lyrics": {
+        "/albums/{id}": {
             "get": {
-                "description": "Returns the lyrics of the song, divided into pages.",
+                "description": "Returns the album with the given ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "lyrics"
+                    "albums"
                 ],
-                "summary": "Get the lyrics of the song",
+                "summary": "Get an album",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "group",
-                        "name": "group",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The album",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Album"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "song",
-                        "name": "song",
-                        "in": "query",
-                        "required": true
+                    "400": {
+                        "description": "Invalid album id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The album was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
                     },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Page number (default is 1)",
-                        "name": "page",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes the album with the given ID. Its songs stay in the library.",
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Delete an album",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Number of verses per page (2 by default))",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Lyrics by page",
+                    "204": {
+                        "description": "The album was successfully deleted"
+                    },
+                    "400": {
+                        "description": "Invalid album id",
                         "schema": {
-                            "$ref": "#/definitions/receive_lyrics.SongLyricsResponse"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request parameters",
+                    "401": {
+                        "description": "Authentication required",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The album was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/song/remove": {
-            "delete": {
-                "description": "Deletes a song from the repository by the name of the band and the name of the song.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/albums/{id}/tracks": {
+            "get": {
+                "description": "Returns the album and its songs ordered by track number.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "song"
+                    "albums"
                 ],
-                "summary": "Delete a song",
+                "summary": "Get the tracklist of an album",
                 "parameters": [
                     {
-                        "description": "Data for deleting a song",
-                        "name": "song",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/remove_song.Song"
-                        }
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "The song was successfully deleted",
+                        "description": "The tracklist",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/receive_album_tracks.TracklistResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request parameters",
+                        "description": "Invalid album id",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
-                        "description": "The song was not found",
+                        "description": "The album was not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/song/update": {
-            "patch": {
-                "description": "Updates the song data in the repository based on the original song data and new data.",
-                "consumes": [
-                    "application/json"
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "produces": [
+                "description": "Numbers the tracks in the given order. The list must contain every song of the album exactly once.",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "songs"
+                    "albums"
                 ],
-                "summary": "Update the song data",
+                "summary": "Reorder the tracks of an album",
                 "parameters": [
                     {
-                        "description": "Data for updating the song",
-                        "name": "updateRequest",
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Song IDs in the new order",
+                        "name": "order",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/update_song_data.UpdateSongRequest"
+                            "$ref": "#/definitions/reorder_album_tracks.ReorderTracksRequest"
                         }
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "The song data has been successfully updated",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "The tracks were reordered"
                     },
                     "400": {
                         "description": "Invalid request parameters",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The album was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The list does not match the songs of the album",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/songs": {
+            },
             "post": {
-                "description": "Adds a song with information about the band, name, release date, lyrics and a link to YouTube",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "produces": [
+                "description": "Inserts the song at the given track number and moves the following tracks down, or appends it when no number is given.",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "song"
+                    "albums"
                 ],
-                "summary": "Add a new song",
+                "summary": "Add a song to an album",
                 "parameters": [
                     {
-                        "description": "Information about the song",
-                        "name": "song",
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Song and track number",
+                        "name": "track",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/add_song.Song"
+                            "$ref": "#/definitions/attach_album_track.AttachTrackRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Song added successfully",
+                    "204": {
+                        "description": "The song was added to the album"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Invalid JSON format",
+                    "401": {
+                        "description": "Authentication required",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The album or song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The song is already on the album",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "415": {
                         "description": "Content-Type header is not application/json",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
-        }
+        },
+        "/albums/{id}/tracks/{songId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the song from the album and renumbers the following tracks. The song stays in the library.",
+                "tags": [
+                    "albums"
+                ],
+                "summary": "Remove a song from an album",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Album ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "songId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was removed from the album"
+                    },
+                    "400": {
+                        "description": "Invalid album or song id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The album was not found or the song is not on it",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "description": "Returns groups ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "List groups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Groups per page (20 by default, at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of groups to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of groups",
+                        "schema": {
+                            "$ref": "#/definitions/receive_groups.GroupsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a group (artist) that songs can refer to.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Add a group",
+                "parameters": [
+                    {
+                        "description": "Information about the group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/add_group.Group"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created group",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A group with this name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}": {
+            "get": {
+                "description": "Returns the group with the given ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get a group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The group",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Group"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid group id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The group was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes the group with the given ID. Groups that still have songs or albums cannot be deleted.",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete a group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The group was successfully deleted"
+                    },
+                    "400": {
+                        "description": "Invalid group id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The admin role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The group was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The group still has songs or albums",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates only the fields present in the body. Renaming a group renames it for all of its songs.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Update a group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/update_group.UpdateGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The group was successfully updated"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The group was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "A group with this name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/songs": {
+            "get": {
+                "description": "Returns the songs of the group page by page. Accepts the same filter, sort and page parameters as GET /songs.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "List the songs of a group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Songs per page (20 by default, at most 100)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated field:asc|desc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of songs",
+                        "schema": {
+                            "$ref": "#/definitions/listing.SongsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The group was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Returns 200 as long as the process serves HTTP.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "The process is alive",
+                        "schema": {
+                            "$ref": "#/definitions/liveness.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists": {
+            "get": {
+                "description": "Returns public playlists and the private playlists of the caller, ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "List playlists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only list the playlists of this user",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Playlists per page (20 by default, at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of playlists to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of playlists",
+                        "schema": {
+                            "$ref": "#/definitions/receive_playlists.PlaylistsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an empty playlist owned by the caller. Playlists are private unless public is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Add a playlist",
+                "parameters": [
+                    {
+                        "description": "Information about the playlist",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/add_playlist.Playlist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "The created playlist",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Playlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{id}": {
+            "get": {
+                "description": "Returns the playlist with the given ID. Private playlists are only visible to their owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Get a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The playlist",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Playlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid playlist id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The playlist was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes the playlist. The songs stay in the library. Only the owner can delete a playlist.",
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Delete a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The playlist was deleted"
+                    },
+                    "400": {
+                        "description": "Invalid playlist id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The playlist belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The playlist was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates only the fields present in the request body. Only the owner can change a playlist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Update a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/update_playlist.UpdatePlaylistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The updated playlist",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Playlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The playlist belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The playlist was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{id}/songs": {
+            "get": {
+                "description": "Returns the playlist with its songs in order. Private playlists are only visible to their owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Get the songs of a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The playlist and its songs",
+                        "schema": {
+                            "$ref": "#/definitions/receive_playlist_songs.PlaylistSongsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid playlist id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The playlist was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Inserts the song at the given position and moves the following songs down, or appends it when no position is given. A song can be in a playlist only once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Add a song to a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Song and position",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/add_playlist_song.AddSongRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was added to the playlist"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The playlist belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The playlist or song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The song is already in the playlist",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/{id}/songs/{songId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the song from the playlist and moves the following songs up. The song stays in the library.",
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Remove a song from a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "songId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was removed from the playlist"
+                    },
+                    "400": {
+                        "description": "Invalid playlist or song id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The playlist belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The playlist was not found or the song is not in it",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves the song to the given position and shifts the songs in between. Positions past the end move the song to the end.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Move a song within a playlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Playlist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "songId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New position",
+                        "name": "position",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/move_playlist_song.MoveSongRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was moved"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The playlist belongs to another user",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The playlist was not found or the song is not in it",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks the storage, the database schema and the details provider and reports every check with its latency. The service is ready, or degraded when only the details provider fails; it is unavailable when the storage or the schema fails and shutting down once it has been told to stop.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "The service can take traffic",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "The service is unavailable or shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/song/add": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a song with information about the band, name, release date, lyrics and a link to YouTube.\nA group has one song with a given name, compared case-insensitively; adding it again fails\nwith 409 unless upsert=true, which overwrites the existing song. Retries sent with the same\nIdempotency-Key replay the first response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "song"
+                ],
+                "summary": "Add a new song",
+                "parameters": [
+                    {
+                        "description": "Information about the song",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/add_song.Song"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Overwrite the song if it already exists",
+                        "name": "upsert",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key that makes retries safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song updated successfully, Location header points to /songs/{id}",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "201": {
+                        "description": "Song added successfully, Location header points to /songs/{id}",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The song already exists or a request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "The details provider is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/song/library": {
+            "get": {
+                "description": "Retrieves the user's song library page by page, optionally filtered by group, song, release date, lyrics and link presence.\nalbum, group, song, releaseDate and lyrics may be repeated; a song matches any of the repeated values.\nPages are selected with page/pageSize, limit/offset or the opaque cursor from the next/prev links.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Retrieve the user's song library",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Album ID",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Song name",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How group and song are compared: exact (default), prefix or contains, the last two ignore case",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Release date (YYYY-MM-DD)",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest release date (YYYY-MM-DD), inclusive",
+                        "name": "releasedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest release date (YYYY-MM-DD), inclusive",
+                        "name": "releasedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) lyrics",
+                        "name": "hasLyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a YouTube link",
+                        "name": "hasLink",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Words that must all occur in the lyrics, ignoring case",
+                        "name": "lyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Songs per page (20 by default, at most 100)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Alias of pageSize",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of songs to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a next/prev link, requires sorting by id",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated field:asc|desc, fields: id, group, song, releaseDate, lyrics, youtubeLink",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of songs",
+                        "schema": {
+                            "$ref": "#/definitions/listing.SongsResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "The cached copy is current"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/song/lyrics": {
+            "get": {
+                "description": "Returns the lyrics of the song, divided into pages.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lyrics"
+                ],
+                "summary": "Get the lyrics of the song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "group",
+                        "name": "group",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "song",
+                        "name": "song",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page number (default is 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of verses per page (2 by default))",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lyrics by page",
+                        "schema": {
+                            "$ref": "#/definitions/receive_lyrics.SongLyricsResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "The cached copy is current"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/song/remove": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deprecated, use DELETE /songs/{id}. Moves a song to the trash by the name of the band and the name of the song.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "song"
+                ],
+                "summary": "Delete a song",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Data for deleting a song",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/remove_song.Song"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The song was successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "The song has changed since the version in If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/song/update": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deprecated, use PATCH /songs/{id}. Updates the song data in the repository based on the original song data and new data.\nEmpty or left out fields stay unchanged; only null clears release_date, lyrics or youtube_link. verses edits single verses like PATCH /songs/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Update the song data",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Data for updating the song",
+                        "name": "updateRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/update_song_data.UpdateSongRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song data has been successfully updated",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The group already has a song with that name",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "The song has changed since the version in If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "Retrieves the user's song library page by page, optionally filtered by group, song, release date, lyrics and link presence.\nalbum, group, song, releaseDate and lyrics may be repeated; a song matches any of the repeated values.\nPages are selected with page/pageSize, limit/offset or the opaque cursor from the next/prev links.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Retrieve the user's song library",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Album ID",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Song name",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How group and song are compared: exact (default), prefix or contains, the last two ignore case",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Release date (YYYY-MM-DD)",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest release date (YYYY-MM-DD), inclusive",
+                        "name": "releasedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest release date (YYYY-MM-DD), inclusive",
+                        "name": "releasedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) lyrics",
+                        "name": "hasLyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a YouTube link",
+                        "name": "hasLink",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Words that must all occur in the lyrics, ignoring case",
+                        "name": "lyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Songs per page (20 by default, at most 100)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Alias of pageSize",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of songs to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from a next/prev link, requires sorting by id",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated field:asc|desc, fields: id, group, song, releaseDate, lyrics, youtubeLink",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of songs",
+                        "schema": {
+                            "$ref": "#/definitions/listing.SongsResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "The cached copy is current"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a song with information about the band, name, release date, lyrics and a link to YouTube.\nA group has one song with a given name, compared case-insensitively; adding it again fails\nwith 409 unless upsert=true, which overwrites the existing song. Retries sent with the same\nIdempotency-Key replay the first response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "song"
+                ],
+                "summary": "Add a new song",
+                "parameters": [
+                    {
+                        "description": "Information about the song",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/add_song.Song"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Overwrite the song if it already exists",
+                        "name": "upsert",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key that makes retries safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song updated successfully, Location header points to /songs/{id}",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "201": {
+                        "description": "Song added successfully, Location header points to /songs/{id}",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The song already exists or a request with the Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "The Idempotency-Key was used for a different request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "The details provider is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/export": {
+            "get": {
+                "description": "Streams every song matching the filters of GET /songs in ID order as a file download.\ncsv and ndjson list the columns and fields accepted by POST /songs/import, so an export can be\nimported again; json is an array of songs; m3u is a playlist of the songs with a YouTube link.",
+                "produces": [
+                    "text/csv",
+                    "application/json",
+                    "application/x-ndjson",
+                    "audio/x-mpegurl"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Export the song library",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv, json (default), ndjson or m3u",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Album ID",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Group name",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Song name",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How group and song are compared: exact (default), prefix or contains, the last two ignore case",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Release date (YYYY-MM-DD)",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest release date (YYYY-MM-DD), inclusive",
+                        "name": "releasedFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest release date (YYYY-MM-DD), inclusive",
+                        "name": "releasedTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) lyrics",
+                        "name": "hasLyrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only songs with (true) or without (false) a YouTube link",
+                        "name": "hasLink",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Words that must all occur in the lyrics, ignoring case",
+                        "name": "lyrics",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported songs",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Imports songs from the request body: CSV with a header row naming the group, song, releaseDate,\nlyrics and youtubeLink columns, or NDJSON with one song object per line. Only group and song are\nrequired; missing details are fetched from the music info API unless enrich=false.\nSongs that already exist are skipped unless upsert=true. The report lists every row with its\nstatus (created, updated, skipped or failed); a file with failed rows still returns 200.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Import songs",
+                "parameters": [
+                    {
+                        "description": "CSV or NDJSON file",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or ndjson, overrides the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Overwrite songs that already exist",
+                        "name": "upsert",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Fetch missing details from the music info API (true by default)",
+                        "name": "enrich",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import report",
+                        "schema": {
+                            "$ref": "#/definitions/importer.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameters or an unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "The file is too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unknown file format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/search": {
+            "get": {
+                "description": "Finds songs whose name or lyrics contain the words of the query, most relevant first.\nThe query supports \"quoted phrases\", OR and -excluded words.\nEvery result lists the matching verses with the matched words wrapped in \u003cmark\u003e\u003c/mark\u003e.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Search songs by lyrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Results per page (20 by default, at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Search results",
+                        "schema": {
+                            "$ref": "#/definitions/search_songs.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/trash": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the songs in the trash, most recently deleted first. Deleted songs are purged for good\nonce they have been in the trash for longer than the configured retention.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "List deleted songs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Songs per page (20 by default, at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of songs to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of deleted songs",
+                        "schema": {
+                            "$ref": "#/definitions/receive_trash.TrashResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Returns the song with the given ID, including its lyrics and link.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Get a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The song",
+                        "schema": {
+                            "$ref": "#/definitions/storage.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the song, for If-Match"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "The cached copy is current"
+                    },
+                    "400": {
+                        "description": "Invalid song id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces every field of the song with the given ID. Omitted optional fields are cleared.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Replace a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New song data, releaseDate in YYYY-MM-DD",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/replace_song.ReplaceSongRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was successfully replaced"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The group already has a song with that name",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "The song has changed since the version in If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves the song with the given ID to the trash and takes it off its albums and playlists.\nIt can be restored with POST /songs/{id}/restore until the trash is purged.",
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Delete a song by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was successfully deleted"
+                    },
+                    "400": {
+                        "description": "Invalid song id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "The song has changed since the version in If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates only the fields present in the body of the song with the given ID. null clears\nreleaseDate, lyrics or youtubeLink. verses edits single verses of the lyrics (verses are\nseparated by a blank line and numbered from 1): replace sets verse N to text, insert puts text\nbefore verse N or after the last one, delete removes verse N. The edits apply in order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Partially update a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields to update, releaseDate in YYYY-MM-DD",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/patch_song.PatchSongRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was successfully updated"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The group already has a song with that name",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "The song has changed since the version in If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the recorded changes of the song with the given ID, newest first: the values before and\nafter each change, who made it and in which request. The action is created, updated, deleted,\nrestored, reverted, purged or baseline for songs that existed before the history was recorded.\nThe history of deleted and purged songs is kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Song history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revisions per page (20 by default, at most 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of revisions to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of revisions",
+                        "schema": {
+                            "$ref": "#/definitions/song_history.HistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song has no history",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Takes the song with the given ID out of the trash. The song does not return to the albums and\nplaylists it was taken off. It fails when its group got a song with the same name meanwhile.",
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Restore a deleted song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was restored"
+                    },
+                    "400": {
+                        "description": "Invalid song id",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song is not in the trash",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The group already has a song with this name",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Overwrites the song with the given ID with the values it had after the given revision of\nGET /songs/{id}/history. The revert is recorded as a new revision. Deleted songs must be\nrestored first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "songs"
+                ],
+                "summary": "Revert a song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Revision to revert to",
+                        "name": "revision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/revert_song.RevertSongRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "The song was reverted"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "The editor role is required",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "The song or the revision was not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "The revision deleted the song, or the group already has a song with that name",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Content-Type header is not application/json",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "add_album.Album": {
+            "type": "object",
+            "required": [
+                "groupId",
+                "title"
+            ],
+            "properties": {
+                "coverUrl": {
+                    "type": "string"
+                },
+                "groupId": {
+                    "type": "integer"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "add_group.Group": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "description": {
+                    "type": "string"
+                },
+                "formedYear": {
+                    "type": "integer",
+                    "maximum": 9999,
+                    "minimum": 0
+                },
+                "genres": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "add_playlist.Playlist": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "public": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "add_playlist_song.AddSongRequest": {
+            "type": "object",
+            "required": [
+                "songId"
+            ],
+            "properties": {
+                "position": {
+                    "description": "Position is where to insert the song; omitted appends it.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "songId": {
+                    "type": "integer"
+                }
+            }
+        },
         "add_song.Song": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "attach_album_track.AttachTrackRequest": {
+            "type": "object",
+            "required": [
+                "songId"
+            ],
+            "properties": {
+                "number": {
+                    "description": "Number is the track number to insert the song at; omitted appends it.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "songId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Result"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ready"
+                }
+            }
+        },
+        "health.Result": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latencyMs": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "status": {
+                    "type": "string",
+                    "example": "up"
+                }
+            }
+        },
+        "importer.Report": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/importer.RowResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "importer.RowResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validate.FieldError"
+                    }
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "listing.Links": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                }
+            }
+        },
+        "listing.SongsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.Song"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "links": {
+                    "$ref": "#/definitions/listing.Links"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "liveness.Response": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "move_playlist_song.MoveSongRequest": {
+            "type": "object",
+            "properties": {
+                "position": {
+                    "description": "Position is the new position of the song; 0 moves it to the end.",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "patch_song.PatchSongRequest": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "lyrics": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "verses": {
+                    "description": "Verses edits the lyrics verse by verse, in order; it cannot be\ncombined with lyrics.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.VerseEdit"
+                    }
+                },
+                "youtubeLink": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "problem.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validate.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "receive_album_tracks.TracklistResponse": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "$ref": "#/definitions/storage.Album"
+                },
+                "tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.Track"
+                    }
+                }
+            }
+        },
+        "receive_albums.AlbumsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.Album"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "receive_groups.GroupsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.Group"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "receive_lyrics.SongLyricsResponse": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "lyrics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "receive_playlist_songs.PlaylistSongsResponse": {
+            "type": "object",
+            "properties": {
+                "playlist": {
+                    "$ref": "#/definitions/storage.Playlist"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.Entry"
+                    }
+                }
+            }
+        },
+        "receive_playlists.PlaylistsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.Playlist"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "receive_trash.TrashResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.DeletedSong"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "remove_song.Song": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "reorder_album_tracks.ReorderTracksRequest": {
+            "type": "object",
+            "required": [
+                "songIds"
+            ],
+            "properties": {
+                "songIds": {
+                    "description": "SongIDs lists every song of the album in the new track order.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "replace_song.ReplaceSongRequest": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "lyrics": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "youtubeLink": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "revert_song.RevertSongRequest": {
+            "type": "object",
+            "required": [
+                "revision"
+            ],
+            "properties": {
+                "revision": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "search_songs.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.SearchResult"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "song_history.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.SongRevision"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "storage.Album": {
+            "type": "object",
+            "properties": {
+                "coverUrl": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "groupId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "releaseDate": {
+                    "description": "ReleaseDate is in the storage format (YYYY-MM-DD).",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.DeletedSong": {
             "type": "object",
             "properties": {
+                "deletedAt": {
+                    "type": "string"
+                },
                 "group": {
                     "type": "string"
                 },
+                "groupId": {
+                    "description": "GroupID is set by the storage; songs are assigned to groups by GroupName,\nand a group with that name is created when it does not exist yet.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lyrics": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
                 "song": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version is set by the storage; it starts at 1 and goes up with every\nwrite that changes the song, renaming its group included.",
+                    "type": "integer"
+                },
+                "youtubeLink": {
+                    "type": "string"
                 }
             }
         },
-        "receive_lyrics.SongLyricsResponse": {
+        "storage.Entry": {
             "type": "object",
             "properties": {
-                "current_page": {
+                "group": {
+                    "type": "string"
+                },
+                "groupId": {
+                    "description": "GroupID is set by the storage; songs are assigned to groups by GroupName,\nand a group with that name is created when it does not exist yet.",
+                    "type": "integer"
+                },
+                "id": {
                     "type": "integer"
                 },
                 "lyrics": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is set by the storage; it starts at 1 and goes up with every\nwrite that changes the song, renaming its group included.",
+                    "type": "integer"
+                },
+                "youtubeLink": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.Group": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "formedYear": {
+                    "type": "integer"
+                },
+                "genres": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "total_pages": {
+                "id": {
                     "type": "integer"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "remove_song.Song": {
+        "storage.Playlist": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the name of the user the playlist belongs to.",
+                    "type": "string"
+                },
+                "public": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "storage.SearchResult": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "groupId": {
+                    "description": "GroupID is set by the storage; songs are assigned to groups by GroupName,\nand a group with that name is created when it does not exist yet.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lyrics": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "Rank orders the results, higher is more relevant.",
+                    "type": "number"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "snippets": {
+                    "description": "Snippets are the matching verses with the matched words wrapped in\nHighlightStart and HighlightStop.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.Snippet"
+                    }
+                },
+                "song": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is set by the storage; it starts at 1 and goes up with every\nwrite that changes the song, renaming its group included.",
+                    "type": "integer"
+                },
+                "youtubeLink": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.Snippet": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "verse": {
+                    "description": "Verse is the 1-based number of the verse in the lyrics.",
+                    "type": "integer"
+                }
+            }
+        },
+        "storage.Song": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "groupId": {
+                    "description": "GroupID is set by the storage; songs are assigned to groups by GroupName,\nand a group with that name is created when it does not exist yet.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lyrics": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is set by the storage; it starts at 1 and goes up with every\nwrite that changes the song, renaming its group included.",
+                    "type": "integer"
+                },
+                "youtubeLink": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.SongRevision": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "new": {
+                    "$ref": "#/definitions/storage.Song"
+                },
+                "old": {
+                    "$ref": "#/definitions/storage.Song"
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "songId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "storage.Track": {
             "type": "object",
             "properties": {
                 "group": {
                     "type": "string"
                 },
+                "groupId": {
+                    "description": "GroupID is set by the storage; songs are assigned to groups by GroupName,\nand a group with that name is created when it does not exist yet.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lyrics": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
                 "song": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version is set by the storage; it starts at 1 and goes up with every\nwrite that changes the song, renaming its group included.",
+                    "type": "integer"
+                },
+                "youtubeLink": {
+                    "type": "string"
+                }
+            }
+        },
+        "storage.VerseEdit": {
+            "type": "object",
+            "required": [
+                "op",
+                "verse"
+            ],
+            "properties": {
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "replace",
+                        "insert",
+                        "delete"
+                    ]
+                },
+                "text": {
+                    "description": "Text is the new verse for replace and insert.",
+                    "type": "string"
+                },
+                "verse": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "update_group.UpdateGroupRequest": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "description": {
+                    "type": "string"
+                },
+                "formedYear": {
+                    "type": "integer",
+                    "maximum": 9999,
+                    "minimum": 0
+                },
+                "genres": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "update_playlist.UpdatePlaylistRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "public": {
+                    "type": "boolean"
                 }
             }
         },
         "update_song_data.UpdateSongRequest": {
             "type": "object",
+            "required": [
+                "firstGroup",
+                "firstSong"
+            ],
             "properties": {
                 "firstGroup": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "firstSong": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "group": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "lyrics": {
                     "type": "string"
                 },
                 "release_date": {
                     "type": "string"
                 },
                 "song": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "verses": {
+                    "description": "Verses edits the lyrics verse by verse like PATCH /songs/{id}.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storage.VerseEdit"
+                    }
+                },
+                "youtube_link": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "validate.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
                     "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Bearer token: \"Bearer \u003cjwt\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
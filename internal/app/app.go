@@ -4,13 +4,65 @@ import (
 	"context"
 	_ "effective-mobile/docs"
 	"effective-mobile/internal/config"
+	addAlbum "effective-mobile/internal/http-server/handlers/add-album"
+	addGroup "effective-mobile/internal/http-server/handlers/add-group"
+	addPlaylist "effective-mobile/internal/http-server/handlers/add-playlist"
+	addPlaylistSong "effective-mobile/internal/http-server/handlers/add-playlist-song"
 	addSong "effective-mobile/internal/http-server/handlers/add-song"
+	attachAlbumTrack "effective-mobile/internal/http-server/handlers/attach-album-track"
+	deleteAlbum "effective-mobile/internal/http-server/handlers/delete-album"
+	deleteGroup "effective-mobile/internal/http-server/handlers/delete-group"
+	deletePlaylist "effective-mobile/internal/http-server/handlers/delete-playlist"
+	deleteSong "effective-mobile/internal/http-server/handlers/delete-song"
+	detachAlbumTrack "effective-mobile/internal/http-server/handlers/detach-album-track"
+	exportSongs "effective-mobile/internal/http-server/handlers/export-songs"
+	getAlbum "effective-mobile/internal/http-server/handlers/get-album"
+	getGroup "effective-mobile/internal/http-server/handlers/get-group"
+	getPlaylist "effective-mobile/internal/http-server/handlers/get-playlist"
+	getSong "effective-mobile/internal/http-server/handlers/get-song"
+	importSongs "effective-mobile/internal/http-server/handlers/import-songs"
+	"effective-mobile/internal/http-server/handlers/liveness"
+	movePlaylistSong "effective-mobile/internal/http-server/handlers/move-playlist-song"
+	patchSong "effective-mobile/internal/http-server/handlers/patch-song"
+	"effective-mobile/internal/http-server/handlers/readiness"
+	receiveAlbumTracks "effective-mobile/internal/http-server/handlers/receive-album-tracks"
+	receiveAlbums "effective-mobile/internal/http-server/handlers/receive-albums"
+	receiveGroupSongs "effective-mobile/internal/http-server/handlers/receive-group-songs"
+	receiveGroups "effective-mobile/internal/http-server/handlers/receive-groups"
 	receiveLibrary "effective-mobile/internal/http-server/handlers/receive-library"
 	receiveLyrics "effective-mobile/internal/http-server/handlers/receive-lyrics"
+	receivePlaylistSongs "effective-mobile/internal/http-server/handlers/receive-playlist-songs"
+	receivePlaylists "effective-mobile/internal/http-server/handlers/receive-playlists"
+	receiveTrash "effective-mobile/internal/http-server/handlers/receive-trash"
+	removePlaylistSong "effective-mobile/internal/http-server/handlers/remove-playlist-song"
 	removeSong "effective-mobile/internal/http-server/handlers/remove-song"
+	reorderAlbumTracks "effective-mobile/internal/http-server/handlers/reorder-album-tracks"
+	replaceSong "effective-mobile/internal/http-server/handlers/replace-song"
+	restoreSong "effective-mobile/internal/http-server/handlers/restore-song"
+	revertSong "effective-mobile/internal/http-server/handlers/revert-song"
+	searchSongs "effective-mobile/internal/http-server/handlers/search-songs"
+	songHistory "effective-mobile/internal/http-server/handlers/song-history"
+	updateGroup "effective-mobile/internal/http-server/handlers/update-group"
+	updatePlaylist "effective-mobile/internal/http-server/handlers/update-playlist"
 	updateSongData "effective-mobile/internal/http-server/handlers/update-song-data"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/services/details"
+	"effective-mobile/internal/services/health"
+	"effective-mobile/internal/services/importer"
+	"effective-mobile/internal/services/middleware/access"
+	"effective-mobile/internal/services/middleware/deprecation"
+	"effective-mobile/internal/services/middleware/idempotency"
 	"effective-mobile/internal/services/middleware/logger"
+	"effective-mobile/internal/services/middleware/metrics"
+	"effective-mobile/internal/services/middleware/ready"
+	"effective-mobile/internal/services/purge"
+	"effective-mobile/internal/services/tracing"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/memory"
 	"effective-mobile/internal/storage/postgres"
+	"effective-mobile/migrations"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
@@ -19,32 +71,158 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 func Run() {
 	cfg := config.MustLoad()
 	log := setupLogger()
-	db, err := postgres.New(cfg.StoragePath)
+	stopTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Error("failed to init storage")
+		log.Error("failed to init tracing", slog.Any("error", err))
 		os.Exit(1)
 	}
+	db, err := setupStorage(context.Background(), cfg)
+	if err != nil {
+		log.Error("failed to init storage", slog.Any("error", err))
+		os.Exit(1)
+	}
+	provider, err := setupProvider(log, cfg)
+	if err != nil {
+		log.Error("failed to init details provider", slog.Any("error", err))
+		os.Exit(1)
+	}
+	authenticator, err := auth.New(auth.Config{
+		APIKeys:          apiKeys(cfg.Auth.APIKeys),
+		JWTSecret:        cfg.Auth.JWTSecret,
+		JWTPublicKeyFile: cfg.Auth.JWTPublicKeyFile,
+		JWTIssuer:        cfg.Auth.JWTIssuer,
+		JWTAudience:      cfg.Auth.JWTAudience,
+		Dev:              cfg.Env == config.EnvDev,
+	})
+	if err != nil {
+		log.Error("failed to init authentication", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	log.Info("starting app", slog.String("version", "1"))
 
+	// Mutating routes need a role; playlists only need a known user since
+	// their handlers check ownership.
+	member := access.Require(log, auth.RoleReader)
+	editor := access.Require(log, auth.RoleEditor)
+	admin := access.Require(log, auth.RoleAdmin)
+	read := member
+	if cfg.Auth.PublicReads {
+		read = func(next http.Handler) http.Handler { return next }
+	}
+	// Both add routes share the cache, but keys are scoped to the path.
+	idempotent := idempotency.New(log, idempotency.NewCache(cfg.IdempotencyTTL))
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /song/library", receiveLibrary.New(log, db))
-	mux.HandleFunc("GET /song/lyrics", receiveLyrics.New(log, db))
-	mux.HandleFunc("POST /song/add", addSong.New(log, db))
-	mux.HandleFunc("PATCH /song/update", updateSongData.New(log, db))
-	mux.HandleFunc("DELETE /song/remove", removeSong.New(log, db))
-	mux.HandleFunc("/swagger/", httpSwagger.WrapHandler)
+	mux.Handle("GET /songs", read(receiveLibrary.New(log, db)))
+	mux.Handle("POST /songs", editor(idempotent(addSong.New(log, db, provider))))
+	mux.Handle("GET /songs/search", read(searchSongs.New(log, db)))
+	mux.Handle("GET /songs/export", read(exportSongs.New(log, db)))
+	mux.Handle("POST /songs/import", editor(importSongs.New(log, setupImporter(log, cfg, db, provider), cfg.Import.MaxSize)))
+	mux.Handle("GET /songs/trash", editor(receiveTrash.New(log, db)))
+	mux.Handle("GET /songs/{id}", read(getSong.New(log, db)))
+	mux.Handle("PUT /songs/{id}", editor(replaceSong.New(log, db)))
+	mux.Handle("PATCH /songs/{id}", editor(patchSong.New(log, db)))
+	mux.Handle("DELETE /songs/{id}", editor(deleteSong.New(log, db)))
+	mux.Handle("POST /songs/{id}/restore", editor(restoreSong.New(log, db)))
+	mux.Handle("GET /songs/{id}/history", editor(songHistory.New(log, db)))
+	mux.Handle("POST /songs/{id}/revert", editor(revertSong.New(log, db)))
+	mux.Handle("GET /song/lyrics", read(receiveLyrics.New(log, db)))
 
-	loggedMux := logger.New(log)(mux)
+	mux.Handle("GET /groups", read(receiveGroups.New(log, db)))
+	mux.Handle("POST /groups", editor(addGroup.New(log, db)))
+	mux.Handle("GET /groups/{id}", read(getGroup.New(log, db)))
+	mux.Handle("PATCH /groups/{id}", editor(updateGroup.New(log, db)))
+	mux.Handle("DELETE /groups/{id}", admin(deleteGroup.New(log, db)))
+	mux.Handle("GET /groups/{id}/songs", read(receiveGroupSongs.New(log, db, db)))
+
+	mux.Handle("GET /albums", read(receiveAlbums.New(log, db)))
+	mux.Handle("POST /albums", editor(addAlbum.New(log, db)))
+	mux.Handle("GET /albums/{id}", read(getAlbum.New(log, db)))
+	mux.Handle("DELETE /albums/{id}", admin(deleteAlbum.New(log, db)))
+	mux.Handle("GET /albums/{id}/tracks", read(receiveAlbumTracks.New(log, db)))
+	mux.Handle("POST /albums/{id}/tracks", editor(attachAlbumTrack.New(log, db)))
+	mux.Handle("PUT /albums/{id}/tracks", editor(reorderAlbumTracks.New(log, db)))
+	mux.Handle("DELETE /albums/{id}/tracks/{songId}", editor(detachAlbumTrack.New(log, db)))
+
+	mux.Handle("GET /playlists", read(receivePlaylists.New(log, db)))
+	mux.Handle("POST /playlists", member(addPlaylist.New(log, db)))
+	mux.Handle("GET /playlists/{id}", read(getPlaylist.New(log, db)))
+	mux.Handle("PATCH /playlists/{id}", member(updatePlaylist.New(log, db)))
+	mux.Handle("DELETE /playlists/{id}", member(deletePlaylist.New(log, db)))
+	mux.Handle("GET /playlists/{id}/songs", read(receivePlaylistSongs.New(log, db)))
+	mux.Handle("POST /playlists/{id}/songs", member(addPlaylistSong.New(log, db)))
+	mux.Handle("PATCH /playlists/{id}/songs/{songId}", member(movePlaylistSong.New(log, db)))
+	mux.Handle("DELETE /playlists/{id}/songs/{songId}", member(removePlaylistSong.New(log, db)))
+
+	// Deprecated aliases kept for old clients.
+	mux.Handle("GET /song/library", deprecation.New(log, "/songs")(read(receiveLibrary.New(log, db))))
+	mux.Handle("POST /song/add", deprecation.New(log, "/songs")(editor(idempotent(addSong.New(log, db, provider)))))
+	mux.Handle("PATCH /song/update", deprecation.New(log, "/songs/{id}")(editor(updateSongData.New(log, db))))
+	mux.Handle("DELETE /song/remove", deprecation.New(log, "/songs/{id}")(editor(removeSong.New(log, db))))
+
+	// The API waits for the storage; probes and docs answer right away.
+	gate := &ready.Gate{}
+	checker, err := setupHealth(cfg, db, provider, gate)
+	if err != nil {
+		log.Error("failed to init health checks", slog.Any("error", err))
+		os.Exit(1)
+	}
+	// Only the API authenticates, so a bad Authorization header cannot fail
+	// the probes, the scrapes or the docs.
+	root := http.NewServeMux()
+	root.Handle("/", access.New(log, authenticator)(ready.New(log, gate)(mux)))
+	root.Handle("GET /healthz", liveness.New(log))
+	root.Handle("GET /readyz", readiness.New(log, checker))
+	root.HandleFunc("/swagger/", httpSwagger.WrapHandler)
+
+	reg := setupMetrics(db, provider)
+	root.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	// route resolves the API routes in mux, which root serves under "/".
+	route := func(r *http.Request) string {
+		if _, pattern := root.Handler(r); pattern != "/" {
+			return pattern
+		}
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+
+	loggedMux := metrics.New(log, reg, route)(logger.New(log, logger.Config{SuccessSampleRate: cfg.Logging.SuccessSampleRate}, route)(root))
+	// Every request but the probes and scrapes gets a server span named by
+	// its route, continuing the trace of an incoming traceparent.
+	traced := otelhttp.NewHandler(loggedMux, "http",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if pattern := route(r); pattern != "" {
+				return pattern
+			}
+			return r.Method
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			switch r.URL.Path {
+			case "/healthz", "/readyz", "/metrics":
+				return false
+			}
+			return true
+		}),
+	)
 
 	srv := &http.Server{
 		Addr:    net.JoinHostPort(cfg.Address, cfg.Port),
-		Handler: loggedMux,
+		Handler: traced,
 	}
 
 	done := make(chan os.Signal, 1)
@@ -60,23 +238,53 @@ func Run() {
 
 	log.Info("server started")
 
+	// Background jobs stop before the storage is closed. They start once
+	// the storage is reachable, which also opens the gate for the API.
+	jobs, stopJobs := context.WithCancel(context.Background())
+	purged := make(chan struct{})
+	go func() {
+		defer close(purged)
+		if err := db.Connect(jobs); err != nil {
+			log.Error("storage unreachable", slog.Any("error", err))
+			return
+		}
+		gate.Open()
+		log.Info("storage ready")
+		if cfg.Trash.Retention > 0 {
+			purge.New(log, db, purge.Config{
+				Retention: cfg.Trash.Retention,
+				Interval:  cfg.Trash.PurgeInterval,
+			}).Run(jobs)
+		}
+	}()
+
 	<-done
+	// Fail readiness first and give load balancers time to notice before
+	// the server stops accepting connections.
+	checker.Drain()
+	log.Info("draining", slog.String("delay", cfg.Health.DrainDelay.String()))
+	time.Sleep(cfg.Health.DrainDelay)
 	log.Info("stopping server")
+	stopJobs()
+	<-purged
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Error("failed to stop server", err)
+		log.Error("failed to stop server", slog.Any("error", err))
 
 		return
 	}
 	err = db.Stop()
 	if err != nil {
-		log.Error("error after db.stop", err)
+		log.Error("error after db.stop", slog.Any("error", err))
 	} else {
 		log.Info("storage closed")
 	}
+	if err := stopTracing(ctx); err != nil {
+		log.Error("failed to flush traces", slog.Any("error", err))
+	}
 	log.Info("server stopped")
 }
 
@@ -85,6 +293,108 @@ func setupLogger() *slog.Logger {
 	log = slog.New(
 		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
 	)
+	// Code that logs without a request logger, such as the storage outside
+	// of requests, writes to the same output.
+	slog.SetDefault(log)
 
 	return log
 }
+
+func setupStorage(ctx context.Context, cfg *config.Config) (storage.Storage, error) {
+	switch cfg.StorageDriver {
+	case "postgres":
+		return postgres.New(ctx, cfg.StoragePath, postgres.Config{
+			QueryTimeout:       cfg.Database.QueryTimeout,
+			MaxOpenConns:       cfg.Database.MaxOpenConns,
+			MaxIdleConns:       cfg.Database.MaxIdleConns,
+			ConnMaxLifetime:    cfg.Database.ConnMaxLifetime,
+			ConnMaxIdleTime:    cfg.Database.ConnMaxIdleTime,
+			ConnectBackoffBase: cfg.Database.ConnectBackoffBase,
+			ConnectBackoffMax:  cfg.Database.ConnectBackoffMax,
+		})
+	case "memory":
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// setupHealth checks the storage, which counts as down until Connect has
+// reached it, the schema of a PostgreSQL database and the details provider,
+// which only degrades the service.
+func setupHealth(cfg *config.Config, db storage.Storage, provider *details.Client, gate *ready.Gate) (*health.Checker, error) {
+	checks := []health.Check{{
+		Name:     cfg.StorageDriver,
+		Critical: true,
+		Probe: func(ctx context.Context) error {
+			if !gate.Ready() {
+				return errors.New("connecting")
+			}
+			return db.Ping(ctx)
+		},
+	}}
+	if pg, ok := db.(*postgres.Storage); ok {
+		latest, err := migrations.Latest()
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, health.Check{
+			Name:     "migrations",
+			Critical: true,
+			Probe: func(ctx context.Context) error {
+				version, err := pg.SchemaVersion(ctx)
+				if err != nil {
+					return err
+				}
+				if version < latest {
+					return fmt.Errorf("schema at version %d, expected %d", version, latest)
+				}
+				return nil
+			},
+		})
+	}
+	checks = append(checks, health.Check{Name: "details", Probe: provider.Ping})
+	return health.New(cfg.Health.CheckTimeout, checks...), nil
+}
+
+// setupMetrics registers the runtime metrics and those of the storage and
+// the details provider; the HTTP metrics are added by their middleware.
+func setupMetrics(db storage.Storage, provider *details.Client) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if pg, ok := db.(*postgres.Storage); ok {
+		reg.MustRegister(pg.Collectors()...)
+	}
+	reg.MustRegister(provider.Collectors()...)
+	return reg
+}
+
+func setupProvider(log *slog.Logger, cfg *config.Config) (*details.Client, error) {
+	return details.New(log, details.Config{
+		BaseURL:          cfg.DetailsAPI.URL,
+		Timeout:          cfg.DetailsAPI.Timeout,
+		MaxRetries:       cfg.DetailsAPI.MaxRetries,
+		BackoffBase:      cfg.DetailsAPI.BackoffBase,
+		BackoffMax:       cfg.DetailsAPI.BackoffMax,
+		BreakerThreshold: cfg.DetailsAPI.BreakerThreshold,
+		BreakerCooldown:  cfg.DetailsAPI.BreakerCooldown,
+	})
+}
+
+func setupImporter(log *slog.Logger, cfg *config.Config, db storage.SongStore, provider details.Provider) *importer.Importer {
+	return importer.New(log, db, provider, importer.Config{
+		BatchSize:   cfg.Import.BatchSize,
+		Concurrency: cfg.Import.Concurrency,
+	})
+}
+
+func apiKeys(keys []config.APIKey) []auth.APIKey {
+	out := make([]auth.APIKey, len(keys))
+	for i, k := range keys {
+		out[i] = auth.APIKey{Key: k.Key, Subject: k.Subject, Role: k.Role}
+	}
+	return out
+}
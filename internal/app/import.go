@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"effective-mobile/internal/config"
+	"effective-mobile/internal/services/importer"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Import runs the import subcommand: it imports a CSV or NDJSON file straight
+// into the configured storage and prints the report as JSON. It exits with 1
+// when the import fails and with 2 when some rows failed.
+func Import(args []string) {
+	os.Exit(runImport(args))
+}
+
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "", "CSV or NDJSON file to import, - for stdin")
+	format := fs.String("format", "", "csv or ndjson, guessed from the file extension by default")
+	upsert := fs.Bool("upsert", false, "overwrite songs that already exist")
+	enrich := fs.Bool("enrich", true, "fetch missing details from the music info API")
+	actor := fs.String("actor", "cli", "name recorded as the author of the songs in their history")
+	fs.Parse(args)
+
+	cfg := config.MustLoad()
+	// stdout carries the report
+	log := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	if *file == "" {
+		log.Error("no file: pass -file")
+		return 1
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+	f, err := importer.ParseFormat(*format)
+	if err != nil {
+		log.Error("unknown format, pass -format", slog.Any("error", err))
+		return 1
+	}
+
+	in := os.Stdin
+	if *file != "-" {
+		if in, err = os.Open(*file); err != nil {
+			log.Error("failed to open file", slog.Any("error", err))
+			return 1
+		}
+		defer in.Close()
+	}
+
+	db, err := setupStorage(context.Background(), cfg)
+	if err != nil {
+		log.Error("failed to init storage", slog.Any("error", err))
+		return 1
+	}
+	defer db.Stop()
+	provider, err := setupProvider(log, cfg)
+	if err != nil {
+		log.Error("failed to init details provider", slog.Any("error", err))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := db.Connect(ctx); err != nil {
+		log.Error("storage unreachable", slog.Any("error", err))
+		return 1
+	}
+	report, err := setupImporter(log, cfg, db, provider).Import(ctx, in, f, importer.Options{Upsert: *upsert, Enrich: *enrich, Actor: storage.Actor{Subject: *actor}})
+	if err != nil {
+		log.Error("import failed", slog.Any("error", err))
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if report.Failed > 0 {
+		return 2
+	}
+	return 0
+}
@@ -1,26 +1,265 @@
 package config
 
 import (
+	"errors"
+	"io/fs"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// EnvDev is the APP_ENV of local runs, which may use the public HS256 secret
+// of .env.example.
+const EnvDev = "dev"
+
 type Config struct {
+	// Env names the environment the service runs in; EnvDev relaxes the
+	// checks of secrets meant for local runs.
+	Env         string
 	Address     string
 	Port        string
 	StoragePath string
+	// StorageDriver selects the song storage backend: "postgres" (default) or "memory".
+	StorageDriver string
+	Database      Database
+	DetailsAPI    DetailsAPI
+	Auth          Auth
+	// IdempotencyTTL is how long responses to requests with an
+	// Idempotency-Key are kept for replay.
+	IdempotencyTTL time.Duration
+	Import         Import
+	Trash          Trash
+	Health         Health
+	Tracing        Tracing
+	Logging        Logging
+}
+
+// Logging configures the access log.
+type Logging struct {
+	// SuccessSampleRate is the share of successful requests that are
+	// logged; failed requests are always logged.
+	SuccessSampleRate float64
+}
+
+// Tracing configures OpenTelemetry tracing.
+type Tracing struct {
+	// Exporter is "otlp", "stdout" or "none" (default). The OTLP endpoint
+	// comes from the standard OTEL_EXPORTER_OTLP_ENDPOINT variable.
+	Exporter    string
+	ServiceName string
+	SampleRatio float64
+}
+
+// Health configures the readiness checks and the graceful shutdown.
+type Health struct {
+	// CheckTimeout bounds every dependency check of GET /readyz.
+	CheckTimeout time.Duration
+	// DrainDelay is how long readiness fails before the server stops, so
+	// load balancers take the instance out of rotation first.
+	DrainDelay time.Duration
+}
+
+// Database configures the calls to the PostgreSQL storage.
+type Database struct {
+	// QueryTimeout bounds a storage call that runs a single query, every
+	// attempt of a call that runs a transaction, and every statement of an
+	// export, whose transaction stays open while the client reads; zero
+	// leaves calls bounded only by their request.
+	QueryTimeout    time.Duration
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ConnectBackoffBase is the delay before the second attempt to reach
+	// the database at startup; it doubles up to ConnectBackoffMax and the
+	// attempts go on until the database answers.
+	ConnectBackoffBase time.Duration
+	ConnectBackoffMax  time.Duration
+}
+
+// Trash configures how long deleted songs can be restored.
+type Trash struct {
+	// Retention is how long a deleted song stays in the trash before it is
+	// purged; zero keeps deleted songs forever.
+	Retention time.Duration
+	// PurgeInterval is how often the trash is purged.
+	PurgeInterval time.Duration
+}
+
+// Import configures bulk imports of songs.
+type Import struct {
+	// BatchSize is the number of songs stored per transaction.
+	BatchSize int
+	// Concurrency bounds the parallel calls to the details API.
+	Concurrency int
+	// MaxSize is the largest file POST /songs/import accepts, in bytes.
+	MaxSize int64
+}
+
+// DetailsAPI configures the client of the external music info API.
+type DetailsAPI struct {
+	URL              string
+	Timeout          time.Duration
+	MaxRetries       int
+	BackoffBase      time.Duration
+	BackoffMax       time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Auth configures API keys and bearer tokens.
+type Auth struct {
+	// APIKeys come from AUTH_API_KEYS as comma-separated subject:role:key entries.
+	APIKeys []APIKey
+	// JWTSecret enables HS256 tokens.
+	JWTSecret string
+	// JWTPublicKeyFile is a PEM file with the RSA key that enables RS256 tokens.
+	JWTPublicKeyFile string
+	JWTIssuer        string
+	JWTAudience      string
+	// PublicReads lets anonymous callers use the read-only routes.
+	PublicReads bool
+}
+
+type APIKey struct {
+	Subject string
+	Role    string
+	Key     string
 }
 
 func MustLoad() *Config {
 	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("Error loading .env file: %v", err)
 	}
 	var cfg Config
+	cfg.Env = getEnv("APP_ENV", "production")
 	cfg.Address = os.Getenv("ADDRESS")
 	cfg.Port = os.Getenv("PORT")
 	cfg.StoragePath = os.Getenv("STORAGE_PATH")
+	cfg.StorageDriver = getEnv("STORAGE_DRIVER", "postgres")
+	cfg.Database = Database{
+		QueryTimeout:       mustDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+		MaxOpenConns:       mustInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:       mustInt("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime:    mustDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		ConnMaxIdleTime:    mustDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		ConnectBackoffBase: mustDuration("DB_CONNECT_BACKOFF_BASE", 500*time.Millisecond),
+		ConnectBackoffMax:  mustDuration("DB_CONNECT_BACKOFF_MAX", 30*time.Second),
+	}
+	cfg.DetailsAPI = DetailsAPI{
+		URL:              os.Getenv("DETAILS_API_URL"),
+		Timeout:          mustDuration("DETAILS_API_TIMEOUT", 5*time.Second),
+		MaxRetries:       mustInt("DETAILS_API_MAX_RETRIES", 3),
+		BackoffBase:      mustDuration("DETAILS_API_BACKOFF_BASE", 200*time.Millisecond),
+		BackoffMax:       mustDuration("DETAILS_API_BACKOFF_MAX", 2*time.Second),
+		BreakerThreshold: mustInt("DETAILS_API_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  mustDuration("DETAILS_API_BREAKER_COOLDOWN", 30*time.Second),
+	}
+	cfg.Auth = Auth{
+		APIKeys:          mustAPIKeys("AUTH_API_KEYS"),
+		JWTSecret:        os.Getenv("AUTH_JWT_SECRET"),
+		JWTPublicKeyFile: os.Getenv("AUTH_JWT_PUBLIC_KEY_FILE"),
+		JWTIssuer:        os.Getenv("AUTH_JWT_ISSUER"),
+		JWTAudience:      os.Getenv("AUTH_JWT_AUDIENCE"),
+		PublicReads:      mustBool("AUTH_PUBLIC_READS", true),
+	}
+	cfg.IdempotencyTTL = mustDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	cfg.Import = Import{
+		BatchSize:   mustInt("IMPORT_BATCH_SIZE", 500),
+		Concurrency: mustInt("IMPORT_CONCURRENCY", 4),
+		MaxSize:     int64(mustInt("IMPORT_MAX_SIZE", 256<<20)),
+	}
+	cfg.Trash = Trash{
+		Retention:     mustDuration("TRASH_RETENTION", 30*24*time.Hour),
+		PurgeInterval: mustDuration("TRASH_PURGE_INTERVAL", time.Hour),
+	}
+	cfg.Health = Health{
+		CheckTimeout: mustDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+		DrainDelay:   mustDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+	}
+	cfg.Tracing = Tracing{
+		Exporter:    getEnv("TRACING_EXPORTER", "none"),
+		ServiceName: getEnv("TRACING_SERVICE_NAME", "effective-mobile"),
+		SampleRatio: mustFloat("TRACING_SAMPLE_RATIO", 1),
+	}
+	cfg.Logging = Logging{
+		SuccessSampleRate: mustFloat("LOG_SUCCESS_SAMPLE_RATE", 1),
+	}
 	return &cfg
 }
+
+func getEnv(key string, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func mustDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("Invalid duration in %s: %v", key, err)
+	}
+	return d
+}
+
+func mustInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("Invalid integer in %s: %v", key, err)
+	}
+	return n
+}
+
+func mustFloat(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Fatalf("Invalid number in %s: %v", key, err)
+	}
+	return f
+}
+
+func mustBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("Invalid boolean in %s: %v", key, err)
+	}
+	return b
+}
+
+func mustAPIKeys(key string) []APIKey {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	var keys []APIKey
+	for _, entry := range strings.Split(v, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			log.Fatalf("Invalid entry in %s, expected subject:role:key", key)
+		}
+		keys = append(keys, APIKey{Subject: parts[0], Role: parts[1], Key: parts[2]})
+	}
+	return keys
+}
@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"net/http"
+)
+
+// RequestIDHeader carries the ID of a request, recorded with its changes.
+const RequestIDHeader = "X-Request-ID"
+
+// Actor returns the caller of the request for the song history.
+func Actor(r *http.Request) storage.Actor {
+	return storage.Actor{
+		Subject:   auth.Subject(r.Context()),
+		RequestID: r.Header.Get(RequestIDHeader),
+	}
+}
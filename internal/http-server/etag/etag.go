@@ -0,0 +1,123 @@
+// Package etag implements entity tags and the conditional requests built on
+// them. A song is tagged with its version, so If-Match guards writes against
+// lost updates; other responses are tagged with a hash of their body.
+package etag
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"effective-mobile/internal/storage"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Song returns the strong entity tag of a song version.
+func Song(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Body returns a weak entity tag for a response body.
+func Body(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Versions lists the song versions an If-Match header accepts. A nil list
+// accepts any version.
+type Versions []int
+
+// IfMatch returns the song versions the If-Match header of r lists, or nil
+// when there is no header or it contains "*". If-Match compares tags
+// strongly, so weak and foreign tags are skipped; a header left with no song
+// version can never match and fails with storage.ErrVersionMismatch.
+func IfMatch(r *http.Request) (Versions, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return nil, nil
+	}
+	var versions Versions
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return nil, nil
+		}
+		tag, ok := strings.CutPrefix(candidate, `"`)
+		if !ok {
+			continue
+		}
+		tag, ok = strings.CutSuffix(tag, `"`)
+		if !ok {
+			continue
+		}
+		version, err := strconv.Atoi(tag)
+		if err != nil || version < 1 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return nil, storage.ErrVersionMismatch
+	}
+	return versions, nil
+}
+
+// Try runs the conditional write with each listed version until one is the
+// current version, or once with zero when any version is accepted. Each
+// write checks its version atomically, so at most one of them succeeds.
+func (v Versions) Try(write func(version int) error) error {
+	if v == nil {
+		return write(0)
+	}
+	var err error
+	for _, version := range v {
+		err = write(version)
+		if !errors.Is(err, storage.ErrVersionMismatch) {
+			return err
+		}
+	}
+	return err
+}
+
+// NoneMatch reports whether the If-None-Match header of r lists tag, in
+// which case the client already has the current response. Tags are
+// compared weakly.
+func NoneMatch(r *http.Request, tag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON sends v as a 200 response tagged with tag, or with the hash of
+// the encoded body when tag is empty. It sends 304 Not Modified instead when
+// the client's copy is current.
+func WriteJSON(w http.ResponseWriter, r *http.Request, tag string, v any) error {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		return err
+	}
+	if tag == "" {
+		tag = Body(body.Bytes())
+	}
+	w.Header().Set("ETag", tag)
+	if NoneMatch(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(body.Bytes())
+	return err
+}
@@ -0,0 +1,231 @@
+package etag_test
+
+import (
+	"context"
+	"effective-mobile/internal/http-server/etag"
+	deleteSong "effective-mobile/internal/http-server/handlers/delete-song"
+	getSong "effective-mobile/internal/http-server/handlers/get-song"
+	patchSong "effective-mobile/internal/http-server/handlers/patch-song"
+	replaceSong "effective-mobile/internal/http-server/handlers/replace-song"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/memory"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   etag.Versions
+		err    error
+	}{
+		{name: "no header", header: "", want: nil},
+		{name: "any", header: "*", want: nil},
+		{name: "any in a list", header: `"3", *`, want: nil},
+		{name: "one version", header: `"3"`, want: etag.Versions{3}},
+		{name: "several versions", header: `"3", "5","7"`, want: etag.Versions{3, 5, 7}},
+		{name: "weak tag never matches", header: `W/"3"`, err: storage.ErrVersionMismatch},
+		{name: "weak tags are skipped", header: `W/"3", "4"`, want: etag.Versions{4}},
+		{name: "body hash", header: `W/"0123456789abcdef"`, err: storage.ErrVersionMismatch},
+		{name: "foreign tag", header: `"abc"`, err: storage.ErrVersionMismatch},
+		{name: "zero version", header: `"0"`, err: storage.ErrVersionMismatch},
+		{name: "unquoted", header: `3`, err: storage.ErrVersionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/songs/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			got, err := etag.IfMatch(r)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(got, tt.want) || (got == nil) != (tt.want == nil) {
+				t.Errorf("versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTry(t *testing.T) {
+	const current = 5
+	write := func(tried *[]int) func(int) error {
+		return func(version int) error {
+			*tried = append(*tried, version)
+			if version != 0 && version != current {
+				return storage.ErrVersionMismatch
+			}
+			return nil
+		}
+	}
+	tests := []struct {
+		name     string
+		versions etag.Versions
+		tried    []int
+		err      error
+	}{
+		{name: "any", versions: nil, tried: []int{0}},
+		{name: "current", versions: etag.Versions{5}, tried: []int{5}},
+		{name: "current in a list", versions: etag.Versions{3, 5, 7}, tried: []int{3, 5}},
+		{name: "stale", versions: etag.Versions{3, 4}, tried: []int{3, 4}, err: storage.ErrVersionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tried []int
+			err := tt.versions.Try(write(&tried))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(tried, tt.tried) {
+				t.Errorf("tried %v, want %v", tried, tt.tried)
+			}
+		})
+	}
+
+	t.Run("other errors stop", func(t *testing.T) {
+		calls := 0
+		err := etag.Versions{3, 5}.Try(func(int) error {
+			calls++
+			return storage.ErrSongNotFound
+		})
+		if !errors.Is(err, storage.ErrSongNotFound) || calls != 1 {
+			t.Errorf("error = %v after %d calls, want %v after 1", err, calls, storage.ErrSongNotFound)
+		}
+	})
+}
+
+func TestNoneMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		tag    string
+		want   bool
+	}{
+		{name: "no header", header: "", tag: `"3"`, want: false},
+		{name: "any", header: "*", tag: `"3"`, want: true},
+		{name: "same strong tag", header: `"3"`, tag: `"3"`, want: true},
+		{name: "other tag", header: `"2"`, tag: `"3"`, want: false},
+		{name: "in a list", header: `"1", "2" ,"3"`, tag: `"3"`, want: true},
+		{name: "not in a list", header: `"1", "2"`, tag: `"3"`, want: false},
+		{name: "weak header, strong tag", header: `W/"3"`, tag: `"3"`, want: true},
+		{name: "strong header, weak tag", header: `"abc"`, tag: `W/"abc"`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/songs/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-None-Match", tt.header)
+			}
+			if got := etag.NoneMatch(r, tt.tag); got != tt.want {
+				t.Errorf("NoneMatch(%q, %q) = %v, want %v", tt.header, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	value := map[string]string{"song": "Supermassive Black Hole"}
+	first := httptest.NewRecorder()
+	if err := etag.WriteJSON(first, httptest.NewRequest(http.MethodGet, "/groups", nil), "", value); err != nil {
+		t.Fatal(err)
+	}
+	tag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || !strings.HasPrefix(tag, `W/"`) || first.Body.Len() == 0 {
+		t.Fatalf("first response: status %d, ETag %q, %d bytes", first.Code, tag, first.Body.Len())
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "current copy", header: tag, want: http.StatusNotModified},
+		{name: "current copy in a list", header: `W/"stale", ` + tag, want: http.StatusNotModified},
+		{name: "stale copy", header: `W/"stale"`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/groups", nil)
+			r.Header.Set("If-None-Match", tt.header)
+			w := httptest.NewRecorder()
+			if err := etag.WriteJSON(w, r, "", value); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("ETag"); got != tag {
+				t.Errorf("ETag = %q, want %q", got, tag)
+			}
+			if w.Code == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("304 carries a body of %d bytes", w.Body.Len())
+			}
+		})
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := memory.New()
+	mux := http.NewServeMux()
+	mux.Handle("GET /songs/{id}", getSong.New(log, store))
+	mux.Handle("PUT /songs/{id}", replaceSong.New(log, store))
+	mux.Handle("PATCH /songs/{id}", patchSong.New(log, store))
+	mux.Handle("DELETE /songs/{id}", deleteSong.New(log, store))
+
+	send := func(method, header, value, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/songs/1", strings.NewReader(body))
+		if body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		if header != "" {
+			r.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	_, err := store.InsertSong(context.Background(), storage.Song{GroupName: "Muse", SongName: "Uprising"}, storage.Actor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := send(http.MethodGet, "", "", "")
+	if first.Code != http.StatusOK || first.Header().Get("ETag") != etag.Song(1) {
+		t.Fatalf("GET: status %d, ETag %q", first.Code, first.Header().Get("ETag"))
+	}
+	if w := send(http.MethodGet, "If-None-Match", etag.Song(1), ""); w.Code != http.StatusNotModified {
+		t.Errorf("GET with a current If-None-Match: status %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	// Each step runs against the song as the previous steps left it.
+	steps := []struct {
+		name   string
+		method string
+		match  string
+		body   string
+		want   int
+	}{
+		{name: "patch current", method: http.MethodPatch, match: `"1"`, body: `{"song":"Resistance"}`, want: http.StatusNoContent},
+		{name: "patch stale", method: http.MethodPatch, match: `"1"`, body: `{"song":"Uprising"}`, want: http.StatusPreconditionFailed},
+		{name: "patch weak", method: http.MethodPatch, match: `W/"2"`, body: `{"song":"Uprising"}`, want: http.StatusPreconditionFailed},
+		{name: "put stale", method: http.MethodPut, match: `"1"`, body: `{"group":"Muse","song":"Uprising"}`, want: http.StatusPreconditionFailed},
+		{name: "put current in a list", method: http.MethodPut, match: `"1", "2"`, body: `{"group":"Muse","song":"Uprising"}`, want: http.StatusNoContent},
+		{name: "delete stale", method: http.MethodDelete, match: `"2"`, want: http.StatusPreconditionFailed},
+		{name: "delete any", method: http.MethodDelete, match: `*`, want: http.StatusNoContent},
+	}
+	for _, step := range steps {
+		w := send(step.method, "If-Match", step.match, step.body)
+		if w.Code != step.want {
+			t.Fatalf("%s: status %d, want %d: %s", step.name, w.Code, step.want, w.Body)
+		}
+	}
+}
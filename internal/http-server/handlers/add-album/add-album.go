@@ -0,0 +1,87 @@
+package add_album
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Album struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	GroupID     uint   `json:"groupId" validate:"required"`
+	ReleaseDate string `json:"releaseDate,omitempty" validate:"date=2006-01-02"`
+	CoverURL    string `json:"coverUrl,omitempty" validate:"url"`
+}
+
+// New creates a handler for adding a new album
+// @Summary Add an album
+// @Description Creates an empty album of a group. Songs are added with POST /albums/{id}/tracks.
+// @Tags albums
+// @Accept json
+// @Produce json
+// @Param album body Album true "Information about the album, releaseDate in YYYY-MM-DD"
+// @Success 201 {object} storage.Album "The created album"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The group was not found"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums [post]
+func New(log *slog.Logger, store storage.AlbumStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.add-album.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		var request Album
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		id, err := store.InsertAlbum(r.Context(), storage.Album{
+			Title:       request.Title,
+			GroupID:     request.GroupID,
+			ReleaseDate: request.ReleaseDate,
+			CoverURL:    request.CoverURL,
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrGroupNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeGroupNotFound, "Group not found")
+				log.Warn("Group not found", slog.Uint64("groupId", uint64(request.GroupID)))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to insert album", slog.Any("error", err))
+			return
+		}
+
+		album, err := store.GetAlbum(r.Context(), id)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get created album", slog.Any("error", err))
+			return
+		}
+
+		log.Info("Album successfully added", slog.Uint64("id", uint64(id)), slog.String("title", album.Title))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/albums/"+strconv.FormatUint(uint64(id), 10))
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(album); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package add_group
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Group struct {
+	Name        string   `json:"name" validate:"required,max=255"`
+	Country     string   `json:"country,omitempty" validate:"max=255"`
+	FormedYear  int      `json:"formedYear,omitempty" validate:"min=0,max=9999"`
+	Genres      []string `json:"genres,omitempty" validate:"max=50,dive,notblank,max=255"`
+	Description string   `json:"description,omitempty"`
+}
+
+// New creates a handler for adding a new group
+// @Summary Add a group
+// @Description Creates a group (artist) that songs can refer to.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group body Group true "Information about the group"
+// @Success 201 {object} storage.Group "The created group"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 409 {object} problem.Problem "A group with this name already exists"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /groups [post]
+func New(log *slog.Logger, store storage.GroupStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.add-group.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		var request Group
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		group := storage.Group{
+			Name:        request.Name,
+			Country:     request.Country,
+			FormedYear:  request.FormedYear,
+			Genres:      request.Genres,
+			Description: request.Description,
+		}
+		id, err := store.InsertGroup(r.Context(), group)
+		if err != nil {
+			if errors.Is(err, storage.ErrGroupExists) {
+				problem.Error(w, r, http.StatusConflict, problem.CodeGroupExists, "Group already exists")
+				log.Warn("Group already exists", slog.String("name", request.Name))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to insert group", slog.Any("error", err))
+			return
+		}
+		group.ID = id
+		if group.Genres == nil {
+			group.Genres = []string{}
+		}
+
+		log.Info("Group successfully added", slog.Uint64("id", uint64(group.ID)), slog.String("name", group.Name))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/groups/"+strconv.FormatUint(uint64(group.ID), 10))
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(group); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+		}
+	}
+}
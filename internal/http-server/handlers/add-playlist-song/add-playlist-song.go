@@ -0,0 +1,105 @@
+package add_playlist_song
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type AddSongRequest struct {
+	SongID uint `json:"songId" validate:"required"`
+	// Position is where to insert the song; omitted appends it.
+	Position int `json:"position,omitempty" validate:"min=0"`
+}
+
+// New creates a handler that adds a song to a playlist
+// @Summary Add a song to a playlist
+// @Description Inserts the song at the given position and moves the following songs down, or appends it when no position is given. A song can be in a playlist only once.
+// @Tags playlists
+// @Accept json
+// @Param id path int true "Playlist ID"
+// @Param song body AddSongRequest true "Song and position"
+// @Success 204 "The song was added to the playlist"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The playlist belongs to another user"
+// @Failure 404 {object} problem.Problem "The playlist or song was not found"
+// @Failure 409 {object} problem.Problem "The song is already in the playlist"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id}/songs [post]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.add-playlist-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid playlist id"))
+			log.Error("Invalid playlist id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		var request AddSongRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		caller := auth.Subject(r.Context())
+		playlist, err := store.GetPlaylist(r.Context(), uint(id))
+		if err == nil && !playlist.Visible(caller) {
+			err = storage.ErrPlaylistNotFound
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get playlist", slog.Any("error", err))
+			return
+		}
+		if playlist.Owner != caller {
+			problem.Error(w, r, http.StatusForbidden, problem.CodeForbidden, "Only the owner can change the playlist")
+			log.Warn("Playlist change by another user", slog.Uint64("id", id), slog.String("user", caller))
+			return
+		}
+
+		err = store.AddEntry(r.Context(), uint(id), request.SongID, request.Position)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrPlaylistNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+			case errors.Is(err, storage.ErrSongNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("songId", uint64(request.SongID)))
+			case errors.Is(err, storage.ErrEntryExists):
+				problem.Error(w, r, http.StatusConflict, problem.CodeEntryExists, "Song is already in the playlist")
+				log.Warn("Duplicate playlist entry", slog.Any("request", request))
+			default:
+				problem.WriteError(w, r, err)
+				log.Error("Failed to add entry", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Song added to playlist", slog.Uint64("id", id), slog.Any("request", request))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
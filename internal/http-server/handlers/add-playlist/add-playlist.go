@@ -0,0 +1,81 @@
+package add_playlist
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Playlist struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description,omitempty" validate:"max=2000"`
+	Public      bool   `json:"public"`
+}
+
+// New creates a handler for adding a new playlist
+// @Summary Add a playlist
+// @Description Creates an empty playlist owned by the caller. Playlists are private unless public is set.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist body Playlist true "Information about the playlist"
+// @Success 201 {object} storage.Playlist "The created playlist"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists [post]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.add-playlist.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		owner := auth.Subject(r.Context())
+		if owner == "" {
+			problem.Error(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Authentication required")
+			log.Warn("Anonymous playlist creation")
+			return
+		}
+
+		var request Playlist
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		playlist := storage.Playlist{
+			Name:        request.Name,
+			Description: request.Description,
+			Owner:       owner,
+			Public:      request.Public,
+		}
+		id, err := store.InsertPlaylist(r.Context(), playlist)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to insert playlist", slog.Any("error", err))
+			return
+		}
+		playlist.ID = id
+
+		log.Info("Playlist successfully added", slog.Uint64("id", uint64(playlist.ID)), slog.String("owner", owner))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/playlists/"+strconv.FormatUint(uint64(playlist.ID), 10))
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(playlist); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+		}
+	}
+}
@@ -1,124 +1,137 @@
 package add_song
 
 import (
-	"effective-mobile/internal/storage/postgres"
-	"encoding/json"
-	"fmt"
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/details"
+	"effective-mobile/internal/storage"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 )
 
 type Song struct {
-	Group string `json:"group"`
-	Song  string `json:"song"`
-}
-
-type SongDetail struct {
-	ReleaseDate string `json:"releaseDate"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
+	Group string `json:"group" validate:"required,max=255"`
+	Song  string `json:"song" validate:"required,max=255"`
 }
 
 // @BasePath /song/add
 
 // New creates a handler for adding a new song
 // @Summary Add a new song
-// @Description Adds a song with information about the band, name, release date, lyrics and a link to YouTube
+// @Description Adds a song with information about the band, name, release date, lyrics and a link to YouTube.
+// @Description A group has one song with a given name, compared case-insensitively; adding it again fails
+// @Description with 409 unless upsert=true, which overwrites the existing song. Retries sent with the same
+// @Description Idempotency-Key replay the first response.
 // @Tags song
 // @Accept json
 // @Produce json
 // @Param song body Song true "Information about the song"
-// @Success 201 {string} string "Song added successfully"
-// @Failure 400 {string} string "Invalid JSON format"
-// @Failure 415 {string} string "Content-Type header is not application/json"
-// @Failure 500 {string} string "Internal server error"
+// @Param upsert query bool false "Overwrite the song if it already exists"
+// @Param Idempotency-Key header string false "Client-chosen key that makes retries safe"
+// @Success 200 {string} string "Song updated successfully, Location header points to /songs/{id}"
+// @Success 201 {string} string "Song added successfully, Location header points to /songs/{id}"
+// @Failure 400 {object} problem.Problem "Invalid JSON format"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 409 {object} problem.Problem "The song already exists or a request with the Idempotency-Key is in progress"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 422 {object} problem.Problem "The Idempotency-Key was used for a different request"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Failure 502 {object} problem.Problem "The details provider is unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [post]
-func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
+// @Router /song/add [post]
+func New(log *slog.Logger, store storage.SongStore, provider details.Provider) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.add-song.New"
-		log = log.With(
+		log := logctx.From(r.Context(), log).With(
 			slog.String("op", op),
 		)
 
 		log.Debug("Processing request", slog.Any("request", r))
 
-		ct := r.Header.Get("Content-Type")
-		if ct != "" {
-			mediaType := strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
-			if mediaType != "application/json" {
-				msg := "Content-Type header is not application/json"
-				http.Error(w, msg, http.StatusUnsupportedMediaType)
-				log.Info("%s", msg)
+		upsert := false
+		if v := r.URL.Query().Get("upsert"); v != "" {
+			var err error
+			if upsert, err = strconv.ParseBool(v); err != nil {
+				problem.Write(w, r, problem.Invalid("upsert", "Invalid upsert parameter, expected true or false"))
+				log.Error("Invalid upsert parameter", slog.String("upsert", v))
 				return
 			}
 		}
+
 		var song Song
-		err := json.NewDecoder(r.Body).Decode(&song)
-		if err != nil {
-			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
-			log.Error("Failed to decode JSON", slog.Any("error", err))
+		if err := validate.DecodeJSON(w, r, &song); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
 			return
 		}
 
 		log.Info("Received song data", slog.Any("song", song))
 		log.Debug("Decoded song data", slog.Any("decodedSong", song))
 
-		url := fmt.Sprintf("/info?group=%s&song=%s", song.Group, song.Song)
-		log.Info("Fetching song details from API", slog.String("url", url))
-		resp, err := http.Get(url)
+		log.Info("Fetching song details from API")
+		songDetail, err := provider.SongDetails(r.Context(), song.Group, song.Song)
 		if err != nil {
-			http.Error(w, "Error internal server", http.StatusInternalServerError)
-			log.Error("Failed to connect towards api", slog.Any("error", err))
-			return
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			if resp.StatusCode == http.StatusBadRequest {
-				http.Error(w, "Failed to get a successful response", http.StatusBadRequest)
-				log.Error("Bad request", slog.Any("statusCode", resp.StatusCode))
-				return
-			} else {
-				http.Error(w, "Failed to get a successful response", http.StatusInternalServerError)
-				log.Error("Internal server error", slog.Any("statusCode", resp.StatusCode))
+			if errors.Is(err, details.ErrBadRequest) {
+				problem.Error(w, r, http.StatusBadRequest, problem.CodeUpstreamRejected, "The music info API rejected the song")
+				log.Error("Bad request", slog.Any("error", err))
 				return
 			}
-		}
-		var songDetail SongDetail
-		err = json.NewDecoder(resp.Body).Decode(&songDetail)
-		if err != nil {
-			http.Error(w, "Failed to decode API response", http.StatusInternalServerError)
-			log.Error("Failed to decode API response", slog.Any("error", err))
+			problem.Error(w, r, http.StatusBadGateway, problem.CodeUpstreamUnavailable, "The music info API is unavailable")
+			log.Error("Failed to fetch song details", slog.Any("error", err))
 			return
 		}
 		log.Info("Received song details", slog.Any("songDetail", songDetail))
-		log.Debug("Decoded song details", slog.Any("decodedSongDetail", songDetail))
 
 		t, err := time.Parse("02.01.2006", songDetail.ReleaseDate)
 		if err != nil {
 			log.Error("Invalid date format from API", slog.Any("error", err))
-			http.Error(w, "Invalid date format from API", http.StatusInternalServerError)
+			problem.Error(w, r, http.StatusBadGateway, problem.CodeUpstreamUnavailable, "The music info API returned an invalid release date")
 			return
 		}
 		formattedDate := t.Format("2006-01-02")
 
 		log.Debug("Formatted release date", slog.String("formattedDate", formattedDate))
 
-		err = storage.InsertSong(postgres.Song{
+		newSong := storage.Song{
 			GroupName:   song.Group,
 			SongName:    song.Song,
 			ReleaseDate: formattedDate,
 			Lyrics:      songDetail.Text,
 			YoutubeLink: songDetail.Link,
-		})
+		}
+		var id uint
+		created := true
+		if upsert {
+			id, created, err = store.UpsertSong(r.Context(), newSong, audit.Actor(r))
+		} else {
+			id, err = store.InsertSong(r.Context(), newSong, audit.Actor(r))
+		}
 		if err != nil {
-			http.Error(w, "Error internal server", http.StatusInternalServerError)
+			problem.WriteError(w, r, err)
+			if errors.Is(err, storage.ErrSongExists) {
+				log.Warn("Song already exists", slog.String("song", song.Song), slog.String("group", song.Group))
+				return
+			}
 			log.Error("Failed to insert song at storage", slog.Any("error", err))
 			return
 		}
 
-		log.Info("Song successfully added", slog.String("song", song.Song), slog.String("group", song.Group))
+		w.Header().Set("Location", "/songs/"+strconv.FormatUint(uint64(id), 10))
+		if !created {
+			log.Info("Song successfully updated", slog.Uint64("id", uint64(id)), slog.String("song", song.Song), slog.String("group", song.Group))
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Song updated successfully"))
+			return
+		}
+		log.Info("Song successfully added", slog.Uint64("id", uint64(id)), slog.String("song", song.Song), slog.String("group", song.Group))
 		w.WriteHeader(http.StatusCreated)
 		w.Write([]byte("Song added successfully"))
 	}
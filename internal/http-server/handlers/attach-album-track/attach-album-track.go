@@ -0,0 +1,83 @@
+package attach_album_track
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type AttachTrackRequest struct {
+	SongID uint `json:"songId" validate:"required"`
+	// Number is the track number to insert the song at; omitted appends it.
+	Number int `json:"number,omitempty" validate:"min=0"`
+}
+
+// New creates a handler that puts a song on an album
+// @Summary Add a song to an album
+// @Description Inserts the song at the given track number and moves the following tracks down, or appends it when no number is given.
+// @Tags albums
+// @Accept json
+// @Param id path int true "Album ID"
+// @Param track body AttachTrackRequest true "Song and track number"
+// @Success 204 "The song was added to the album"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The album or song was not found"
+// @Failure 409 {object} problem.Problem "The song is already on the album"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums/{id}/tracks [post]
+func New(log *slog.Logger, store storage.AlbumStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.attach-album-track.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid album id"))
+			log.Error("Invalid album id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		var request AttachTrackRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		err = store.AttachTrack(r.Context(), uint(id), request.SongID, request.Number)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrAlbumNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeAlbumNotFound, "Album not found")
+				log.Warn("Album not found", slog.Uint64("id", id))
+			case errors.Is(err, storage.ErrSongNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("songId", uint64(request.SongID)))
+			case errors.Is(err, storage.ErrTrackExists):
+				problem.Error(w, r, http.StatusConflict, problem.CodeTrackExists, "Song is already on the album")
+				log.Warn("Song is already on the album", slog.Any("request", request))
+			default:
+				problem.WriteError(w, r, err)
+				log.Error("Failed to attach track", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Song added to album", slog.Uint64("id", id), slog.Any("request", request))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,58 @@
+package delete_album
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler for deleting an album
+// @Summary Delete an album
+// @Description Deletes the album with the given ID. Its songs stay in the library.
+// @Tags albums
+// @Param id path int true "Album ID"
+// @Success 204 "The album was successfully deleted"
+// @Failure 400 {object} problem.Problem "Invalid album id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The admin role is required"
+// @Failure 404 {object} problem.Problem "The album was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums/{id} [delete]
+func New(log *slog.Logger, store storage.AlbumStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.delete-album.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid album id"))
+			log.Error("Invalid album id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		err = store.DeleteAlbum(r.Context(), uint(id))
+		if err != nil {
+			if errors.Is(err, storage.ErrAlbumNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeAlbumNotFound, "Album not found")
+				log.Warn("Album not found", slog.Uint64("id", id))
+			} else {
+				problem.WriteError(w, r, err)
+				log.Error("Failed to delete album", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Album deleted successfully", slog.Uint64("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
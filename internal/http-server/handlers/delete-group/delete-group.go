@@ -0,0 +1,63 @@
+package delete_group
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler for deleting a group
+// @Summary Delete a group
+// @Description Deletes the group with the given ID. Groups that still have songs or albums cannot be deleted.
+// @Tags groups
+// @Param id path int true "Group ID"
+// @Success 204 "The group was successfully deleted"
+// @Failure 400 {object} problem.Problem "Invalid group id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The admin role is required"
+// @Failure 404 {object} problem.Problem "The group was not found"
+// @Failure 409 {object} problem.Problem "The group still has songs or albums"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /groups/{id} [delete]
+func New(log *slog.Logger, store storage.GroupStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.delete-group.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid group id"))
+			log.Error("Invalid group id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		err = store.DeleteGroup(r.Context(), uint(id))
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrGroupNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeGroupNotFound, "Group not found")
+				log.Warn("Group not found", slog.Uint64("id", id))
+			case errors.Is(err, storage.ErrGroupInUse):
+				problem.Error(w, r, http.StatusConflict, problem.CodeGroupInUse, "Group still has songs or albums")
+				log.Warn("Group still has songs or albums", slog.Uint64("id", id))
+			default:
+				problem.WriteError(w, r, err)
+				log.Error("Failed to delete group", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Group deleted successfully", slog.Uint64("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
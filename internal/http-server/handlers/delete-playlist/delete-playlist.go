@@ -0,0 +1,80 @@
+package delete_playlist
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that deletes a playlist
+// @Summary Delete a playlist
+// @Description Deletes the playlist. The songs stay in the library. Only the owner can delete a playlist.
+// @Tags playlists
+// @Param id path int true "Playlist ID"
+// @Success 204 "The playlist was deleted"
+// @Failure 400 {object} problem.Problem "Invalid playlist id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The playlist belongs to another user"
+// @Failure 404 {object} problem.Problem "The playlist was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id} [delete]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.delete-playlist.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid playlist id"))
+			log.Error("Invalid playlist id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		caller := auth.Subject(r.Context())
+		playlist, err := store.GetPlaylist(r.Context(), uint(id))
+		if err == nil && !playlist.Visible(caller) {
+			err = storage.ErrPlaylistNotFound
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get playlist", slog.Any("error", err))
+			return
+		}
+		if playlist.Owner != caller {
+			problem.Error(w, r, http.StatusForbidden, problem.CodeForbidden, "Only the owner can change the playlist")
+			log.Warn("Playlist change by another user", slog.Uint64("id", id), slog.String("user", caller))
+			return
+		}
+
+		err = store.DeletePlaylist(r.Context(), uint(id))
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to delete playlist", slog.Any("error", err))
+			return
+		}
+
+		log.Info("Playlist successfully deleted", slog.Uint64("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,75 @@
+package delete_song
+
+import (
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler for deleting a song by its ID
+// @Summary Delete a song by ID
+// @Description Moves the song with the given ID to the trash and takes it off its albums and playlists.
+// @Description It can be restored with POST /songs/{id}/restore until the trash is purged.
+// @Tags songs
+// @Param id path int true "Song ID"
+// @Param If-Match header string false "ETag of the song version the change is based on"
+// @Success 204 "The song was successfully deleted"
+// @Failure 400 {object} problem.Problem "Invalid song id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song was not found"
+// @Failure 412 {object} problem.Problem "The song has changed since the version in If-Match"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id} [delete]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.delete-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		versions, err := etag.IfMatch(r)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Warn("If-Match names no song version", slog.String("ifMatch", r.Header.Get("If-Match")))
+			return
+		}
+
+		err = versions.Try(func(version int) error {
+			return store.DeleteSongByID(r.Context(), uint(id), version, audit.Actor(r))
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("id", id))
+			} else if errors.Is(err, storage.ErrVersionMismatch) {
+				problem.WriteError(w, r, err)
+				log.Warn("Song has changed meanwhile", slog.Uint64("id", id), slog.Any("versions", versions))
+			} else {
+				problem.WriteError(w, r, err)
+				log.Error("Failed to delete song", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Song deleted successfully", slog.Uint64("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
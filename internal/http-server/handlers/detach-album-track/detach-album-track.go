@@ -0,0 +1,69 @@
+package detach_album_track
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that removes a song from an album
+// @Summary Remove a song from an album
+// @Description Removes the song from the album and renumbers the following tracks. The song stays in the library.
+// @Tags albums
+// @Param id path int true "Album ID"
+// @Param songId path int true "Song ID"
+// @Success 204 "The song was removed from the album"
+// @Failure 400 {object} problem.Problem "Invalid album or song id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The album was not found or the song is not on it"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums/{id}/tracks/{songId} [delete]
+func New(log *slog.Logger, store storage.AlbumStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.detach-album-track.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid album id"))
+			log.Error("Invalid album id", slog.String("id", r.PathValue("id")))
+			return
+		}
+		songID, err := strconv.ParseUint(r.PathValue("songId"), 10, 0)
+		if err != nil || songID == 0 {
+			problem.Write(w, r, problem.Invalid("songId", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("songId", r.PathValue("songId")))
+			return
+		}
+
+		err = store.DetachTrack(r.Context(), uint(id), uint(songID))
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrAlbumNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeAlbumNotFound, "Album not found")
+				log.Warn("Album not found", slog.Uint64("id", id))
+			case errors.Is(err, storage.ErrTrackNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeTrackNotFound, "Song is not on the album")
+				log.Warn("Song is not on the album", slog.Uint64("id", id), slog.Uint64("songId", songID))
+			default:
+				problem.WriteError(w, r, err)
+				log.Error("Failed to detach track", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Song removed from album", slog.Uint64("id", id), slog.Uint64("songId", songID))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,237 @@
+package export_songs
+
+import (
+	"bufio"
+	"effective-mobile/internal/http-server/listing"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/importer"
+	"effective-mobile/internal/storage"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encoder writes songs in one export format.
+type encoder interface {
+	begin() error
+	song(song storage.Song) error
+	end() error
+}
+
+type format struct {
+	contentType string
+	extension   string
+	newEncoder  func(w io.Writer) encoder
+}
+
+var formats = map[string]format{
+	"csv":    {"text/csv; charset=utf-8", "csv", newCSVEncoder},
+	"json":   {"application/json", "json", func(w io.Writer) encoder { return &jsonEncoder{w: w} }},
+	"ndjson": {"application/x-ndjson", "ndjson", func(w io.Writer) encoder { return &ndjsonEncoder{enc: json.NewEncoder(w)} }},
+	"m3u":    {"audio/x-mpegurl", "m3u", func(w io.Writer) encoder { return &m3uEncoder{w: w} }},
+}
+
+// New creates a handler that exports the library as a file
+// @Summary Export the song library
+// @Description Streams every song matching the filters of GET /songs in ID order as a file download.
+// @Description csv and ndjson list the columns and fields accepted by POST /songs/import, so an export can be
+// @Description imported again; json is an array of songs; m3u is a playlist of the songs with a YouTube link.
+// @Tags songs
+// @Produce text/csv
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce audio/x-mpegurl
+// @Param format query string false "csv, json (default), ndjson or m3u"
+// @Param album query []int false "Album ID" collectionFormat(multi)
+// @Param group query []string false "Group name" collectionFormat(multi)
+// @Param song query []string false "Song name" collectionFormat(multi)
+// @Param match query string false "How group and song are compared: exact (default), prefix or contains, the last two ignore case"
+// @Param releaseDate query []string false "Release date (YYYY-MM-DD)" collectionFormat(multi)
+// @Param releasedFrom query string false "Earliest release date (YYYY-MM-DD), inclusive"
+// @Param releasedTo query string false "Latest release date (YYYY-MM-DD), inclusive"
+// @Param hasLyrics query bool false "Only songs with (true) or without (false) lyrics"
+// @Param hasLink query bool false "Only songs with (true) or without (false) a YouTube link"
+// @Param lyrics query []string false "Words that must all occur in the lyrics, ignoring case" collectionFormat(multi)
+// @Success 200 {file} file "Exported songs"
+// @Failure 400 {object} problem.Problem "Bad request"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /songs/export [get]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.export-songs.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		name := strings.ToLower(r.URL.Query().Get("format"))
+		if name == "" {
+			name = "json"
+		}
+		f, ok := formats[name]
+		filter, err := listing.ParseFilter(r.URL.Query())
+		if !ok || err != nil {
+			var errs validate.Errors
+			if !ok {
+				errs = append(errs, validate.FieldError{Field: "format", Code: validate.CodeInvalid, Message: "Invalid format parameter, expected csv, json, ndjson or m3u"})
+			}
+			if err != nil {
+				errs = append(errs, err.(validate.Errors)...)
+			}
+			problem.Write(w, r, problem.Validation(errs...))
+			log.Error("Invalid export parameters", slog.Any("error", errs))
+			return
+		}
+
+		// Nothing reaches the client until the buffer fills up, so an error
+		// on the first rows can still be reported as a problem.
+		out := &sentWriter{w: w}
+		buf := bufio.NewWriterSize(out, 32<<10)
+		enc := f.newEncoder(buf)
+
+		filename := "songs-" + time.Now().UTC().Format("20060102-150405") + "." + f.extension
+		w.Header().Set("Content-Type", f.contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		count := 0
+		err = enc.begin()
+		if err == nil {
+			err = store.ExportSongs(r.Context(), filter, func(song storage.Song) error {
+				count++
+				return enc.song(song)
+			})
+		}
+		if err == nil {
+			err = enc.end()
+		}
+		if err == nil {
+			err = buf.Flush()
+		}
+		if err != nil {
+			if !out.sent && r.Context().Err() == nil {
+				w.Header().Del("Content-Disposition")
+				problem.WriteError(w, r, err)
+			}
+			log.Error("Failed to export songs", slog.Int("written", count), slog.Any("error", err))
+			return
+		}
+
+		log.Info("Songs exported", slog.String("format", name), slog.Int("count", count))
+	}
+}
+
+// sentWriter remembers whether anything was written to the client.
+type sentWriter struct {
+	w    io.Writer
+	sent bool
+}
+
+func (s *sentWriter) Write(p []byte) (int, error) {
+	s.sent = true
+	return s.w.Write(p)
+}
+
+// csvColumns match the columns POST /songs/import reads.
+var csvColumns = []string{"id", "group", "song", "releaseDate", "lyrics", "youtubeLink"}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) encoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (c *csvEncoder) begin() error {
+	return c.w.Write(csvColumns)
+}
+
+// song escapes the cells that a spreadsheet would run as formulas; the
+// importer reads them back unchanged.
+func (c *csvEncoder) song(song storage.Song) error {
+	return c.w.Write([]string{
+		strconv.FormatUint(uint64(song.ID), 10),
+		importer.EscapeCSV(song.GroupName),
+		importer.EscapeCSV(song.SongName),
+		song.ReleaseDate,
+		importer.EscapeCSV(song.Lyrics),
+		importer.EscapeCSV(song.YoutubeLink),
+	})
+}
+
+func (c *csvEncoder) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonEncoder) begin() error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonEncoder) song(song storage.Song) error {
+	b, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.count++
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonEncoder) end() error {
+	_, err := io.WriteString(j.w, "]\n")
+	return err
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonEncoder) begin() error { return nil }
+
+func (n *ndjsonEncoder) song(song storage.Song) error {
+	return n.enc.Encode(song)
+}
+
+func (n *ndjsonEncoder) end() error { return nil }
+
+// m3uEncoder writes an extended M3U playlist; songs without a link have no
+// location to play and are left out.
+type m3uEncoder struct {
+	w io.Writer
+}
+
+func (m *m3uEncoder) begin() error {
+	_, err := io.WriteString(m.w, "#EXTM3U\n")
+	return err
+}
+
+func (m *m3uEncoder) song(song storage.Song) error {
+	if song.YoutubeLink == "" {
+		return nil
+	}
+	title := strings.NewReplacer("\r", " ", "\n", " ").Replace(song.GroupName + " - " + song.SongName)
+	_, err := io.WriteString(m.w, "#EXTINF:-1,"+title+"\n"+song.YoutubeLink+"\n")
+	return err
+}
+
+func (m *m3uEncoder) end() error { return nil }
@@ -0,0 +1,234 @@
+package export_songs_test
+
+import (
+	"bufio"
+	"context"
+	"effective-mobile/internal/http-server/handlers/export-songs"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/services/importer"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/memory"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+var songs = []storage.Song{
+	{GroupName: "Muse", SongName: "Uprising", ReleaseDate: "2009-09-07", Lyrics: "one, \"two\"\n\nthree", YoutubeLink: "https://youtu.be/w8KQmps-Sog"},
+	{GroupName: "'Til Tuesday", SongName: "Voices Carry"},
+	{GroupName: "=HYPERLINK(\"http://evil\")", SongName: "+1", Lyrics: "- intro\n\n@verse"},
+	{GroupName: "Placebo", SongName: "'=quoted", Lyrics: "\tindented"},
+}
+
+func newStore(t *testing.T) storage.SongStore {
+	t.Helper()
+	store := memory.New()
+	for _, song := range songs {
+		if _, err := store.InsertSong(context.Background(), song, storage.Actor{}); err != nil {
+			t.Fatalf("InsertSong(%q): %v", song.SongName, err)
+		}
+	}
+	return store
+}
+
+func export(t *testing.T, store storage.SongStore, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	export_songs.New(discard, store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/export"+query, nil))
+	return w
+}
+
+func TestFormats(t *testing.T) {
+	store := newStore(t)
+	tests := []struct {
+		format      string
+		contentType string
+		extension   string
+		// check inspects the body.
+		check func(t *testing.T, body string)
+	}{
+		{
+			format:      "json",
+			contentType: "application/json",
+			extension:   ".json",
+			check: func(t *testing.T, body string) {
+				var got []storage.Song
+				if err := json.Unmarshal([]byte(body), &got); err != nil {
+					t.Fatalf("invalid JSON: %v", err)
+				}
+				if len(got) != len(songs) || got[2].GroupName != songs[2].GroupName {
+					t.Errorf("songs = %+v, want %d songs as stored", got, len(songs))
+				}
+			},
+		},
+		{
+			format:      "ndjson",
+			contentType: "application/x-ndjson",
+			extension:   ".ndjson",
+			check: func(t *testing.T, body string) {
+				lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+				if len(lines) != len(songs) {
+					t.Fatalf("%d lines, want %d", len(lines), len(songs))
+				}
+				var got storage.Song
+				if err := json.Unmarshal([]byte(lines[0]), &got); err != nil || got.Lyrics != songs[0].Lyrics {
+					t.Errorf("first line = %s, want %+v", lines[0], songs[0])
+				}
+			},
+		},
+		{
+			format:      "csv",
+			contentType: "text/csv; charset=utf-8",
+			extension:   ".csv",
+			check: func(t *testing.T, body string) {
+				records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+				if err != nil {
+					t.Fatalf("invalid CSV: %v", err)
+				}
+				if len(records) != len(songs)+1 || strings.Join(records[0], ",") != "id,group,song,releaseDate,lyrics,youtubeLink" {
+					t.Fatalf("records = %q, want a header and %d songs", records, len(songs))
+				}
+				// Cells a spreadsheet would run as formulas start with an
+				// apostrophe.
+				want := [][]string{
+					{"1", "Muse", "Uprising", "2009-09-07", songs[0].Lyrics, songs[0].YoutubeLink},
+					{"2", "'Til Tuesday", "Voices Carry", "", "", ""},
+					{"3", "'=HYPERLINK(\"http://evil\")", "'+1", "", "'- intro\n\n@verse", ""},
+					{"4", "Placebo", "''=quoted", "", "'\tindented", ""},
+				}
+				for i, record := range records[1:] {
+					if strings.Join(record, "|") != strings.Join(want[i], "|") {
+						t.Errorf("record %d = %q, want %q", i+1, record, want[i])
+					}
+				}
+			},
+		},
+		{
+			format:      "m3u",
+			contentType: "audio/x-mpegurl",
+			extension:   ".m3u",
+			check: func(t *testing.T, body string) {
+				want := "#EXTM3U\n#EXTINF:-1,Muse - Uprising\nhttps://youtu.be/w8KQmps-Sog\n"
+				if body != want {
+					t.Errorf("body = %q, want %q", body, want)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			w := export(t, store, "?format="+tt.format)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := w.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="songs-`) || !strings.HasSuffix(got, tt.extension+`"`) {
+				t.Errorf("Content-Disposition = %q, want an attachment ending in %s", got, tt.extension)
+			}
+			tt.check(t, w.Body.String())
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		if w := export(t, store, "?format=xml"); w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	store := newStore(t)
+	for _, format := range []importer.Format{importer.FormatCSV, importer.FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			w := export(t, store, "?format="+string(format))
+			if w.Code != http.StatusOK {
+				t.Fatalf("export status = %d: %s", w.Code, w.Body)
+			}
+
+			target := memory.New()
+			report, err := importer.New(discard, target, nil, importer.Config{}).Import(context.Background(), w.Body, format, importer.Options{})
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if report.Created != len(songs) {
+				t.Fatalf("imported %d of %d songs: %+v", report.Created, len(songs), report.Rows)
+			}
+			got, _, err := target.SelectSongs(context.Background(), storage.SongFilter{}, storage.Page{})
+			if err != nil {
+				t.Fatalf("SelectSongs: %v", err)
+			}
+			for i, song := range got {
+				want := songs[i]
+				if song.GroupName != want.GroupName || song.SongName != want.SongName || song.ReleaseDate != want.ReleaseDate ||
+					song.Lyrics != want.Lyrics || song.YoutubeLink != want.YoutubeLink {
+					t.Errorf("song %d = %+v, want %+v", i, song, want)
+				}
+			}
+		})
+	}
+}
+
+// failing exports after songs until the limit and then fails.
+type failing struct {
+	storage.SongStore
+	songs int
+}
+
+var errExport = &storage.Error{Op: "test.ExportSongs", Kind: storage.ErrTimeout, Err: errors.New("statement timeout")}
+
+func (f *failing) ExportSongs(ctx context.Context, filter storage.SongFilter, fn func(storage.Song) error) error {
+	for i := range f.songs {
+		song := storage.Song{ID: uint(i + 1), GroupName: "Muse", SongName: "Uprising", Lyrics: strings.Repeat("la ", 100)}
+		if err := fn(song); err != nil {
+			return err
+		}
+	}
+	return errExport
+}
+
+func TestExportError(t *testing.T) {
+	t.Run("before the first flush", func(t *testing.T) {
+		w := export(t, &failing{songs: 3}, "?format=ndjson")
+		want := problem.FromError(errExport)
+		if w.Code != want.Status {
+			t.Fatalf("status = %d, want %d", w.Code, want.Status)
+		}
+		if got := w.Header().Get("Content-Type"); got != problem.ContentType {
+			t.Errorf("Content-Type = %q, want %q", got, problem.ContentType)
+		}
+		if got := w.Header().Get("Content-Disposition"); got != "" {
+			t.Errorf("Content-Disposition = %q, want none", got)
+		}
+		var got problem.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Code != want.Code {
+			t.Errorf("body = %s, want a %s problem", w.Body, want.Code)
+		}
+	})
+
+	t.Run("after the first flush", func(t *testing.T) {
+		// The songs fill the buffer several times over, so the response has
+		// started and the export can only stop short.
+		w := export(t, &failing{songs: 500}, "?format=ndjson")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		lines := 0
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			lines++
+		}
+		if lines == 0 || lines >= 500 {
+			t.Errorf("%d lines, want a partial export", lines)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package get_group
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that returns a single group by its ID
+// @Summary Get a group
+// @Description Returns the group with the given ID.
+// @Tags groups
+// @Produce json
+// @Param id path int true "Group ID"
+// @Success 200 {object} storage.Group "The group"
+// @Failure 400 {object} problem.Problem "Invalid group id"
+// @Failure 404 {object} problem.Problem "The group was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /groups/{id} [get]
+func New(log *slog.Logger, store storage.GroupStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get-group.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid group id"))
+			log.Error("Invalid group id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		group, err := store.GetGroup(r.Context(), uint(id))
+		if err != nil {
+			if errors.Is(err, storage.ErrGroupNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeGroupNotFound, "Group not found")
+				log.Warn("Group not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get group", slog.Any("error", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(group); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Group sent to client", slog.Uint64("id", id))
+	}
+}
@@ -0,0 +1,64 @@
+package get_playlist
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that returns a single playlist by its ID
+// @Summary Get a playlist
+// @Description Returns the playlist with the given ID. Private playlists are only visible to their owner.
+// @Tags playlists
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Success 200 {object} storage.Playlist "The playlist"
+// @Failure 400 {object} problem.Problem "Invalid playlist id"
+// @Failure 404 {object} problem.Problem "The playlist was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /playlists/{id} [get]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get-playlist.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid playlist id"))
+			log.Error("Invalid playlist id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		playlist, err := store.GetPlaylist(r.Context(), uint(id))
+		if err == nil && !playlist.Visible(auth.Subject(r.Context())) {
+			err = storage.ErrPlaylistNotFound
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get playlist", slog.Any("error", err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(playlist); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Playlist sent to client", slog.Uint64("id", id))
+	}
+}
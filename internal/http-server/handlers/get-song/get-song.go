@@ -0,0 +1,62 @@
+package get_song
+
+import (
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that returns a single song by its ID
+// @Summary Get a song
+// @Description Returns the song with the given ID, including its lyrics and link.
+// @Tags songs
+// @Produce json
+// @Param id path int true "Song ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} storage.Song "The song"
+// @Success 304 "The cached copy is current"
+// @Header 200 {string} ETag "Version of the song, for If-Match"
+// @Failure 400 {object} problem.Problem "Invalid song id"
+// @Failure 404 {object} problem.Problem "The song was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /songs/{id} [get]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		song, err := store.GetSong(r.Context(), uint(id))
+		if err != nil {
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get song", slog.Any("error", err))
+			return
+		}
+
+		if err := etag.WriteJSON(w, r, etag.Song(song.Version), song); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Song sent to client", slog.Uint64("id", id))
+	}
+}
@@ -0,0 +1,113 @@
+package import_songs
+
+import (
+	"context"
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/importer"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// Importer stores the songs of an import file.
+type Importer interface {
+	Import(ctx context.Context, r io.Reader, format importer.Format, opts importer.Options) (importer.Report, error)
+}
+
+// New creates a handler that imports songs from a CSV or NDJSON file
+// @Summary Import songs
+// @Description Imports songs from the request body: CSV with a header row naming the group, song, releaseDate,
+// @Description lyrics and youtubeLink columns, or NDJSON with one song object per line. Only group and song are
+// @Description required; missing details are fetched from the music info API unless enrich=false.
+// @Description Songs that already exist are skipped unless upsert=true. The report lists every row with its
+// @Description status (created, updated, skipped or failed); a file with failed rows still returns 200.
+// @Tags songs
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param file body string true "CSV or NDJSON file"
+// @Param format query string false "csv or ndjson, overrides the Content-Type"
+// @Param upsert query bool false "Overwrite songs that already exist"
+// @Param enrich query bool false "Fetch missing details from the music info API (true by default)"
+// @Success 200 {object} importer.Report "Import report"
+// @Failure 400 {object} problem.Problem "Invalid parameters or an unreadable file"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 413 {object} problem.Problem "The file is too large"
+// @Failure 415 {object} problem.Problem "Unknown file format"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/import [post]
+func New(log *slog.Logger, im Importer, maxSize int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.import-songs.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		q := r.URL.Query()
+		name := q.Get("format")
+		if name == "" {
+			name, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+		}
+		format, err := importer.ParseFormat(name)
+		if err != nil {
+			problem.Error(w, r, http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType, "Send text/csv or application/x-ndjson, or set the format parameter")
+			log.Warn("Unknown import format", slog.String("format", name))
+			return
+		}
+
+		opts := importer.Options{Enrich: true, Actor: audit.Actor(r)}
+		var errs validate.Errors
+		for _, param := range []struct {
+			key string
+			dst *bool
+		}{{"upsert", &opts.Upsert}, {"enrich", &opts.Enrich}} {
+			if v := q.Get(param.key); v != "" {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					errs = append(errs, validate.FieldError{Field: param.key, Code: validate.CodeInvalid, Message: "Invalid " + param.key + " parameter, expected true or false"})
+					continue
+				}
+				*param.dst = b
+			}
+		}
+		if len(errs) > 0 {
+			problem.Write(w, r, problem.Validation(errs...))
+			log.Error("Invalid import parameters", slog.Any("error", errs))
+			return
+		}
+
+		log.Info("Importing songs", slog.String("format", string(format)), slog.Bool("upsert", opts.Upsert), slog.Bool("enrich", opts.Enrich))
+		report, err := im.Import(r.Context(), http.MaxBytesReader(w, r.Body, maxSize), format, opts)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			switch {
+			case errors.As(err, &tooLarge):
+				problem.Error(w, r, http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge, "The file is larger than "+strconv.FormatInt(maxSize, 10)+" bytes")
+			case errors.Is(err, importer.ErrInvalidFile):
+				problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidImportFile, errors.Unwrap(err).Error())
+			default:
+				problem.WriteError(w, r, err)
+			}
+			log.Error("Import failed", slog.Any("error", err))
+			return
+		}
+
+		log.Info("Songs imported", slog.Int("total", report.Total), slog.Int("failed", report.Failed))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Error("Failed to encode response", slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package liveness
+
+import (
+	"effective-mobile/internal/lib/logctx"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+type Response struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// New creates a handler that tells orchestrators the process is alive. It
+// checks no dependencies, so an outage of the database does not get the
+// process restarted
+// @Summary Liveness probe
+// @Description Returns 200 as long as the process serves HTTP.
+// @Tags health
+// @Produce json
+// @Success 200 {object} Response "The process is alive"
+// @Router /healthz [get]
+func New(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.liveness.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(Response{Status: "ok"}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+		}
+	}
+}
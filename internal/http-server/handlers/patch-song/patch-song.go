@@ -0,0 +1,123 @@
+package patch_song
+
+import (
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// PatchSongRequest leaves out the fields that stay unchanged; null clears
+// releaseDate, lyrics and youtubeLink.
+type PatchSongRequest struct {
+	Group       validate.Optional[string] `json:"group,omitempty" validate:"notblank,max=255" swaggertype:"string"`
+	Song        validate.Optional[string] `json:"song,omitempty" validate:"notblank,max=255" swaggertype:"string"`
+	ReleaseDate validate.Optional[string] `json:"releaseDate,omitempty" validate:"date=2006-01-02" swaggertype:"string"`
+	Lyrics      validate.Optional[string] `json:"lyrics,omitempty" swaggertype:"string"`
+	YoutubeLink validate.Optional[string] `json:"youtubeLink,omitempty" validate:"max=255,youtube" swaggertype:"string"`
+	// Verses edits the lyrics verse by verse, in order; it cannot be
+	// combined with lyrics.
+	Verses []storage.VerseEdit `json:"verses,omitempty" validate:"dive"`
+}
+
+// New creates a handler that partially updates a song
+// @Summary Partially update a song
+// @Description Updates only the fields present in the body of the song with the given ID. null clears
+// @Description releaseDate, lyrics or youtubeLink. verses edits single verses of the lyrics (verses are
+// @Description separated by a blank line and numbered from 1): replace sets verse N to text, insert puts text
+// @Description before verse N or after the last one, delete removes verse N. The edits apply in order.
+// @Tags songs
+// @Accept json
+// @Param id path int true "Song ID"
+// @Param If-Match header string false "ETag of the song version the change is based on"
+// @Param song body PatchSongRequest true "Fields to update, releaseDate in YYYY-MM-DD"
+// @Success 204 "The song was successfully updated"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song was not found"
+// @Failure 409 {object} problem.Problem "The group already has a song with that name"
+// @Failure 412 {object} problem.Problem "The song has changed since the version in If-Match"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id} [patch]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.patch-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		versions, err := etag.IfMatch(r)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Warn("If-Match names no song version", slog.String("ifMatch", r.Header.Get("If-Match")))
+			return
+		}
+
+		var request PatchSongRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		if request.Lyrics.Set && len(request.Verses) > 0 {
+			problem.Write(w, r, problem.Invalid("verses", "Cannot be combined with lyrics"))
+			log.Error("Both lyrics and verse edits", slog.Uint64("id", id))
+			return
+		}
+		update := storage.SongUpdate{
+			GroupName:   request.Group.Ptr(),
+			SongName:    request.Song.Ptr(),
+			ReleaseDate: request.ReleaseDate.Ptr(),
+			Lyrics:      request.Lyrics.Ptr(),
+			YoutubeLink: request.YoutubeLink.Ptr(),
+			Verses:      request.Verses,
+		}
+		if update.IsEmpty() {
+			problem.Error(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "No fields to update")
+			log.Error("Empty update", slog.Uint64("id", id))
+			return
+		}
+
+		err = versions.Try(func(version int) error {
+			return store.UpdateSongByID(r.Context(), uint(id), update, version, audit.Actor(r))
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrVersionMismatch) {
+				problem.WriteError(w, r, err)
+				log.Warn("Song has changed meanwhile", slog.Uint64("id", id), slog.Any("versions", versions))
+				return
+			}
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to update song", slog.Any("error", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		log.Info("Song updated successfully", slog.Uint64("id", id))
+	}
+}
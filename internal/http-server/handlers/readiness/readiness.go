@@ -0,0 +1,41 @@
+package readiness
+
+import (
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/health"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// New creates a handler that tells load balancers and orchestrators whether
+// the service can take traffic
+// @Summary Readiness probe
+// @Description Checks the storage, the database schema and the details provider and reports every check with its latency. The service is ready, or degraded when only the details provider fails; it is unavailable when the storage or the schema fails and shutting down once it has been told to stop.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report "The service can take traffic"
+// @Failure 503 {object} health.Report "The service is unavailable or shutting down"
+// @Router /readyz [get]
+func New(log *slog.Logger, checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.readiness.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		report := checker.Check(r.Context())
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+			log.Warn("Service not ready", slog.Any("report", report))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+		}
+	}
+}
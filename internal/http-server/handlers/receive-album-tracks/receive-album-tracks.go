@@ -0,0 +1,75 @@
+package receive_album_tracks
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type TracklistResponse struct {
+	Album  storage.Album   `json:"album"`
+	Tracks []storage.Track `json:"tracks"`
+}
+
+// New creates a handler that returns the tracklist of an album
+// @Summary Get the tracklist of an album
+// @Description Returns the album and its songs ordered by track number.
+// @Tags albums
+// @Produce json
+// @Param id path int true "Album ID"
+// @Success 200 {object} TracklistResponse "The tracklist"
+// @Failure 400 {object} problem.Problem "Invalid album id"
+// @Failure 404 {object} problem.Problem "The album was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /albums/{id}/tracks [get]
+func New(log *slog.Logger, store storage.AlbumStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.receive-album-tracks.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid album id"))
+			log.Error("Invalid album id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		album, err := store.GetAlbum(r.Context(), uint(id))
+		if err != nil {
+			if errors.Is(err, storage.ErrAlbumNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeAlbumNotFound, "Album not found")
+				log.Warn("Album not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get album", slog.Any("error", err))
+			return
+		}
+
+		tracks, err := store.SelectTracks(r.Context(), uint(id))
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to select tracks", slog.Any("error", err))
+			return
+		}
+		if tracks == nil {
+			tracks = []storage.Track{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(TracklistResponse{Album: album, Tracks: tracks}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Tracklist sent to client", slog.Uint64("id", id), slog.Int("tracks", len(tracks)))
+	}
+}
@@ -0,0 +1,88 @@
+package receive_group_songs
+
+import (
+	"effective-mobile/internal/http-server/listing"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that lists the songs of a group
+// @Summary List the songs of a group
+// @Description Returns the songs of the group page by page. Accepts the same filter, sort and page parameters as GET /songs.
+// @Tags groups
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param pageSize query int false "Songs per page (20 by default, at most 100)"
+// @Param sort query string false "Comma separated field:asc|desc"
+// @Success 200 {object} listing.SongsResponse "Page of songs"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 404 {object} problem.Problem "The group was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /groups/{id}/songs [get]
+func New(log *slog.Logger, groups storage.GroupStore, songs storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.receive-group-songs.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid group id"))
+			log.Error("Invalid group id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		filter, p, err := listing.Parse(r.URL.Query())
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid listing parameters", slog.Any("error", err))
+			return
+		}
+		filter.GroupIDs = []uint{uint(id)}
+
+		if _, err := groups.GetGroup(r.Context(), uint(id)); err != nil {
+			if errors.Is(err, storage.ErrGroupNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeGroupNotFound, "Group not found")
+				log.Warn("Group not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get group", slog.Any("error", err))
+			return
+		}
+
+		res, total, err := songs.SelectSongs(r.Context(), filter, p.Page)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to select songs", slog.Any("error", err))
+			return
+		}
+
+		response := listing.SongsResponse{
+			Items: res,
+			Total: total,
+			Limit: p.Page.Limit,
+		}
+		if response.Items == nil {
+			response.Items = []storage.Song{}
+		}
+		response.Links.Next, response.Links.Prev = p.Links(r.URL, res, total)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Group songs sent to client", slog.Uint64("id", id), slog.Int("count", len(res)))
+	}
+}
@@ -0,0 +1,89 @@
+package receive_groups
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type GroupsResponse struct {
+	Items  []storage.Group `json:"items"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// New creates a handler that lists groups
+// @Summary List groups
+// @Description Returns groups ordered by name.
+// @Tags groups
+// @Produce json
+// @Param limit query int false "Groups per page (20 by default, at most 100)"
+// @Param offset query int false "Number of groups to skip"
+// @Success 200 {object} GroupsResponse "Page of groups"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /groups [get]
+func New(log *slog.Logger, store storage.GroupStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.receive-groups.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		limit := defaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			var err error
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				problem.Write(w, r, problem.Invalid("limit", "Invalid limit parameter"))
+				log.Error("Invalid limit parameter", slog.String("limit", v))
+				return
+			}
+			limit = min(limit, maxLimit)
+		}
+		offset := 0
+		if v := r.URL.Query().Get("offset"); v != "" {
+			var err error
+			offset, err = strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				problem.Write(w, r, problem.Invalid("offset", "Invalid offset parameter"))
+				log.Error("Invalid offset parameter", slog.String("offset", v))
+				return
+			}
+		}
+
+		groups, total, err := store.SelectGroups(r.Context(), limit, offset)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to select groups", slog.Any("error", err))
+			return
+		}
+		if groups == nil {
+			groups = []storage.Group{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(GroupsResponse{
+			Items:  groups,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Groups sent to client", slog.Int("count", len(groups)), slog.Int("total", total))
+	}
+}
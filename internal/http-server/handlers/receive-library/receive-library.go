@@ -1,82 +1,85 @@
 package receive_library
 
 import (
-	"effective-mobile/internal/storage/postgres"
-	"effective-mobile/internal/storage/postgres/queries"
-	"encoding/json"
-	"fmt"
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/listing"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
 	"log/slog"
 	"net/http"
-
-	_ "github.com/lib/pq"
 )
 
 // @BasePath /song/library
 
 // New godoc
 // @Summary Retrieve the user's song library
-// @Description Retrieves the user's entire song library, optionally filtered by group, song, or release date.
+// @Description Retrieves the user's song library page by page, optionally filtered by group, song, release date, lyrics and link presence.
+// @Description album, group, song, releaseDate and lyrics may be repeated; a song matches any of the repeated values.
+// @Description Pages are selected with page/pageSize, limit/offset or the opaque cursor from the next/prev links.
 // @Tags songs
 // @Produce  json
-// @Param group query string false "Group name"
-// @Param song query string false "Song name"
-// @Param releaseDate query string false "Release date (YYYY-MM-DD)"
-// @Success 200 "List of songs"
-// @Failure 400 {string} string "Bad request"
-// @Failure 500 {string} string "Internal server error"
+// @Param album query []int false "Album ID" collectionFormat(multi)
+// @Param group query []string false "Group name" collectionFormat(multi)
+// @Param song query []string false "Song name" collectionFormat(multi)
+// @Param match query string false "How group and song are compared: exact (default), prefix or contains, the last two ignore case"
+// @Param releaseDate query []string false "Release date (YYYY-MM-DD)" collectionFormat(multi)
+// @Param releasedFrom query string false "Earliest release date (YYYY-MM-DD), inclusive"
+// @Param releasedTo query string false "Latest release date (YYYY-MM-DD), inclusive"
+// @Param hasLyrics query bool false "Only songs with (true) or without (false) lyrics"
+// @Param hasLink query bool false "Only songs with (true) or without (false) a YouTube link"
+// @Param lyrics query []string false "Words that must all occur in the lyrics, ignoring case" collectionFormat(multi)
+// @Param page query int false "Page number, starting at 1"
+// @Param pageSize query int false "Songs per page (20 by default, at most 100)"
+// @Param limit query int false "Alias of pageSize"
+// @Param offset query int false "Number of songs to skip"
+// @Param cursor query string false "Cursor from a next/prev link, requires sorting by id"
+// @Param sort query string false "Comma separated field:asc|desc, fields: id, group, song, releaseDate, lyrics, youtubeLink"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} listing.SongsResponse "Page of songs"
+// @Success 304 "The cached copy is current"
+// @Failure 400 {object} problem.Problem "Bad request"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /songs [get]
 // @Router /song/library [get]
-func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.receive-library.New"
-		log = log.With(
+		log := logctx.From(r.Context(), log).With(
 			slog.String("op", op),
 		)
 
 		log.Debug("Received a request", slog.Any("request", r))
 
-		group := r.URL.Query().Get("group")
-		song := r.URL.Query().Get("song")
-		releaseDate := r.URL.Query().Get("releaseDate")
-		log.Info("Incoming request parameters",
-			slog.String("group", group),
-			slog.String("song", song),
-			slog.String("releaseDate", releaseDate),
-		)
-		query := queries.GetLibrary
-
-		var filters []interface{}
-		count := 1
-		if group != "" {
-			query += fmt.Sprintf(" AND group_name = $%v", count)
-			filters = append(filters, group)
-			count++
-		}
-		if song != "" {
-			query += fmt.Sprintf(" AND song_name = $%v", count)
-			filters = append(filters, song)
-			count++
-		}
-		if releaseDate != "" {
-			query += fmt.Sprintf(" AND release_date = $%v", count)
-			filters = append(filters, releaseDate)
+		filter, p, err := listing.Parse(r.URL.Query())
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid listing parameters", slog.Any("error", err))
+			return
 		}
+		log.Info("Selecting songs", slog.Any("filter", filter), slog.Any("page", p.Page))
 
-		log.Info("Executing SQL query", slog.String("query", query), slog.Any("filters", filters))
-
-		res, err := storage.SelectSongs(query, filters)
+		res, total, err := store.SelectSongs(r.Context(), filter, p.Page)
 		if err != nil {
-			http.Error(w, "Failed to get a successful response", http.StatusInternalServerError)
-			log.Error("Failed to select", slog.Any("statusCode", err))
+			problem.WriteError(w, r, err)
+			log.Error("Failed to select", slog.Any("error", err))
 			return
 		}
 
-		log.Info("Songs retrieved", slog.Int("count", len(res)))
+		log.Info("Songs retrieved", slog.Int("count", len(res)), slog.Int("total", total))
 		log.Debug("Retrieved songs data", slog.Any("songs", res))
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(res); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		response := listing.SongsResponse{
+			Items: res,
+			Total: total,
+			Limit: p.Page.Limit,
+		}
+		if response.Items == nil {
+			response.Items = []storage.Song{}
+		}
+		response.Links.Next, response.Links.Prev = p.Links(r.URL, res, total)
+
+		if err := etag.WriteJSON(w, r, "", response); err != nil {
 			log.Error("Failed to encode", slog.Any("statusCode", err))
 		}
 		log.Info("Request successfully processed", slog.Int("status", http.StatusOK))
@@ -1,16 +1,15 @@
 package receive_lyrics
 
 import (
-	"database/sql"
-	"effective-mobile/internal/storage/postgres"
-	"encoding/json"
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
 	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
-
-	_ "github.com/lib/pq"
 )
 
 type SongLyricsResponse struct {
@@ -28,16 +27,19 @@ type SongLyricsResponse struct {
 // @Produce json
 // @Param group query string true "group"
 // @Param song query string true "song"
-// @Param page query int false "Page number (default is 1)"
-// @Param limit query int false "Number of verses per page (2 by default))"
+// @Param page query int false "Page number (default is 1)" minimum(1)
+// @Param limit query int false "Number of verses per page (2 by default))" minimum(1)
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} SongLyricsResponse "Lyrics by page"
-// @Failure 400 {string} string "Invalid request parameters"
-// @Failure 500 {string} string "Server error"
+// @Success 304 "The cached copy is current"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 404 {object} problem.Problem "The song was not found"
+// @Failure 500 {object} problem.Problem "Server error"
 // @Router /song/lyrics [get]
-func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.receive-lyrics.New"
-		log = log.With(
+		log := logctx.From(r.Context(), log).With(
 			slog.String("op", op),
 		)
 
@@ -59,8 +61,8 @@ func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
 		if pageStr != "" {
 			var err error
 			page, err = strconv.Atoi(pageStr)
-			if err != nil {
-				http.Error(w, "Invalid page parameter", http.StatusBadRequest)
+			if err != nil || page < 1 {
+				problem.Write(w, r, problem.Invalid("page", "Invalid page parameter, expected a positive number"))
 				log.Error("Invalid page parameter", slog.Any("error", err))
 				return
 			}
@@ -70,21 +72,21 @@ func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
 		if limitStr != "" {
 			var err error
 			limit, err = strconv.Atoi(limitStr)
-			if err != nil {
-				http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			if err != nil || limit < 1 {
+				problem.Write(w, r, problem.Invalid("limit", "Invalid limit parameter, expected a positive number"))
 				log.Error("Invalid limit parameter", slog.Any("error", err))
 				return
 			}
 			log.Debug("Parsed limit parameter", slog.Int("limit", limit))
 		}
-		lyrics, err := storage.GetLyrics(song, group)
+		lyrics, err := store.GetLyrics(r.Context(), song, group)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				http.Error(w, "Failed to get song lyrics", http.StatusBadRequest)
-				log.Error("Bad request", slog.Any("error", err))
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.String("song", song), slog.String("group", group))
 				return
 			} else {
-				http.Error(w, "Failed to get song lyrics", http.StatusInternalServerError)
+				problem.WriteError(w, r, err)
 				log.Error("Failed to select lyrics", slog.Any("error", err))
 				return
 			}
@@ -93,20 +95,16 @@ func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
 		log.Info("Lyrics retrieved from storage", slog.String("song", song), slog.String("group", group))
 
 		// Dividing the lyrics into verses
-		verses := strings.Split(lyrics, "\n\n")
+		verses := strings.Split(lyrics, storage.VerseSeparator)
 
 		// Total page count
-		totalPages := (len(verses) + limit - 1) / limit
+		totalPages := (len(verses)-1)/limit + 1
 		log.Debug("Total pages calculated", slog.Int("totalPages", totalPages))
 
 		// The restriction for the page is not to go beyond
 		if page > totalPages {
 			page = totalPages
 			log.Debug("Adjusted page to total pages", slog.Int("adjustedPage", page))
-
-		} else if page < 1 {
-			page = 1
-			log.Debug("Adjusted page to minimum", slog.Int("adjustedPage", page))
 		}
 
 		// Calculating the beginning and end of the verses for the current page
@@ -122,9 +120,7 @@ func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
 			Lyrics:      verses[start:end],
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		if err := etag.WriteJSON(w, r, "", response); err != nil {
 			log.Error("Failed to encode JSON response", slog.Any("error", err))
 			return
 		}
@@ -0,0 +1,79 @@
+package receive_playlist_songs
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type PlaylistSongsResponse struct {
+	Playlist storage.Playlist `json:"playlist"`
+	Songs    []storage.Entry  `json:"songs"`
+}
+
+// New creates a handler that returns the songs of a playlist
+// @Summary Get the songs of a playlist
+// @Description Returns the playlist with its songs in order. Private playlists are only visible to their owner.
+// @Tags playlists
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Success 200 {object} PlaylistSongsResponse "The playlist and its songs"
+// @Failure 400 {object} problem.Problem "Invalid playlist id"
+// @Failure 404 {object} problem.Problem "The playlist was not found"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /playlists/{id}/songs [get]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.receive-playlist-songs.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid playlist id"))
+			log.Error("Invalid playlist id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		playlist, err := store.GetPlaylist(r.Context(), uint(id))
+		if err == nil && !playlist.Visible(auth.Subject(r.Context())) {
+			err = storage.ErrPlaylistNotFound
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get playlist", slog.Any("error", err))
+			return
+		}
+
+		entries, err := store.SelectEntries(r.Context(), uint(id))
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to select entries", slog.Any("error", err))
+			return
+		}
+		if entries == nil {
+			entries = []storage.Entry{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(PlaylistSongsResponse{Playlist: playlist, Songs: entries}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Playlist songs sent to client", slog.Uint64("id", id), slog.Int("songs", len(entries)))
+	}
+}
@@ -0,0 +1,95 @@
+package receive_playlists
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type PlaylistsResponse struct {
+	Items  []storage.Playlist `json:"items"`
+	Total  int                `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+// New creates a handler that lists playlists
+// @Summary List playlists
+// @Description Returns public playlists and the private playlists of the caller, ordered by name.
+// @Tags playlists
+// @Produce json
+// @Param owner query string false "Only list the playlists of this user"
+// @Param limit query int false "Playlists per page (20 by default, at most 100)"
+// @Param offset query int false "Number of playlists to skip"
+// @Success 200 {object} PlaylistsResponse "Page of playlists"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /playlists [get]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.receive-playlists.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		limit := defaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			var err error
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				problem.Write(w, r, problem.Invalid("limit", "Invalid limit parameter"))
+				log.Error("Invalid limit parameter", slog.String("limit", v))
+				return
+			}
+			limit = min(limit, maxLimit)
+		}
+		offset := 0
+		if v := r.URL.Query().Get("offset"); v != "" {
+			var err error
+			offset, err = strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				problem.Write(w, r, problem.Invalid("offset", "Invalid offset parameter"))
+				log.Error("Invalid offset parameter", slog.String("offset", v))
+				return
+			}
+		}
+
+		filter := storage.PlaylistFilter{
+			Owner:  r.URL.Query().Get("owner"),
+			Viewer: auth.Subject(r.Context()),
+		}
+		playlists, total, err := store.SelectPlaylists(r.Context(), filter, limit, offset)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to select playlists", slog.Any("error", err))
+			return
+		}
+		if playlists == nil {
+			playlists = []storage.Playlist{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(PlaylistsResponse{
+			Items:  playlists,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Playlists sent to client", slog.Int("count", len(playlists)), slog.Int("total", total))
+	}
+}
@@ -0,0 +1,94 @@
+package receive_trash
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type TrashResponse struct {
+	Items  []storage.DeletedSong `json:"items"`
+	Total  int                   `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+// New creates a handler that lists the deleted songs
+// @Summary List deleted songs
+// @Description Returns the songs in the trash, most recently deleted first. Deleted songs are purged for good
+// @Description once they have been in the trash for longer than the configured retention.
+// @Tags songs
+// @Produce json
+// @Param limit query int false "Songs per page (20 by default, at most 100)"
+// @Param offset query int false "Number of songs to skip"
+// @Success 200 {object} TrashResponse "Page of deleted songs"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/trash [get]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.receive-trash.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		limit := defaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			var err error
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				problem.Write(w, r, problem.Invalid("limit", "Invalid limit parameter"))
+				log.Error("Invalid limit parameter", slog.String("limit", v))
+				return
+			}
+			limit = min(limit, maxLimit)
+		}
+		offset := 0
+		if v := r.URL.Query().Get("offset"); v != "" {
+			var err error
+			offset, err = strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				problem.Write(w, r, problem.Invalid("offset", "Invalid offset parameter"))
+				log.Error("Invalid offset parameter", slog.String("offset", v))
+				return
+			}
+		}
+
+		songs, total, err := store.SelectTrash(r.Context(), limit, offset)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to select deleted songs", slog.Any("error", err))
+			return
+		}
+		if songs == nil {
+			songs = []storage.DeletedSong{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(TrashResponse{
+			Items:  songs,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Deleted songs sent to client", slog.Int("count", len(songs)), slog.Int("total", total))
+	}
+}
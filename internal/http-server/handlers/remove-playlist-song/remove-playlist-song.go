@@ -0,0 +1,91 @@
+package remove_playlist_song
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that removes a song from a playlist
+// @Summary Remove a song from a playlist
+// @Description Removes the song from the playlist and moves the following songs up. The song stays in the library.
+// @Tags playlists
+// @Param id path int true "Playlist ID"
+// @Param songId path int true "Song ID"
+// @Success 204 "The song was removed from the playlist"
+// @Failure 400 {object} problem.Problem "Invalid playlist or song id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The playlist belongs to another user"
+// @Failure 404 {object} problem.Problem "The playlist was not found or the song is not in it"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id}/songs/{songId} [delete]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.remove-playlist-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid playlist id"))
+			log.Error("Invalid playlist id", slog.String("id", r.PathValue("id")))
+			return
+		}
+		songID, err := strconv.ParseUint(r.PathValue("songId"), 10, 0)
+		if err != nil || songID == 0 {
+			problem.Write(w, r, problem.Invalid("songId", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("songId", r.PathValue("songId")))
+			return
+		}
+
+		caller := auth.Subject(r.Context())
+		playlist, err := store.GetPlaylist(r.Context(), uint(id))
+		if err == nil && !playlist.Visible(caller) {
+			err = storage.ErrPlaylistNotFound
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get playlist", slog.Any("error", err))
+			return
+		}
+		if playlist.Owner != caller {
+			problem.Error(w, r, http.StatusForbidden, problem.CodeForbidden, "Only the owner can change the playlist")
+			log.Warn("Playlist change by another user", slog.Uint64("id", id), slog.String("user", caller))
+			return
+		}
+
+		err = store.RemoveEntry(r.Context(), uint(id), uint(songID))
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrPlaylistNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+			case errors.Is(err, storage.ErrEntryNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeEntryNotFound, "Song is not in the playlist")
+				log.Warn("Song is not in the playlist", slog.Uint64("id", id), slog.Uint64("songId", songID))
+			default:
+				problem.WriteError(w, r, err)
+				log.Error("Failed to remove entry", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Song removed from playlist", slog.Uint64("id", id), slog.Uint64("songId", songID))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
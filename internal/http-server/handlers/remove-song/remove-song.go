@@ -1,61 +1,61 @@
 package remove_song
 
 import (
-	"effective-mobile/internal/storage/postgres"
-	"encoding/json"
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
 	"errors"
 	"log/slog"
 	"net/http"
-	"strings"
 )
 
 type Song struct {
-	Group string `json:"group"`
-	Song  string `json:"song"`
+	Group string `json:"group" validate:"required,max=255"`
+	Song  string `json:"song" validate:"required,max=255"`
 }
 
 // New creates a handler for deleting a song
 // @Summary Delete a song
-// @Description Deletes a song from the repository by the name of the band and the name of the song.
+// @Description Deprecated, use DELETE /songs/{id}. Moves a song to the trash by the name of the band and the name of the song.
 // @Tags song
 // @Accept json
 // @Produce json
+// @Param If-Match header string false "ETag of the song version the change is based on"
 // @Param song body Song true "Data for deleting a song"
 // @Success 200 {string} string "The song was successfully deleted"
-// @Failure 400 {string} string "Invalid request parameters"
-// @Failure 404 {string} string "The song was not found"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song was not found"
+// @Failure 412 {object} problem.Problem "The song has changed since the version in If-Match"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /song/remove [delete]
-func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
+// @Deprecated
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.remove-song.New"
-		log = log.With(
+		log := logctx.From(r.Context(), log).With(
 			slog.String("op", op),
 		)
 
 		log.Debug("Received a request", slog.Any("request", r))
 
-		ct := r.Header.Get("Content-Type")
-		if ct != "" {
-			mediaType := strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
-			if mediaType != "application/json" {
-				msg := "Content-Type header is not application/json"
-				http.Error(w, msg, http.StatusUnsupportedMediaType)
-				log.Warn("Invalid Content-Type", slog.String("content-type", ct))
-				return
-			}
-		}
-		var song Song
-		err := json.NewDecoder(r.Body).Decode(&song)
+		versions, err := etag.IfMatch(r)
 		if err != nil {
-			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
-			log.Error("Failed to decode JSON", slog.Any("error", err))
+			problem.WriteError(w, r, err)
+			log.Warn("If-Match names no song version", slog.String("ifMatch", r.Header.Get("If-Match")))
 			return
 		}
 
-		if song.Group == "" || song.Song == "" {
-			http.Error(w, "Group and Song fields are required", http.StatusBadRequest)
-			log.Error("Missing required fields", slog.Any("song", song))
+		var song Song
+		if err := validate.DecodeJSON(w, r, &song); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
 			return
 		}
 
@@ -63,13 +63,18 @@ func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
 
 		log.Debug("Attempting to delete song", slog.String("group", song.Group), slog.String("song", song.Song))
 
-		err = storage.DeleteSong(song.Song, song.Group)
+		err = versions.Try(func(version int) error {
+			return store.DeleteSong(r.Context(), song.Song, song.Group, version, audit.Actor(r))
+		})
 		if err != nil {
-			if errors.Is(err, postgres.ErrSongNotFound) {
-				http.Error(w, "Song not found", http.StatusNotFound)
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
 				log.Warn("Song not found", slog.String("group", song.Group), slog.String("song", song.Song))
+			} else if errors.Is(err, storage.ErrVersionMismatch) {
+				problem.WriteError(w, r, err)
+				log.Warn("Song has changed meanwhile", slog.String("group", song.Group), slog.String("song", song.Song), slog.Any("versions", versions))
 			} else {
-				http.Error(w, "Failed to delete song", http.StatusInternalServerError)
+				problem.WriteError(w, r, err)
 				log.Error("Failed to delete song", slog.Any("error", err))
 			}
 			return
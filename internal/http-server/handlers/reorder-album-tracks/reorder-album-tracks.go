@@ -0,0 +1,79 @@
+package reorder_album_tracks
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ReorderTracksRequest struct {
+	// SongIDs lists every song of the album in the new track order.
+	SongIDs []uint `json:"songIds" validate:"required"`
+}
+
+// New creates a handler that reorders the tracks of an album
+// @Summary Reorder the tracks of an album
+// @Description Numbers the tracks in the given order. The list must contain every song of the album exactly once.
+// @Tags albums
+// @Accept json
+// @Param id path int true "Album ID"
+// @Param order body ReorderTracksRequest true "Song IDs in the new order"
+// @Success 204 "The tracks were reordered"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The album was not found"
+// @Failure 409 {object} problem.Problem "The list does not match the songs of the album"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums/{id}/tracks [put]
+func New(log *slog.Logger, store storage.AlbumStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.reorder-album-tracks.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid album id"))
+			log.Error("Invalid album id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		var request ReorderTracksRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		err = store.ReorderTracks(r.Context(), uint(id), request.SongIDs)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrAlbumNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeAlbumNotFound, "Album not found")
+				log.Warn("Album not found", slog.Uint64("id", id))
+			case errors.Is(err, storage.ErrInvalidTrackOrder):
+				problem.Error(w, r, http.StatusConflict, problem.CodeInvalidTrackOrder, "SongIDs must list every song of the album exactly once")
+				log.Warn("Invalid track order", slog.Any("request", request))
+			default:
+				problem.WriteError(w, r, err)
+				log.Error("Failed to reorder tracks", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Tracks reordered", slog.Uint64("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
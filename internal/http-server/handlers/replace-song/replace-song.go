@@ -0,0 +1,103 @@
+package replace_song
+
+import (
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ReplaceSongRequest struct {
+	Group       string `json:"group" validate:"required,max=255"`
+	Song        string `json:"song" validate:"required,max=255"`
+	ReleaseDate string `json:"releaseDate,omitempty" validate:"date=2006-01-02"`
+	Lyrics      string `json:"lyrics,omitempty"`
+	YoutubeLink string `json:"youtubeLink,omitempty" validate:"max=255,youtube"`
+}
+
+// New creates a handler that replaces all data of a song
+// @Summary Replace a song
+// @Description Replaces every field of the song with the given ID. Omitted optional fields are cleared.
+// @Tags songs
+// @Accept json
+// @Param id path int true "Song ID"
+// @Param If-Match header string false "ETag of the song version the change is based on"
+// @Param song body ReplaceSongRequest true "New song data, releaseDate in YYYY-MM-DD"
+// @Success 204 "The song was successfully replaced"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song was not found"
+// @Failure 409 {object} problem.Problem "The group already has a song with that name"
+// @Failure 412 {object} problem.Problem "The song has changed since the version in If-Match"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id} [put]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.replace-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		versions, err := etag.IfMatch(r)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Warn("If-Match names no song version", slog.String("ifMatch", r.Header.Get("If-Match")))
+			return
+		}
+
+		var request ReplaceSongRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		song := storage.Song{
+			GroupName:   request.Group,
+			SongName:    request.Song,
+			ReleaseDate: request.ReleaseDate,
+			Lyrics:      request.Lyrics,
+			YoutubeLink: request.YoutubeLink,
+		}
+		err = versions.Try(func(version int) error {
+			return store.ReplaceSong(r.Context(), uint(id), song, version, audit.Actor(r))
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrVersionMismatch) {
+				problem.WriteError(w, r, err)
+				log.Warn("Song has changed meanwhile", slog.Uint64("id", id), slog.Any("versions", versions))
+				return
+			}
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to replace song", slog.Any("error", err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		log.Info("Song replaced successfully", slog.Uint64("id", id))
+	}
+}
@@ -0,0 +1,62 @@
+package restore_song
+
+import (
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// New creates a handler that takes a song out of the trash
+// @Summary Restore a deleted song
+// @Description Takes the song with the given ID out of the trash. The song does not return to the albums and
+// @Description playlists it was taken off. It fails when its group got a song with the same name meanwhile.
+// @Tags songs
+// @Param id path int true "Song ID"
+// @Success 204 "The song was restored"
+// @Failure 400 {object} problem.Problem "Invalid song id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song is not in the trash"
+// @Failure 409 {object} problem.Problem "The group already has a song with this name"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/restore [post]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.restore-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		err = store.RestoreSong(r.Context(), uint(id), audit.Actor(r))
+		if err != nil {
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found in the trash")
+				log.Warn("Song not found in the trash", slog.Uint64("id", id))
+			} else {
+				problem.WriteError(w, r, err)
+				log.Error("Failed to restore song", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Song restored successfully", slog.Uint64("id", id))
+		w.Header().Set("Location", "/songs/"+strconv.FormatUint(id, 10))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,77 @@
+package revert_song
+
+import (
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type RevertSongRequest struct {
+	Revision int `json:"revision" validate:"required,min=1"`
+}
+
+// New creates a handler that reverts a song to a revision
+// @Summary Revert a song
+// @Description Overwrites the song with the given ID with the values it had after the given revision of
+// @Description GET /songs/{id}/history. The revert is recorded as a new revision. Deleted songs must be
+// @Description restored first.
+// @Tags songs
+// @Accept json
+// @Param id path int true "Song ID"
+// @Param revision body RevertSongRequest true "Revision to revert to"
+// @Success 204 "The song was reverted"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song or the revision was not found"
+// @Failure 409 {object} problem.Problem "The revision deleted the song, or the group already has a song with that name"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/revert [post]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.revert-song.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		var request RevertSongRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		err = store.RevertSong(r.Context(), uint(id), request.Revision, audit.Actor(r))
+		if err != nil {
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("id", id))
+			} else {
+				problem.WriteError(w, r, err)
+				log.Error("Failed to revert song", slog.Any("error", err))
+			}
+			return
+		}
+
+		log.Info("Song reverted successfully", slog.Uint64("id", id), slog.Int("revision", request.Revision))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,104 @@
+package search_songs
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type SearchResponse struct {
+	Items  []storage.SearchResult `json:"items"`
+	Total  int                    `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+// New creates a handler for the full-text search over song names and lyrics
+// @Summary Search songs by lyrics
+// @Description Finds songs whose name or lyrics contain the words of the query, most relevant first.
+// @Description The query supports "quoted phrases", OR and -excluded words.
+// @Description Every result lists the matching verses with the matched words wrapped in <mark></mark>.
+// @Tags songs
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Results per page (20 by default, at most 100)"
+// @Param offset query int false "Number of results to skip"
+// @Success 200 {object} SearchResponse "Search results"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Router /songs/search [get]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.search-songs.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			problem.Write(w, r, problem.Invalid("q", "q parameter is required"))
+			log.Error("Missing search query")
+			return
+		}
+
+		limit := defaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			var err error
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				problem.Write(w, r, problem.Invalid("limit", "Invalid limit parameter"))
+				log.Error("Invalid limit parameter", slog.String("limit", v))
+				return
+			}
+			limit = min(limit, maxLimit)
+		}
+		offset := 0
+		if v := r.URL.Query().Get("offset"); v != "" {
+			var err error
+			offset, err = strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				problem.Write(w, r, problem.Invalid("offset", "Invalid offset parameter"))
+				log.Error("Invalid offset parameter", slog.String("offset", v))
+				return
+			}
+		}
+		log.Info("Incoming search", slog.String("q", query), slog.Int("limit", limit), slog.Int("offset", offset))
+
+		results, total, err := store.SearchSongs(r.Context(), query, limit, offset)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Failed to search songs", slog.Any("error", err))
+			return
+		}
+		if results == nil {
+			results = []storage.SearchResult{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		// keep the <mark> highlights readable
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(SearchResponse{
+			Items:  results,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Search results sent to client", slog.Int("count", len(results)), slog.Int("total", total))
+	}
+}
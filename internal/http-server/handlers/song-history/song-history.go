@@ -0,0 +1,108 @@
+package song_history
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type HistoryResponse struct {
+	Items  []storage.SongRevision `json:"items"`
+	Total  int                    `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+// New creates a handler that lists the revisions of a song
+// @Summary Song history
+// @Description Returns the recorded changes of the song with the given ID, newest first: the values before and
+// @Description after each change, who made it and in which request. The action is created, updated, deleted,
+// @Description restored, reverted, purged or baseline for songs that existed before the history was recorded.
+// @Description The history of deleted and purged songs is kept.
+// @Tags songs
+// @Produce json
+// @Param id path int true "Song ID"
+// @Param limit query int false "Revisions per page (20 by default, at most 100)"
+// @Param offset query int false "Number of revisions to skip"
+// @Success 200 {object} HistoryResponse "Page of revisions"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song has no history"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/{id}/history [get]
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.song-history.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid song id"))
+			log.Error("Invalid song id", slog.String("id", r.PathValue("id")))
+			return
+		}
+		limit := defaultLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				problem.Write(w, r, problem.Invalid("limit", "Invalid limit parameter"))
+				log.Error("Invalid limit parameter", slog.String("limit", v))
+				return
+			}
+			limit = min(limit, maxLimit)
+		}
+		offset := 0
+		if v := r.URL.Query().Get("offset"); v != "" {
+			offset, err = strconv.Atoi(v)
+			if err != nil || offset < 0 {
+				problem.Write(w, r, problem.Invalid("offset", "Invalid offset parameter"))
+				log.Error("Invalid offset parameter", slog.String("offset", v))
+				return
+			}
+		}
+
+		revisions, total, err := store.SongHistory(r.Context(), uint(id), limit, offset)
+		if err != nil {
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Uint64("id", id))
+			} else {
+				problem.WriteError(w, r, err)
+				log.Error("Failed to select song history", slog.Any("error", err))
+			}
+			return
+		}
+		if revisions == nil {
+			revisions = []storage.SongRevision{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(HistoryResponse{
+			Items:  revisions,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		}); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+			return
+		}
+		log.Info("Song history sent to client", slog.Uint64("id", id), slog.Int("count", len(revisions)), slog.Int("total", total))
+	}
+}
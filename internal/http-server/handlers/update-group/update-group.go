@@ -0,0 +1,95 @@
+package update_group
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type UpdateGroupRequest struct {
+	Name        *string   `json:"name,omitempty" validate:"notblank,max=255"`
+	Country     *string   `json:"country,omitempty" validate:"max=255"`
+	FormedYear  *int      `json:"formedYear,omitempty" validate:"min=0,max=9999"`
+	Genres      *[]string `json:"genres,omitempty" validate:"max=50,dive,notblank,max=255"`
+	Description *string   `json:"description,omitempty"`
+}
+
+// New creates a handler that partially updates a group
+// @Summary Update a group
+// @Description Updates only the fields present in the body. Renaming a group renames it for all of its songs.
+// @Tags groups
+// @Accept json
+// @Param id path int true "Group ID"
+// @Param group body UpdateGroupRequest true "Fields to update"
+// @Success 204 "The group was successfully updated"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The group was not found"
+// @Failure 409 {object} problem.Problem "A group with this name already exists"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /groups/{id} [patch]
+func New(log *slog.Logger, store storage.GroupStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.update-group.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid group id"))
+			log.Error("Invalid group id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		var request UpdateGroupRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+
+		update := storage.GroupUpdate{
+			Name:        request.Name,
+			Country:     request.Country,
+			FormedYear:  request.FormedYear,
+			Genres:      request.Genres,
+			Description: request.Description,
+		}
+		if update.IsEmpty() {
+			problem.Error(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "No fields to update")
+			log.Error("Empty update", slog.Uint64("id", id))
+			return
+		}
+
+		err = store.UpdateGroup(r.Context(), uint(id), update)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrGroupNotFound):
+				problem.Error(w, r, http.StatusNotFound, problem.CodeGroupNotFound, "Group not found")
+				log.Warn("Group not found", slog.Uint64("id", id))
+			case errors.Is(err, storage.ErrGroupExists):
+				problem.Error(w, r, http.StatusConflict, problem.CodeGroupExists, "Group already exists")
+				log.Warn("Group already exists", slog.Any("request", request))
+			default:
+				problem.WriteError(w, r, err)
+				log.Error("Failed to update group", slog.Any("error", err))
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		log.Info("Group updated successfully", slog.Uint64("id", id))
+	}
+}
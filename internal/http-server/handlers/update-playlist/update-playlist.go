@@ -0,0 +1,115 @@
+package update_playlist
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type UpdatePlaylistRequest struct {
+	Name        *string `json:"name,omitempty" validate:"notblank,max=255"`
+	Description *string `json:"description,omitempty" validate:"max=2000"`
+	Public      *bool   `json:"public,omitempty"`
+}
+
+// New creates a handler that partially updates a playlist
+// @Summary Update a playlist
+// @Description Updates only the fields present in the request body. Only the owner can change a playlist.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Playlist ID"
+// @Param playlist body UpdatePlaylistRequest true "Fields to update"
+// @Success 200 {object} storage.Playlist "The updated playlist"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The playlist belongs to another user"
+// @Failure 404 {object} problem.Problem "The playlist was not found"
+// @Failure 415 {object} problem.Problem "Content-Type header is not application/json"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id} [patch]
+func New(log *slog.Logger, store storage.PlaylistStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.update-playlist.New"
+		log := logctx.From(r.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		log.Debug("Received a request", slog.Any("request", r))
+
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 0)
+		if err != nil || id == 0 {
+			problem.Write(w, r, problem.Invalid("id", "Invalid playlist id"))
+			log.Error("Invalid playlist id", slog.String("id", r.PathValue("id")))
+			return
+		}
+
+		var request UpdatePlaylistRequest
+		if err := validate.DecodeJSON(w, r, &request); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+		update := storage.PlaylistUpdate{
+			Name:        request.Name,
+			Description: request.Description,
+			Public:      request.Public,
+		}
+		if update.IsEmpty() {
+			problem.Error(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "No fields to update")
+			log.Error("Empty update request")
+			return
+		}
+
+		caller := auth.Subject(r.Context())
+		playlist, err := store.GetPlaylist(r.Context(), uint(id))
+		if err == nil && !playlist.Visible(caller) {
+			err = storage.ErrPlaylistNotFound
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to get playlist", slog.Any("error", err))
+			return
+		}
+		if playlist.Owner != caller {
+			problem.Error(w, r, http.StatusForbidden, problem.CodeForbidden, "Only the owner can change the playlist")
+			log.Warn("Playlist change by another user", slog.Uint64("id", id), slog.String("user", caller))
+			return
+		}
+
+		err = store.UpdatePlaylist(r.Context(), uint(id), update)
+		if err == nil {
+			playlist, err = store.GetPlaylist(r.Context(), uint(id))
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrPlaylistNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodePlaylistNotFound, "Playlist not found")
+				log.Warn("Playlist not found", slog.Uint64("id", id))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to update playlist", slog.Any("error", err))
+			return
+		}
+
+		log.Info("Playlist successfully updated", slog.Uint64("id", id))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(playlist); err != nil {
+			log.Error("Failed to encode JSON response", slog.Any("error", err))
+		}
+	}
+}
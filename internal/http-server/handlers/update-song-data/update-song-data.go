@@ -1,74 +1,151 @@
 package update_song_data
 
 import (
-	"effective-mobile/internal/storage/postgres"
-	"encoding/json"
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"errors"
 	"log/slog"
 	"net/http"
-
-	_ "github.com/lib/pq"
 )
 
+// UpdateSongRequest names the song by firstSong and firstGroup. Empty or left
+// out fields stay unchanged; only null clears release_date, lyrics and
+// youtube_link.
 type UpdateSongRequest struct {
-	FirstSong   string `json:"firstSong"`
-	FirstGroup  string `json:"firstGroup"`
-	Group       string `json:"group,omitempty"`
-	Song        string `json:"song,omitempty"`
-	ReleaseDate string `json:"release_date,omitempty"`
+	FirstSong   string                    `json:"firstSong" validate:"required,max=255"`
+	FirstGroup  string                    `json:"firstGroup" validate:"required,max=255"`
+	Group       string                    `json:"group,omitempty" validate:"max=255"`
+	Song        string                    `json:"song,omitempty" validate:"max=255"`
+	ReleaseDate validate.Optional[string] `json:"release_date,omitempty" validate:"date=02.01.2006" swaggertype:"string"`
+	Lyrics      validate.Optional[string] `json:"lyrics,omitempty" swaggertype:"string"`
+	YoutubeLink validate.Optional[string] `json:"youtube_link,omitempty" validate:"max=255,youtube" swaggertype:"string"`
+	// Verses edits the lyrics verse by verse like PATCH /songs/{id}.
+	Verses []storage.VerseEdit `json:"verses,omitempty" validate:"dive"`
+}
+
+func (u UpdateSongRequest) update() (storage.SongUpdate, error) {
+	var update storage.SongUpdate
+	if u.Group != "" {
+		update.GroupName = &u.Group
+	}
+	if u.Song != "" {
+		update.SongName = &u.Song
+	}
+	update.ReleaseDate = nonEmpty(u.ReleaseDate)
+	if update.ReleaseDate != nil && *update.ReleaseDate != "" {
+		date, err := storage.FormatReleaseDate(*update.ReleaseDate)
+		if err != nil {
+			return update, err
+		}
+		update.ReleaseDate = &date
+	}
+	update.Lyrics = nonEmpty(u.Lyrics)
+	update.YoutubeLink = nonEmpty(u.YoutubeLink)
+	update.Verses = u.Verses
+	return update, nil
+}
+
+// nonEmpty returns nil for a field that was left out or empty, which leaves
+// it unchanged, and a pointer to "" for null, which clears it.
+func nonEmpty(field validate.Optional[string]) *string {
+	if field.Null {
+		cleared := ""
+		return &cleared
+	}
+	if field.Value == "" {
+		return nil
+	}
+	return &field.Value
 }
 
 // New creates a handler for updating song data
 // @Summary Update the song data
-// @Description Updates the song data in the repository based on the original song data and new data.
+// @Description Deprecated, use PATCH /songs/{id}. Updates the song data in the repository based on the original song data and new data.
+// @Description Empty or left out fields stay unchanged; only null clears release_date, lyrics or youtube_link. verses edits single verses like PATCH /songs/{id}.
 // @Tags songs
 // @Accept json
 // @Produce json
+// @Param If-Match header string false "ETag of the song version the change is based on"
 // @Param updateRequest body UpdateSongRequest true "Data for updating the song"
 // @Success 204 {string} string "The song data has been successfully updated"
-// @Failure 400 {string} string "Invalid request parameters"
-// @Failure 500 {string} string "Server error"
+// @Failure 400 {object} problem.Problem "Invalid request parameters"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "The editor role is required"
+// @Failure 404 {object} problem.Problem "The song was not found"
+// @Failure 409 {object} problem.Problem "The group already has a song with that name"
+// @Failure 412 {object} problem.Problem "The song has changed since the version in If-Match"
+// @Failure 500 {object} problem.Problem "Server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /song/update [patch]
-func New(log *slog.Logger, storage *postgres.Storage) http.HandlerFunc {
+// @Deprecated
+func New(log *slog.Logger, store storage.SongStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.update-song.New"
-		log = log.With(
+		log := logctx.From(r.Context(), log).With(
 			slog.String("op", op),
 		)
 
 		log.Debug("Received a request", slog.Any("request", r))
 
-		ct := r.Header.Get("Content-Type")
-		if ct != "application/json" {
-			msg := "Content-Type header is not application/json"
-			http.Error(w, msg, http.StatusUnsupportedMediaType)
-			log.Info("Unsupported media type", slog.String("content-type", ct))
+		versions, err := etag.IfMatch(r)
+		if err != nil {
+			problem.WriteError(w, r, err)
+			log.Warn("If-Match names no song version", slog.String("ifMatch", r.Header.Get("If-Match")))
 			return
 		}
 
 		var updateRequest UpdateSongRequest
-		err := json.NewDecoder(r.Body).Decode(&updateRequest)
+		if err := validate.DecodeJSON(w, r, &updateRequest); err != nil {
+			problem.WriteError(w, r, err)
+			log.Error("Invalid request body", slog.Any("error", err))
+			return
+		}
+		update, err := updateRequest.update()
 		if err != nil {
-			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
-			log.Error("Failed to decode JSON", slog.Any("error", err))
+			problem.Write(w, r, problem.Invalid("release_date", "Invalid release date"))
+			log.Error("Invalid release date", slog.Any("error", err))
+			return
+		}
+		if update.Lyrics != nil && len(update.Verses) > 0 {
+			problem.Write(w, r, problem.Invalid("verses", "Cannot be combined with lyrics"))
+			log.Error("Both lyrics and verse edits", slog.Any("updateRequest", updateRequest))
 			return
 		}
-		if updateRequest.FirstSong == "" || updateRequest.FirstGroup == "" {
-			http.Error(w, "FirstSong and FirstGroup fields are required", http.StatusBadRequest)
-			log.Error("Missing required fields", slog.Any("song", updateRequest))
+		if update.IsEmpty() {
+			problem.Error(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "No fields to update")
+			log.Error("Empty update", slog.Any("updateRequest", updateRequest))
 			return
 		}
 		log.Debug("Attempting to update song data", slog.Any("updateRequest", updateRequest))
 
-		err = storage.UpdateSong(
-			updateRequest.FirstSong,
-			updateRequest.FirstGroup,
-			updateRequest.Song,
-			updateRequest.Group,
-			updateRequest.ReleaseDate,
-		)
+		err = versions.Try(func(version int) error {
+			return store.UpdateSong(
+				r.Context(),
+				updateRequest.FirstSong,
+				updateRequest.FirstGroup,
+				update,
+				version,
+				audit.Actor(r),
+			)
+		})
 		if err != nil {
-			http.Error(w, "Failed to update song", http.StatusInternalServerError)
-			log.Error("Failed to update song", slog.Any("statusCode", err))
+			if errors.Is(err, storage.ErrVersionMismatch) {
+				problem.WriteError(w, r, err)
+				log.Warn("Song has changed meanwhile", slog.Any("updateRequest", updateRequest), slog.Any("versions", versions))
+				return
+			}
+			if errors.Is(err, storage.ErrSongNotFound) {
+				problem.Error(w, r, http.StatusNotFound, problem.CodeSongNotFound, "Song not found")
+				log.Warn("Song not found", slog.Any("updateRequest", updateRequest))
+				return
+			}
+			problem.WriteError(w, r, err)
+			log.Error("Failed to update song", slog.Any("error", err))
 			return
 		}
 
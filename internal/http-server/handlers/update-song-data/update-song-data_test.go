@@ -0,0 +1,104 @@
+package update_song_data_test
+
+import (
+	"context"
+	updateSongData "effective-mobile/internal/http-server/handlers/update-song-data"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/memory"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEmptyAndNullFields(t *testing.T) {
+	original := storage.Song{
+		GroupName:   "Muse",
+		SongName:    "Uprising",
+		ReleaseDate: "2009-09-07",
+		Lyrics:      "one\n\ntwo",
+		YoutubeLink: "https://www.youtube.com/watch?v=w8KQmps-Sog",
+	}
+	tests := []struct {
+		name   string
+		fields string
+		status int
+		want   storage.Song
+	}{
+		{
+			name:   "empty fields stay unchanged",
+			fields: `"song":"Resistance","release_date":"","lyrics":"","youtube_link":""`,
+			status: http.StatusNoContent,
+			want:   storage.Song{SongName: "Resistance", ReleaseDate: original.ReleaseDate, Lyrics: original.Lyrics, YoutubeLink: original.YoutubeLink},
+		},
+		{
+			name:   "null clears",
+			fields: `"release_date":null,"lyrics":null,"youtube_link":null`,
+			status: http.StatusNoContent,
+			want:   storage.Song{SongName: original.SongName},
+		},
+		{
+			name:   "null clears only its field",
+			fields: `"lyrics":null`,
+			status: http.StatusNoContent,
+			want:   storage.Song{SongName: original.SongName, ReleaseDate: original.ReleaseDate, YoutubeLink: original.YoutubeLink},
+		},
+		{
+			name:   "values replace",
+			fields: `"release_date":"01.02.2010","lyrics":"three"`,
+			status: http.StatusNoContent,
+			want:   storage.Song{SongName: original.SongName, ReleaseDate: "2010-02-01", Lyrics: "three", YoutubeLink: original.YoutubeLink},
+		},
+		{
+			name:   "empty lyrics do not block verse edits",
+			fields: `"lyrics":"","verses":[{"op":"delete","verse":1}]`,
+			status: http.StatusNoContent,
+			want:   storage.Song{SongName: original.SongName, ReleaseDate: original.ReleaseDate, Lyrics: "two", YoutubeLink: original.YoutubeLink},
+		},
+		{
+			name:   "null lyrics with verse edits",
+			fields: `"lyrics":null,"verses":[{"op":"delete","verse":1}]`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "only empty fields",
+			fields: `"song":"","lyrics":""`,
+			status: http.StatusBadRequest,
+		},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.New()
+			id, err := store.InsertSong(context.Background(), original, storage.Actor{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body := `{"firstSong":"Uprising","firstGroup":"Muse",` + tt.fields + `}`
+			r := httptest.NewRequest(http.MethodPatch, "/song/update", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			updateSongData.New(log, store).ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusNoContent {
+				return
+			}
+
+			got, err := store.GetSong(context.Background(), id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.SongName != tt.want.SongName || got.ReleaseDate != tt.want.ReleaseDate ||
+				got.Lyrics != tt.want.Lyrics || got.YoutubeLink != tt.want.YoutubeLink {
+				t.Errorf("song = %q, %q, %q, %q; want %q, %q, %q, %q",
+					got.SongName, got.ReleaseDate, got.Lyrics, got.YoutubeLink,
+					tt.want.SongName, tt.want.ReleaseDate, tt.want.Lyrics, tt.want.YoutubeLink)
+			}
+		})
+	}
+}
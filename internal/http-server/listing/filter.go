@@ -0,0 +1,133 @@
+package listing
+
+import (
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/storage"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var matchModes = map[string]storage.MatchMode{
+	"":         storage.MatchExact,
+	"exact":    storage.MatchExact,
+	"prefix":   storage.MatchPrefix,
+	"contains": storage.MatchContains,
+}
+
+// ParseFilter reads the library filters from the query. album, group, song,
+// releaseDate and lyrics may be repeated. Every invalid parameter is reported
+// in the returned validate.Errors.
+func ParseFilter(q url.Values) (storage.SongFilter, error) {
+	filter := storage.SongFilter{
+		Groups:       nonEmpty(q["group"]),
+		Songs:        nonEmpty(q["song"]),
+		ReleaseDates: nonEmpty(q["releaseDate"]),
+		ReleasedFrom: q.Get("releasedFrom"),
+		ReleasedTo:   q.Get("releasedTo"),
+	}
+	var errs validate.Errors
+
+	match, ok := matchModes[strings.ToLower(q.Get("match"))]
+	if !ok {
+		errs = append(errs, invalid("match", "Invalid match parameter, expected exact, prefix or contains"))
+	}
+	filter.NameMatch = match
+
+	for _, date := range filter.ReleaseDates {
+		if storage.ValidateReleaseDate(date) != nil {
+			errs = append(errs, invalidDate("releaseDate", date))
+		}
+	}
+	fromOK := filter.ReleasedFrom == "" || storage.ValidateReleaseDate(filter.ReleasedFrom) == nil
+	if !fromOK {
+		errs = append(errs, invalidDate("releasedFrom", filter.ReleasedFrom))
+	}
+	toOK := filter.ReleasedTo == "" || storage.ValidateReleaseDate(filter.ReleasedTo) == nil
+	if !toOK {
+		errs = append(errs, invalidDate("releasedTo", filter.ReleasedTo))
+	}
+	if fromOK && toOK && filter.ReleasedFrom != "" && filter.ReleasedTo != "" && filter.ReleasedFrom > filter.ReleasedTo {
+		errs = append(errs, validate.FieldError{Field: "releasedFrom", Code: validate.CodeOutOfRange, Message: "releasedFrom is after releasedTo"})
+	}
+
+	for _, v := range q["album"] {
+		id, err := strconv.ParseUint(v, 10, 0)
+		if err != nil || id == 0 {
+			errs = append(errs, invalid("album", "Invalid album parameter, expected an album id"))
+			continue
+		}
+		filter.AlbumIDs = append(filter.AlbumIDs, uint(id))
+	}
+
+	var err error
+	if filter.HasLyrics, err = parseBool(q, "hasLyrics"); err != nil {
+		errs = append(errs, err.(validate.FieldError))
+	}
+	if filter.HasLink, err = parseBool(q, "hasLink"); err != nil {
+		errs = append(errs, err.(validate.FieldError))
+	}
+
+	for _, text := range q["lyrics"] {
+		filter.LyricsWords = append(filter.LyricsWords, strings.Fields(text)...)
+	}
+	if len(errs) > 0 {
+		return filter, errs
+	}
+	return filter, nil
+}
+
+func parseBool(q url.Values, key string) (*bool, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, invalid(key, fmt.Sprintf("Invalid %s parameter, expected true or false", key))
+	}
+	return &b, nil
+}
+
+func invalid(field, message string) validate.FieldError {
+	return validate.FieldError{Field: field, Code: validate.CodeInvalid, Message: message}
+}
+
+func invalidDate(field, date string) validate.FieldError {
+	return validate.FieldError{Field: field, Code: validate.CodeInvalidDate, Message: fmt.Sprintf("Invalid date %q, expected YYYY-MM-DD", date)}
+}
+
+func nonEmpty(values []string) []string {
+	var res []string
+	for _, v := range values {
+		if v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Parse reads the filters and the pagination of a song listing and reports
+// the violations of both at once.
+func Parse(q url.Values) (storage.SongFilter, Pagination, error) {
+	filter, filterErr := ParseFilter(q)
+	p, pageErr := ParsePagination(q)
+
+	var errs validate.Errors
+	for _, err := range []error{filterErr, pageErr} {
+		switch err := err.(type) {
+		case nil:
+		case validate.Errors:
+			errs = append(errs, err...)
+		case validate.FieldError:
+			errs = append(errs, err)
+		default:
+			return filter, p, err
+		}
+	}
+	if len(errs) > 0 {
+		return filter, p, errs
+	}
+	return filter, p, nil
+}
@@ -0,0 +1,209 @@
+package listing
+
+import (
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/storage"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// SongsResponse is a page of a song listing.
+type SongsResponse struct {
+	Items []storage.Song `json:"items"`
+	Total int            `json:"total"`
+	Limit int            `json:"limit"`
+	Links Links          `json:"links"`
+}
+
+type Links struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+type paginationMode int
+
+const (
+	modeOffset paginationMode = iota
+	modePage
+	modeCursor
+)
+
+type Pagination struct {
+	mode paginationMode
+	Page storage.Page
+}
+
+// ParsePagination reads either page/pageSize, limit/offset or cursor/limit
+// together with sort=field:asc|desc[,field:asc|desc...] from the query.
+// Every invalid parameter is reported in the returned validate.Errors.
+func ParsePagination(q url.Values) (Pagination, error) {
+	var p Pagination
+	var errs validate.Errors
+
+	var err error
+	if p.Page.Sort, err = parseSort(q.Get("sort")); err != nil {
+		errs = append(errs, invalid("sort", err.Error()))
+	}
+
+	size := defaultPageSize
+	for _, key := range []string{"pageSize", "limit"} {
+		if v := q.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				errs = append(errs, invalid(key, fmt.Sprintf("Invalid %s parameter, expected a positive number", key)))
+				continue
+			}
+			size = n
+		}
+	}
+	p.Page.Limit = min(size, maxPageSize)
+
+	switch {
+	case q.Get("cursor") != "":
+		if q.Get("page") != "" || q.Get("offset") != "" {
+			errs = append(errs, invalid("cursor", "cursor cannot be combined with page or offset"))
+			break
+		}
+		p.mode = modeCursor
+		after, id, err := decodeCursor(q.Get("cursor"))
+		if err != nil {
+			errs = append(errs, invalid("cursor", "Invalid cursor"))
+			break
+		}
+		if after {
+			p.Page.AfterID = id
+		} else {
+			p.Page.BeforeID = id
+		}
+	case q.Get("page") != "":
+		if q.Get("offset") != "" {
+			errs = append(errs, invalid("page", "page cannot be combined with offset"))
+			break
+		}
+		p.mode = modePage
+		page, err := strconv.Atoi(q.Get("page"))
+		if err != nil || page < 1 {
+			errs = append(errs, invalid("page", "Invalid page parameter, expected a positive number"))
+			break
+		}
+		// the offset of the page must fit in an int
+		if page-1 > math.MaxInt/p.Page.Limit {
+			errs = append(errs, invalid("page", "Invalid page parameter, the page is out of range"))
+			break
+		}
+		p.Page.Offset = (page - 1) * p.Page.Limit
+	case q.Get("offset") != "":
+		offset, err := strconv.Atoi(q.Get("offset"))
+		if err != nil || offset < 0 {
+			errs = append(errs, invalid("offset", "Invalid offset parameter, expected a non-negative number"))
+			break
+		}
+		p.Page.Offset = offset
+	}
+
+	if len(errs) > 0 {
+		return p, errs
+	}
+	if err := storage.ValidatePage(p.Page); err != nil {
+		return p, invalid("sort", err.Error())
+	}
+	return p, nil
+}
+
+func parseSort(raw string) ([]storage.Sort, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var sorts []storage.Sort
+	for _, part := range strings.Split(raw, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(part), ":")
+		sort := storage.Sort{Field: field}
+		switch strings.ToLower(dir) {
+		case "", "asc":
+		case "desc":
+			sort.Desc = true
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q", dir)
+		}
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// Links returns the next and prev URLs for the page that was served. Empty
+// strings mean there is no such page.
+func (p Pagination) Links(u *url.URL, songs []storage.Song, total int) (next string, prev string) {
+	with := func(set map[string]string) string {
+		q := u.Query()
+		for _, key := range []string{"page", "offset", "cursor"} {
+			q.Del(key)
+		}
+		for k, v := range set {
+			q.Set(k, v)
+		}
+		return u.Path + "?" + q.Encode()
+	}
+	limit := p.Page.Limit
+
+	switch p.mode {
+	case modeCursor:
+		if len(songs) == 0 {
+			return "", ""
+		}
+		first, last := songs[0].ID, songs[len(songs)-1].ID
+		// a full page may be followed by more songs; a page reached by
+		// walking backwards always has the one we came from after it
+		if len(songs) == limit || p.Page.BeforeID != 0 {
+			next = with(map[string]string{"cursor": encodeCursor(true, last)})
+		}
+		if p.Page.AfterID != 0 || (p.Page.BeforeID != 0 && len(songs) == limit) {
+			prev = with(map[string]string{"cursor": encodeCursor(false, first)})
+		}
+	case modePage:
+		page := p.Page.Offset/limit + 1
+		if p.Page.Offset+len(songs) < total {
+			next = with(map[string]string{"page": strconv.Itoa(page + 1)})
+		}
+		if page > 1 {
+			prev = with(map[string]string{"page": strconv.Itoa(page - 1)})
+		}
+	default:
+		if p.Page.Offset+len(songs) < total {
+			next = with(map[string]string{"offset": strconv.Itoa(p.Page.Offset + limit)})
+		}
+		if p.Page.Offset > 0 {
+			prev = with(map[string]string{"offset": strconv.Itoa(max(p.Page.Offset-limit, 0))})
+		}
+	}
+	return next, prev
+}
+
+func encodeCursor(after bool, id uint) string {
+	dir := "b"
+	if after {
+		dir = "a"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(dir + ":" + strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCursor(cursor string) (after bool, id uint, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid cursor")
+	}
+	dir, idStr, ok := strings.Cut(string(raw), ":")
+	n, err := strconv.ParseUint(idStr, 10, 0)
+	if !ok || err != nil || n == 0 || (dir != "a" && dir != "b") {
+		return false, 0, fmt.Errorf("invalid cursor")
+	}
+	return dir == "a", uint(n), nil
+}
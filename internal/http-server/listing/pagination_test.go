@@ -0,0 +1,44 @@
+package listing
+
+import (
+	"effective-mobile/internal/http-server/validate"
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestParsePaginationPage(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantOffset int
+		wantField  string
+	}{
+		{"page=3&pageSize=10", 20, ""},
+		{"page=0", 0, "page"},
+		{"page=" + strconv.Itoa(math.MaxInt), 0, "page"},
+		{"page=" + strconv.Itoa(math.MaxInt) + "&pageSize=1", math.MaxInt - 1, ""},
+		{"page=" + strconv.Itoa(math.MaxInt/20+2) + "&pageSize=20", 0, "page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := ParsePagination(q)
+			var errs validate.Errors
+			switch {
+			case tt.wantField == "" && err != nil:
+				t.Fatalf("ParsePagination: %v", err)
+			case tt.wantField != "" && !errors.As(err, &errs):
+				t.Fatalf("ParsePagination: err = %v, want errors of %q", err, tt.wantField)
+			case tt.wantField != "" && (len(errs) != 1 || errs[0].Field != tt.wantField):
+				t.Errorf("ParsePagination errors = %v, want one of %q", errs, tt.wantField)
+			case tt.wantField == "" && p.Page.Offset != tt.wantOffset:
+				t.Errorf("ParsePagination offset = %d, want %d", p.Page.Offset, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package problem writes RFC 7807 problem details responses.
+package problem
+
+import (
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/services/details"
+	"effective-mobile/internal/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// Stable machine-readable error codes. Clients should branch on these rather
+// than on the English detail text.
+const (
+	CodeValidationFailed     = "validation_failed"
+	CodeInvalidJSON          = "invalid_json"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeInvalidImportFile    = "invalid_import_file"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeSongNotFound         = "song_not_found"
+	CodeSongExists           = "song_exists"
+	CodeVersionMismatch      = "version_mismatch"
+	CodeRevisionNotFound     = "revision_not_found"
+	CodeRevisionEmpty        = "revision_empty"
+	CodeGroupNotFound        = "group_not_found"
+	CodeGroupExists          = "group_exists"
+	CodeGroupInUse           = "group_in_use"
+	CodeAlbumNotFound        = "album_not_found"
+	CodeTrackNotFound        = "track_not_found"
+	CodeTrackExists          = "track_exists"
+	CodeInvalidTrackOrder    = "invalid_track_order"
+	CodePlaylistNotFound     = "playlist_not_found"
+	CodeEntryNotFound        = "entry_not_found"
+	CodeEntryExists          = "entry_exists"
+	CodeIdempotencyKeyInUse  = "idempotency_key_in_use"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeUpstreamRejected     = "upstream_rejected"
+	CodeUpstreamUnavailable  = "upstream_unavailable"
+	CodeStorageTimeout       = "storage_timeout"
+	CodeStorageUnavailable   = "storage_unavailable"
+	CodeRequestCanceled      = "request_canceled"
+	CodeInternal             = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details object extended with a code and
+// field-level errors.
+type Problem struct {
+	Type     string          `json:"type"`
+	Title    string          `json:"title"`
+	Status   int             `json:"status"`
+	Detail   string          `json:"detail,omitempty"`
+	Instance string          `json:"instance,omitempty"`
+	Code     string          `json:"code"`
+	Errors   validate.Errors `json:"errors,omitempty"`
+}
+
+func New(status int, code string, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Validation reports invalid request fields.
+func Validation(errs ...validate.FieldError) Problem {
+	p := New(http.StatusBadRequest, CodeValidationFailed, "The request has invalid fields")
+	p.Errors = errs
+	return p
+}
+
+// Invalid reports a single invalid request field.
+func Invalid(field string, message string) Problem {
+	return Validation(validate.FieldError{Field: field, Code: validate.CodeInvalid, Message: message})
+}
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// mappings is checked in order, so wrapped errors go before the errors they wrap.
+var mappings = []mapping{
+	{validate.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType},
+	{validate.ErrBodyTooLarge, http.StatusRequestEntityTooLarge, CodePayloadTooLarge},
+	{validate.ErrInvalidJSON, http.StatusBadRequest, CodeInvalidJSON},
+	{storage.ErrSongNotFound, http.StatusNotFound, CodeSongNotFound},
+	{storage.ErrSongExists, http.StatusConflict, CodeSongExists},
+	{storage.ErrVersionMismatch, http.StatusPreconditionFailed, CodeVersionMismatch},
+	{storage.ErrRevisionNotFound, http.StatusNotFound, CodeRevisionNotFound},
+	{storage.ErrRevisionEmpty, http.StatusConflict, CodeRevisionEmpty},
+	{storage.ErrGroupNotFound, http.StatusNotFound, CodeGroupNotFound},
+	{storage.ErrGroupExists, http.StatusConflict, CodeGroupExists},
+	{storage.ErrGroupInUse, http.StatusConflict, CodeGroupInUse},
+	{storage.ErrAlbumNotFound, http.StatusNotFound, CodeAlbumNotFound},
+	{storage.ErrTrackNotFound, http.StatusNotFound, CodeTrackNotFound},
+	{storage.ErrTrackExists, http.StatusConflict, CodeTrackExists},
+	{storage.ErrInvalidTrackOrder, http.StatusConflict, CodeInvalidTrackOrder},
+	{storage.ErrPlaylistNotFound, http.StatusNotFound, CodePlaylistNotFound},
+	{storage.ErrEntryNotFound, http.StatusNotFound, CodeEntryNotFound},
+	{storage.ErrEntryExists, http.StatusConflict, CodeEntryExists},
+	{storage.ErrInvalidPage, http.StatusBadRequest, CodeValidationFailed},
+	{details.ErrBadRequest, http.StatusBadRequest, CodeUpstreamRejected},
+	{details.ErrUnavailable, http.StatusBadGateway, CodeUpstreamUnavailable},
+	// The storage failure kinds come after the errors of particular
+	// entities, which they may wrap.
+	{storage.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{storage.ErrExists, http.StatusConflict, CodeConflict},
+	{storage.ErrTimeout, http.StatusGatewayTimeout, CodeStorageTimeout},
+	{storage.ErrConnectionLost, http.StatusServiceUnavailable, CodeStorageUnavailable},
+	{storage.ErrCanceled, http.StatusServiceUnavailable, CodeRequestCanceled},
+}
+
+// FromError maps an error from the storage or the details provider to a
+// problem. Unknown errors become a 500 without leaking their text.
+func FromError(err error) Problem {
+	var fieldErrs validate.Errors
+	if errors.As(err, &fieldErrs) {
+		return Validation(fieldErrs...)
+	}
+	var fieldErr validate.FieldError
+	if errors.As(err, &fieldErr) {
+		return Validation(fieldErr)
+	}
+	var verseErr *storage.VerseError
+	if errors.As(err, &verseErr) {
+		return Invalid(fmt.Sprintf("verses[%d]", verseErr.Edit), verseErr.Message)
+	}
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return New(m.status, m.code, capitalize(m.err.Error()))
+		}
+	}
+	return New(http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// Write sends the problem as the response.
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", ContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Error is the problem counterpart of http.Error.
+func Error(w http.ResponseWriter, r *http.Request, status int, code string, detail string) {
+	Write(w, r, New(status, code, detail))
+}
+
+// WriteError sends the problem FromError picks for err.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	Write(w, r, FromError(err))
+}
+
+func capitalize(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
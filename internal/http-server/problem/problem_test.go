@@ -0,0 +1,109 @@
+package problem_test
+
+import (
+	"effective-mobile/internal/http-server/etag"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/services/details"
+	"effective-mobile/internal/storage"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// secret stands for driver or upstream text that must not reach clients.
+const secret = "pq: password authentication failed for user app"
+
+func storageErr(kind error, err error) error {
+	return &storage.Error{Op: "storage.postgres.Test", Kind: kind, Err: err}
+}
+
+func TestFromError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPut, "/songs/1", nil)
+	r.Header.Set("If-Match", `W/"1"`)
+	_, ifMatchErr := etag.IfMatch(r)
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		// field is the field of the first error of a validation problem.
+		field string
+	}{
+		{name: "field errors", err: validate.Errors{{Field: "song", Code: validate.CodeRequired, Message: "is required"}}, status: http.StatusBadRequest, code: problem.CodeValidationFailed, field: "song"},
+		{name: "wrapped field errors", err: fmt.Errorf("decode: %w", validate.Errors{{Field: "group", Code: validate.CodeTooLong}}), status: http.StatusBadRequest, code: problem.CodeValidationFailed, field: "group"},
+		{name: "field error", err: validate.FieldError{Field: "releaseDate", Code: validate.CodeInvalidDate}, status: http.StatusBadRequest, code: problem.CodeValidationFailed, field: "releaseDate"},
+		{name: "verse error", err: &storage.VerseError{Edit: 2, Err: storage.ErrVerseOutOfRange, Message: "there is no verse 9"}, status: http.StatusBadRequest, code: problem.CodeValidationFailed, field: "verses[2]"},
+		{name: "unsupported media type", err: validate.ErrUnsupportedMediaType, status: http.StatusUnsupportedMediaType, code: problem.CodeUnsupportedMediaType},
+		{name: "body too large", err: validate.ErrBodyTooLarge, status: http.StatusRequestEntityTooLarge, code: problem.CodePayloadTooLarge},
+		{name: "invalid json", err: validate.ErrInvalidJSON, status: http.StatusBadRequest, code: problem.CodeInvalidJSON},
+		{name: "song not found", err: storage.ErrSongNotFound, status: http.StatusNotFound, code: problem.CodeSongNotFound},
+		{name: "song exists", err: storage.ErrSongExists, status: http.StatusConflict, code: problem.CodeSongExists},
+		{name: "version mismatch", err: storage.ErrVersionMismatch, status: http.StatusPreconditionFailed, code: problem.CodeVersionMismatch},
+		{name: "weak If-Match", err: ifMatchErr, status: http.StatusPreconditionFailed, code: problem.CodeVersionMismatch},
+		{name: "revision not found", err: storage.ErrRevisionNotFound, status: http.StatusNotFound, code: problem.CodeRevisionNotFound},
+		{name: "revision empty", err: storage.ErrRevisionEmpty, status: http.StatusConflict, code: problem.CodeRevisionEmpty},
+		{name: "group not found", err: storage.ErrGroupNotFound, status: http.StatusNotFound, code: problem.CodeGroupNotFound},
+		{name: "group exists", err: storage.ErrGroupExists, status: http.StatusConflict, code: problem.CodeGroupExists},
+		{name: "group in use", err: storage.ErrGroupInUse, status: http.StatusConflict, code: problem.CodeGroupInUse},
+		{name: "album not found", err: storage.ErrAlbumNotFound, status: http.StatusNotFound, code: problem.CodeAlbumNotFound},
+		{name: "track not found", err: storage.ErrTrackNotFound, status: http.StatusNotFound, code: problem.CodeTrackNotFound},
+		{name: "track exists", err: storage.ErrTrackExists, status: http.StatusConflict, code: problem.CodeTrackExists},
+		{name: "invalid track order", err: storage.ErrInvalidTrackOrder, status: http.StatusConflict, code: problem.CodeInvalidTrackOrder},
+		{name: "playlist not found", err: storage.ErrPlaylistNotFound, status: http.StatusNotFound, code: problem.CodePlaylistNotFound},
+		{name: "entry not found", err: storage.ErrEntryNotFound, status: http.StatusNotFound, code: problem.CodeEntryNotFound},
+		{name: "entry exists", err: storage.ErrEntryExists, status: http.StatusConflict, code: problem.CodeEntryExists},
+		{name: "invalid page", err: fmt.Errorf("%w: negative limit", storage.ErrInvalidPage), status: http.StatusBadRequest, code: problem.CodeValidationFailed},
+		{name: "upstream rejected", err: fmt.Errorf("%w: 400", details.ErrBadRequest), status: http.StatusBadRequest, code: problem.CodeUpstreamRejected},
+		{name: "upstream unavailable", err: fmt.Errorf("%w: %s", details.ErrUnavailable, secret), status: http.StatusBadGateway, code: problem.CodeUpstreamUnavailable},
+		{name: "not found kind", err: storageErr(storage.ErrNotFound, errors.New(secret)), status: http.StatusNotFound, code: problem.CodeNotFound},
+		{name: "exists kind", err: storageErr(storage.ErrExists, errors.New(secret)), status: http.StatusConflict, code: problem.CodeConflict},
+		{name: "timeout kind", err: storageErr(storage.ErrTimeout, errors.New(secret)), status: http.StatusGatewayTimeout, code: problem.CodeStorageTimeout},
+		{name: "connection lost kind", err: storageErr(storage.ErrConnectionLost, errors.New(secret)), status: http.StatusServiceUnavailable, code: problem.CodeStorageUnavailable},
+		{name: "canceled kind", err: storageErr(storage.ErrCanceled, errors.New(secret)), status: http.StatusServiceUnavailable, code: problem.CodeRequestCanceled},
+		{name: "entity error of a kind", err: storageErr(storage.ErrNotFound, storage.ErrSongNotFound), status: http.StatusNotFound, code: problem.CodeSongNotFound},
+		{name: "storage error without a kind", err: storageErr(nil, errors.New(secret)), status: http.StatusInternalServerError, code: problem.CodeInternal},
+		{name: "unknown", err: errors.New(secret), status: http.StatusInternalServerError, code: problem.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problem.FromError(tt.err)
+			if p.Status != tt.status || p.Code != tt.code {
+				t.Errorf("FromError = %d %s, want %d %s", p.Status, p.Code, tt.status, tt.code)
+			}
+			if p.Title != http.StatusText(tt.status) {
+				t.Errorf("title = %q, want %q", p.Title, http.StatusText(tt.status))
+			}
+			if strings.Contains(p.Detail, "pq:") || strings.Contains(p.Detail, "password") {
+				t.Errorf("detail %q leaks the error text", p.Detail)
+			}
+			if tt.field != "" && (len(p.Errors) == 0 || p.Errors[0].Field != tt.field) {
+				t.Errorf("errors = %+v, want the first for %s", p.Errors, tt.field)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Length", "12")
+	problem.WriteError(w, httptest.NewRequest(http.MethodGet, "/songs/7", nil), errors.New(secret))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if got := w.Header().Get("Content-Type"); got != problem.ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, problem.ContentType)
+	}
+	if got := w.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length = %q, want none", got)
+	}
+	body := w.Body.String()
+	if strings.Contains(body, secret) || !strings.Contains(body, `"instance":"/songs/7"`) {
+		t.Errorf("body = %s, want the instance and no internal detail", body)
+	}
+}
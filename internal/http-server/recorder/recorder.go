@@ -0,0 +1,83 @@
+// Package recorder wraps a ResponseWriter to remember what was sent.
+package recorder
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Recorder remembers the status code and the body size of a response and,
+// once Capture is called, the headers and the body themselves.
+type Recorder struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+
+	capture bool
+	header  http.Header
+	body    bytes.Buffer
+}
+
+func New(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Capture makes the recorder keep a copy of the headers and the body it
+// sends. It must be called before the response is written.
+func (rec *Recorder) Capture() {
+	rec.capture = true
+}
+
+func (rec *Recorder) WriteHeader(status int) {
+	if !rec.wroteHeader && status >= http.StatusOK {
+		rec.status = status
+		rec.sendHeader()
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *Recorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.sendHeader()
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.size += int64(n)
+	if rec.capture {
+		rec.body.Write(b[:n])
+	}
+	return n, err
+}
+
+func (rec *Recorder) sendHeader() {
+	rec.wroteHeader = true
+	if rec.capture {
+		rec.header = rec.Header().Clone()
+	}
+}
+
+// Status returns the status code sent, 200 when the handler set none.
+func (rec *Recorder) Status() int {
+	return rec.status
+}
+
+// Size returns the number of body bytes written.
+func (rec *Recorder) Size() int64 {
+	return rec.size
+}
+
+// SentHeader returns the headers as they were sent, or nil when nothing was
+// sent or Capture was not called.
+func (rec *Recorder) SentHeader() http.Header {
+	return rec.header
+}
+
+// Body returns the body sent since Capture was called.
+func (rec *Recorder) Body() []byte {
+	return rec.body.Bytes()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *Recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,233 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MaxBodySize is the largest JSON body a handler reads.
+const MaxBodySize = 1 << 20
+
+var (
+	ErrUnsupportedMediaType = errors.New("content type is not application/json")
+	ErrBodyTooLarge         = fmt.Errorf("request body is larger than %d bytes", MaxBodySize)
+	ErrInvalidJSON          = errors.New("request body is not a valid JSON object")
+)
+
+// DecodeJSON reads a JSON object from the request body into dst and checks it
+// with Struct. Unknown fields, wrongly typed fields and rule violations are
+// all returned together as Errors.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaType != "application/json" {
+			return ErrUnsupportedMediaType
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return ErrBodyTooLarge
+		}
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	return Unmarshal(body, dst)
+}
+
+// Unmarshal decodes a JSON object into dst and checks it like DecodeJSON.
+func Unmarshal(body []byte, dst any) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return ErrInvalidJSON
+	}
+	errs := unknownFields(fields, reflect.TypeOf(dst), "")
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	err := dec.Decode(dst)
+	if err == nil && dec.More() {
+		// Only one object may make up the body.
+		return ErrInvalidJSON
+	}
+	if err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return ErrInvalidJSON
+		}
+		field := typeErr.Field
+		if field == "" {
+			field = typeErrorField(fields, reflect.TypeOf(dst))
+		}
+		errs = append(errs, FieldError{Field: field, Code: CodeInvalid, Message: "must be a " + jsonType(typeErr.Type)})
+		return errs
+	}
+
+	if err := Struct(dst); err != nil {
+		errs = append(errs, err.(Errors)...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// unknownFields reports the keys of the object that dst has no field for,
+// in nested objects and lists of objects too. Their names are put after
+// prefix.
+func unknownFields(fields map[string]json.RawMessage, dst reflect.Type, prefix string) Errors {
+	for dst.Kind() == reflect.Pointer {
+		dst = dst.Elem()
+	}
+	if dst.Kind() != reflect.Struct || decodesItself(dst) {
+		return nil
+	}
+	known := jsonFields(dst)
+	var errs Errors
+	for key, raw := range fields {
+		t, ok := known[key]
+		if !ok {
+			errs = append(errs, FieldError{Field: prefix + key, Code: CodeUnknownField, Message: "is not a known field"})
+			continue
+		}
+		errs = append(errs, nestedUnknownFields(raw, t, prefix+key)...)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// nestedUnknownFields looks for unknown fields in the value of the field
+// name. Values of the wrong type are left to the decoder to report.
+func nestedUnknownFields(raw json.RawMessage, t reflect.Type, name string) Errors {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if decodesItself(t) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil
+		}
+		return unknownFields(fields, t, name+".")
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil
+		}
+		var errs Errors
+		for i, item := range items {
+			errs = append(errs, nestedUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", name, i))...)
+		}
+		return errs
+	}
+	return nil
+}
+
+// jsonFields maps the JSON keys of the struct t to the types of their
+// values. Like encoding/json it includes the fields promoted from embedded
+// structs without a JSON name, where a shallower field hides a deeper one.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	known := make(map[string]reflect.Type)
+	seen := map[reflect.Type]bool{t: true}
+	for level := []reflect.Type{t}; len(level) > 0; {
+		var next []reflect.Type
+		for _, t := range level {
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+				if tag == "-" {
+					continue
+				}
+				if f.Anonymous && tag == "" {
+					embedded := f.Type
+					if embedded.Kind() == reflect.Pointer {
+						embedded = embedded.Elem()
+					}
+					// encoding/json cannot set fields behind a pointer to an
+					// unexported type.
+					promotes := embedded.Kind() == reflect.Struct && (f.IsExported() || f.Type.Kind() != reflect.Pointer)
+					if promotes && !seen[embedded] {
+						seen[embedded] = true
+						next = append(next, embedded)
+					}
+					if promotes || !f.IsExported() {
+						continue
+					}
+				}
+				if !f.IsExported() {
+					continue
+				}
+				if name := fieldName(f); known[name] == nil {
+					known[name] = f.Type
+				}
+			}
+		}
+		level = next
+	}
+	return known
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decodesItself reports whether values of t decode their JSON themselves, as
+// Optional does, so their fields do not name JSON keys.
+func decodesItself(t reflect.Type) bool {
+	return t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+// typeErrorField finds the field with a value of the wrong type when the
+// decoder does not name it, as for Optional fields.
+func typeErrorField(fields map[string]json.RawMessage, dst reflect.Type) string {
+	for dst.Kind() == reflect.Pointer {
+		dst = dst.Elem()
+	}
+	if dst.Kind() != reflect.Struct {
+		return ""
+	}
+	known := jsonFields(dst)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t, ok := known[name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(fields[name], reflect.New(t).Interface()); err != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	default:
+		return "object"
+	}
+}
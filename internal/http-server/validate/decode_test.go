@@ -0,0 +1,157 @@
+package validate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalUnknownFields(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	type request struct {
+		Title Optional[string] `json:"title"`
+		Item  *item            `json:"item"`
+		Items []item           `json:"items"`
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"known fields", `{"title":"a","item":{"name":"b"},"items":[{"name":"c"}]}`, nil},
+		{"top level", `{"title":"a","extra":1}`, []string{"extra"}},
+		{"nested object", `{"item":{"name":"b","extra":1}}`, []string{"item.extra"}},
+		{"list items", `{"items":[{"name":"c"},{"extra":1,"more":2}]}`, []string{"items[1].extra", "items[1].more"}},
+		{"null nested object", `{"item":null,"items":null}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst request
+			err := Unmarshal([]byte(tt.body), &dst)
+			var got []string
+			var errs Errors
+			if errors.As(err, &errs) {
+				for _, e := range errs {
+					if e.Code == CodeUnknownField {
+						got = append(got, e.Field)
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("unknown fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTrailingData(t *testing.T) {
+	type request struct {
+		Title string `json:"title"`
+	}
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"one object", `{"title":"a"}`, nil},
+		{"trailing whitespace", "{\"title\":\"a\"}\n\t ", nil},
+		{"second object", `{"title":"a"}{"title":"b"}`, ErrInvalidJSON},
+		{"second object after a newline", "{\"title\":\"a\"}\n{\"title\":\"b\"}", ErrInvalidJSON},
+		{"trailing value", `{"title":"a"} 1`, ErrInvalidJSON},
+		{"trailing garbage", `{"title":"a"}x`, ErrInvalidJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst request
+			if err := Unmarshal([]byte(tt.body), &dst); !errors.Is(err, tt.want) {
+				t.Errorf("Unmarshal = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+type Audit struct {
+	Author string `json:"author" validate:"required"`
+}
+
+type Base struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	*Audit
+}
+
+type embedding struct {
+	Base
+	// Title hides Base.Title.
+	Title int `json:"title"`
+	// Meta has a JSON name, so its fields stay nested.
+	Meta Audit `json:"meta"`
+}
+
+func TestUnmarshalEmbedded(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// unknown lists the unknown fields, invalid the other failing ones.
+		unknown []string
+		invalid []string
+	}{
+		{name: "promoted fields", body: `{"id":1,"title":2,"author":"a","meta":{"author":"b"}}`},
+		{name: "unknown field", body: `{"id":1,"author":"a","meta":{"author":"b"},"extra":1}`, unknown: []string{"extra"}},
+		{name: "embedded struct is not a key", body: `{"Base":{},"author":"a","meta":{"author":"b"}}`, unknown: []string{"Base"}},
+		{name: "named embedded struct is nested", body: `{"author":"a","meta":{"author":"b","id":1}}`, unknown: []string{"meta.id"}},
+		{name: "shallower field hides the promoted one", body: `{"title":"text","author":"a","meta":{"author":"b"}}`, invalid: []string{"title"}},
+		{name: "promoted rules", body: `{"author":"","meta":{"author":"b"}}`, invalid: []string{"author"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst embedding
+			err := Unmarshal([]byte(tt.body), &dst)
+			var unknown, invalid []string
+			var errs Errors
+			if errors.As(err, &errs) {
+				for _, e := range errs {
+					if e.Code == CodeUnknownField {
+						unknown = append(unknown, e.Field)
+					} else {
+						invalid = append(invalid, e.Field)
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !slices.Equal(unknown, tt.unknown) || !slices.Equal(invalid, tt.invalid) {
+				t.Errorf("unknown fields = %v, invalid = %v; want %v and %v", unknown, invalid, tt.unknown, tt.invalid)
+			}
+		})
+	}
+
+	t.Run("decoded", func(t *testing.T) {
+		var dst embedding
+		if err := Unmarshal([]byte(`{"id":7,"title":3,"author":"a","meta":{"author":"b"}}`), &dst); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if dst.ID != 7 || dst.Title != 3 || dst.Audit == nil || dst.Author != "a" || dst.Meta.Author != "b" {
+			t.Errorf("decoded %+v", dst)
+		}
+	})
+}
+
+func TestDecodeJSONTrailingData(t *testing.T) {
+	var dst struct {
+		Title string `json:"title"`
+	}
+	r := httptest.NewRequest(http.MethodPost, "/songs", strings.NewReader(`{"title":"a"}{"title":"b"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if err := DecodeJSON(httptest.NewRecorder(), r, &dst); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("DecodeJSON = %v, want %v", err, ErrInvalidJSON)
+	}
+}
@@ -0,0 +1,40 @@
+package validate
+
+import "encoding/json"
+
+// Optional is a field of a partial update that tells a field left out of the
+// body (Set is false) from an explicit null (Set and Null). Rules apply to
+// the value; notblank and required also reject null.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if string(b) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(b, &o.Value)
+}
+
+// Ptr returns nil for a field that was left out, a pointer to the zero value
+// for null and a pointer to the value otherwise, as the storage updates
+// expect: nil leaves a column unchanged and a zero value clears it.
+func (o Optional[T]) Ptr() *T {
+	if !o.Set {
+		return nil
+	}
+	v := o.Value
+	return &v
+}
+
+func (o Optional[T]) state() (set bool, null bool, value any) {
+	return o.Set, o.Null, o.Value
+}
+
+type optional interface {
+	state() (set bool, null bool, value any)
+}
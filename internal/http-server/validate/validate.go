@@ -0,0 +1,280 @@
+// Package validate checks request payloads against the rules declared in
+// their `validate` struct tags.
+//
+// Rules are separated by commas:
+//
+//	required     the field must be present and not blank
+//	notblank     a present string must not be blank
+//	max=N        at most N characters, N items or a value of N
+//	min=N        at least N characters, N items or a value of N
+//	date=LAYOUT  a non-empty string must be a date in the Go time layout
+//	url          a non-empty string must be an absolute http(s) URL
+//	youtube      a non-empty string must be a YouTube link
+//	oneof=A B C  a non-empty string must be one of the space-separated values
+//	dive         the rules after it apply to every item of a slice; struct
+//	             items are checked by their own tags
+//
+// Pointer fields are checked only when they are not nil, so a PATCH request
+// can leave a field out. Optional fields also tell null apart.
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Field error codes.
+const (
+	CodeRequired     = "required"
+	CodeInvalid      = "invalid"
+	CodeTooLong      = "too_long"
+	CodeTooShort     = "too_short"
+	CodeOutOfRange   = "out_of_range"
+	CodeInvalidDate  = "invalid_date"
+	CodeInvalidURL   = "invalid_url"
+	CodeUnknownField = "unknown_field"
+)
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors holds every violation found in a request.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Struct checks the tagged fields of v, which must be a struct or a pointer
+// to one, and returns Errors with every violation or nil. Fields promoted
+// from embedded structs are checked as fields of v.
+func Struct(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs Errors
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); f.Anonymous && name == "" {
+			if embedded := reflect.Indirect(rv.Field(i)); embedded.Kind() == reflect.Struct && embedded.CanInterface() {
+				if err := Struct(embedded.Interface()); err != nil {
+					errs = append(errs, err.(Errors)...)
+				}
+				continue
+			}
+		}
+		tag := f.Tag.Get("validate")
+		if tag == "" || !f.IsExported() {
+			continue
+		}
+		errs = append(errs, checkField(fieldName(f), rv.Field(i), strings.Split(tag, ","))...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func checkField(name string, v reflect.Value, rules []string) Errors {
+	if o, ok := v.Interface().(optional); ok {
+		set, null, value := o.state()
+		switch {
+		case !set && contains(rules, "required"):
+			return Errors{{Field: name, Code: CodeRequired, Message: "is required"}}
+		case !set:
+			return nil
+		case null && (contains(rules, "required") || contains(rules, "notblank")):
+			return Errors{{Field: name, Code: CodeRequired, Message: "must not be null"}}
+		case null:
+			return nil
+		}
+		v = reflect.ValueOf(value)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if contains(rules, "required") {
+				return Errors{{Field: name, Code: CodeRequired, Message: "is required"}}
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var errs Errors
+	for i, rule := range rules {
+		key, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if key == "dive" {
+			if v.Kind() == reflect.Slice {
+				for j := 0; j < v.Len(); j++ {
+					item := fmt.Sprintf("%s[%d]", name, j)
+					if reflect.Indirect(v.Index(j)).Kind() == reflect.Struct {
+						errs = append(errs, prefixed(item, Struct(v.Index(j).Interface()))...)
+						continue
+					}
+					errs = append(errs, checkField(item, v.Index(j), rules[i+1:])...)
+				}
+			}
+			break
+		}
+		if fe, ok := check(key, param, v); !ok {
+			fe.Field = name
+			errs = append(errs, fe)
+			if key == "required" || key == "notblank" {
+				// The other rules have nothing useful to add about a missing value.
+				break
+			}
+		}
+	}
+	return errs
+}
+
+func check(rule string, param string, v reflect.Value) (FieldError, bool) {
+	switch rule {
+	case "required", "notblank":
+		if isBlank(v) {
+			return FieldError{Code: CodeRequired, Message: "must not be empty"}, false
+		}
+	case "max", "min":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			panic(fmt.Sprintf("validate: bad %s parameter %q", rule, param))
+		}
+		return checkBound(rule == "max", n, v)
+	case "date":
+		s := v.String()
+		if _, err := time.Parse(param, s); s != "" && err != nil {
+			return FieldError{Code: CodeInvalidDate, Message: "must be a date in the format " + humanLayout(param)}, false
+		}
+	case "url":
+		if s := v.String(); s != "" && !isAbsoluteURL(s) {
+			return FieldError{Code: CodeInvalidURL, Message: "must be an absolute http or https URL"}, false
+		}
+	case "youtube":
+		if s := v.String(); s != "" && !isYoutubeURL(s) {
+			return FieldError{Code: CodeInvalidURL, Message: "must be a YouTube link"}, false
+		}
+	case "oneof":
+		values := strings.Fields(param)
+		if s := v.String(); s != "" && !contains(values, s) {
+			return FieldError{Code: CodeInvalid, Message: "must be one of " + strings.Join(values, ", ")}, false
+		}
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", rule))
+	}
+	return FieldError{}, true
+}
+
+func checkBound(isMax bool, n int, v reflect.Value) (FieldError, bool) {
+	var size int
+	var unit string
+	switch v.Kind() {
+	case reflect.String:
+		size, unit = utf8.RuneCountInString(v.String()), " characters"
+	case reflect.Slice, reflect.Map:
+		size, unit = v.Len(), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = int(v.Uint())
+	default:
+		return FieldError{}, true
+	}
+	switch {
+	case isMax && size > n:
+		code := CodeTooLong
+		if unit == "" {
+			code = CodeOutOfRange
+		}
+		return FieldError{Code: code, Message: fmt.Sprintf("must be at most %d%s", n, unit)}, false
+	case !isMax && size < n:
+		code := CodeTooShort
+		if unit == "" {
+			code = CodeOutOfRange
+		}
+		return FieldError{Code: code, Message: fmt.Sprintf("must be at least %d%s", n, unit)}, false
+	}
+	return FieldError{}, true
+}
+
+func isBlank(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+var youtubeHosts = map[string]bool{
+	"youtube.com":       true,
+	"www.youtube.com":   true,
+	"m.youtube.com":     true,
+	"music.youtube.com": true,
+	"youtu.be":          true,
+}
+
+func isYoutubeURL(s string) bool {
+	if !isAbsoluteURL(s) {
+		return false
+	}
+	u, _ := url.Parse(s)
+	return youtubeHosts[strings.ToLower(u.Hostname())]
+}
+
+// humanLayout turns a Go time layout like 2006-01-02 into YYYY-MM-DD.
+func humanLayout(layout string) string {
+	return strings.NewReplacer("2006", "YYYY", "01", "MM", "02", "DD").Replace(layout)
+}
+
+// fieldName is the JSON name of the field as clients see it.
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+// prefixed puts the name of the item before the fields of its errors.
+func prefixed(item string, err error) Errors {
+	errs, _ := err.(Errors)
+	for i := range errs {
+		errs[i].Field = item + "." + errs[i].Field
+	}
+	return errs
+}
+
+func contains(rules []string, rule string) bool {
+	for _, r := range rules {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+// Package logctx carries a request-scoped logger in a context, so every
+// line logged for a request, down to the storage, names the request.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+type key struct{}
+
+// With returns a copy of ctx that carries log.
+func With(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, key{}, log)
+}
+
+// From returns the logger ctx carries, or fallback when it carries none.
+func From(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if log, ok := ctx.Value(key{}).(*slog.Logger); ok {
+		return log
+	}
+	return fallback
+}
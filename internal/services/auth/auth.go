@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials is returned when the request carries neither an API
+	// key nor a bearer token.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned for unknown API keys and for tokens
+	// that fail verification.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// APIKeyHeader carries a static API key.
+const APIKeyHeader = "X-API-Key"
+
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleLevels[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants at least the access of required.
+// Every role includes the roles below it: admin > editor > reader.
+func (r Role) Allows(required Role) bool {
+	return roleLevels[r] >= roleLevels[required]
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+type APIKey struct {
+	Key     string
+	Subject string
+	Role    string
+}
+
+// DevSecret is the HS256 secret of .env.example. It is public, so New only
+// accepts it in dev mode.
+const DevSecret = "local-dev-secret"
+
+// MinSecretLength is the shortest HS256 secret New accepts outside dev mode;
+// shorter secrets can be guessed offline from any token signed with them.
+const MinSecretLength = 32
+
+type Config struct {
+	APIKeys []APIKey
+	// JWTSecret enables HS256 bearer tokens.
+	JWTSecret string
+	// JWTPublicKeyFile is a PEM file with the RSA public key that enables
+	// RS256 bearer tokens.
+	JWTPublicKeyFile string
+	// JWTIssuer and JWTAudience are checked against the iss and aud claims when set.
+	JWTIssuer   string
+	JWTAudience string
+	// Dev accepts DevSecret and secrets shorter than MinSecretLength, for
+	// local runs.
+	Dev bool
+}
+
+// Authenticator resolves the credentials of a request to a Principal.
+type Authenticator struct {
+	apiKeys   []apiKey
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+}
+
+type apiKey struct {
+	key       []byte
+	principal Principal
+}
+
+func New(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+	}
+	for _, k := range cfg.APIKeys {
+		if k.Key == "" || k.Subject == "" {
+			return nil, errors.New("api key and subject must not be empty")
+		}
+		role, err := ParseRole(k.Role)
+		if err != nil {
+			return nil, fmt.Errorf("api key of %s: %w", k.Subject, err)
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{key: []byte(k.Key), principal: Principal{Subject: k.Subject, Role: role}})
+	}
+	if cfg.JWTSecret != "" {
+		if !cfg.Dev && cfg.JWTSecret == DevSecret {
+			return nil, errors.New("the jwt secret of .env.example is only accepted in dev mode")
+		}
+		if !cfg.Dev && len(cfg.JWTSecret) < MinSecretLength {
+			return nil, fmt.Errorf("jwt secret must be at least %d bytes long", MinSecretLength)
+		}
+		a.secret = []byte(cfg.JWTSecret)
+	}
+	if cfg.JWTPublicKeyFile != "" {
+		key, err := readPublicKey(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		a.publicKey = key
+	}
+	return a, nil
+}
+
+// Authenticate checks the X-API-Key header or, if there is none, the bearer
+// token in the Authorization header.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.checkAPIKey(key)
+	}
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Principal{}, fmt.Errorf("%w: expected a bearer token", ErrInvalidCredentials)
+	}
+	return a.verifyToken(strings.TrimSpace(token))
+}
+
+func (a *Authenticator) checkAPIKey(key string) (Principal, error) {
+	for _, k := range a.apiKeys {
+		if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+			return k.principal, nil
+		}
+	}
+	return Principal{}, fmt.Errorf("%w: unknown api key", ErrInvalidCredentials)
+}
+
+func readPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in %s", path)
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key in %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries the authenticated caller.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the authenticated caller, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Subject returns the name of the authenticated caller, or "" for anonymous requests.
+func Subject(ctx context.Context) string {
+	p, _ := FromContext(ctx)
+	return p.Subject
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// writePublicKey stores the public half of key as PEM and returns its path.
+func writePublicKey(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pub")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// unsigned returns the header and claims segments of a token.
+func unsigned(t *testing.T, alg string, claims Claims) string {
+	t.Helper()
+	h, err := json.Marshal(header{Alg: alg, Typ: "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims Claims) string {
+	t.Helper()
+	signed := unsigned(t, "RS256", claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signHS(t *testing.T, secret string, claims Claims) string {
+	t.Helper()
+	token, err := SignHS256(secret, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthenticate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyFile := writePublicKey(t, key)
+
+	both, err := New(Config{
+		APIKeys:          []APIKey{{Key: "k-editor", Subject: "ci", Role: "editor"}},
+		JWTSecret:        testSecret,
+		JWTPublicKeyFile: publicKeyFile,
+		JWTIssuer:        "effective-mobile",
+		JWTAudience:      "songs",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	rsOnly, err := New(Config{JWTPublicKeyFile: publicKeyFile})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := Claims{Subject: "alice", Role: "admin", Issuer: "effective-mobile", Audience: Audience{"songs"}, ExpiresAt: exp}
+	with := func(change func(c *Claims)) Claims {
+		c := valid
+		change(&c)
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		auth   *Authenticator
+		apiKey string
+		bearer string
+		want   Principal
+		err    error
+	}{
+		{
+			name:   "HS256 token",
+			auth:   both,
+			bearer: signHS(t, testSecret, valid),
+			want:   Principal{Subject: "alice", Role: RoleAdmin},
+		},
+		{
+			name:   "RS256 token",
+			auth:   both,
+			bearer: signRS256(t, key, valid),
+			want:   Principal{Subject: "alice", Role: RoleAdmin},
+		},
+		{
+			name:   "API key",
+			auth:   both,
+			apiKey: "k-editor",
+			want:   Principal{Subject: "ci", Role: RoleEditor},
+		},
+		{
+			name: "no credentials",
+			auth: both,
+			err:  ErrNoCredentials,
+		},
+		{
+			name:   "unknown API key",
+			auth:   both,
+			apiKey: "k-unknown",
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "expired token",
+			auth:   both,
+			bearer: signHS(t, testSecret, with(func(c *Claims) { c.ExpiresAt = time.Now().Add(-time.Hour).Unix() })),
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "token without exp",
+			auth:   both,
+			bearer: signHS(t, testSecret, with(func(c *Claims) { c.ExpiresAt = 0 })),
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "token signed with another secret",
+			auth:   both,
+			bearer: signHS(t, "another-secret-another-secret-32", valid),
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "HS256 token when only RS256 is configured",
+			auth:   rsOnly,
+			bearer: signHS(t, testSecret, valid),
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "HS256 token signed with the RSA public key",
+			auth:   rsOnly,
+			bearer: signHS(t, string(x509.MarshalPKCS1PublicKey(&key.PublicKey)), valid),
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "alg none",
+			auth:   both,
+			bearer: unsigned(t, "none", valid) + ".",
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "wrong issuer",
+			auth:   both,
+			bearer: signHS(t, testSecret, with(func(c *Claims) { c.Issuer = "someone-else" })),
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "wrong audience",
+			auth:   both,
+			bearer: signHS(t, testSecret, with(func(c *Claims) { c.Audience = Audience{"billing"} })),
+			err:    ErrInvalidCredentials,
+		},
+		{
+			name:   "unknown role",
+			auth:   both,
+			bearer: signHS(t, testSecret, with(func(c *Claims) { c.Role = "root" })),
+			err:    ErrInvalidCredentials,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/songs", nil)
+			if tt.apiKey != "" {
+				r.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			got, err := tt.auth.Authenticate(r)
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Fatalf("Authenticate: err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Authenticate = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{RoleReader, RoleReader, true},
+		{RoleReader, RoleEditor, false},
+		{RoleEditor, RoleReader, true},
+		{RoleEditor, RoleAdmin, false},
+		{RoleAdmin, RoleEditor, true},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %t, want %t", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"API keys only", Config{APIKeys: []APIKey{{Key: "k", Subject: "ci", Role: "editor"}}}, false},
+		{"strong secret", Config{JWTSecret: testSecret}, false},
+		{"short secret", Config{JWTSecret: "short"}, true},
+		{"dev secret", Config{JWTSecret: DevSecret}, true},
+		{"dev secret in dev mode", Config{JWTSecret: DevSecret, Dev: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New: err = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
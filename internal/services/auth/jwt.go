@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is the tolerance for exp and nbf.
+const clockSkew = 30 * time.Second
+
+// Claims are the JWT claims the service reads.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Role      string   `json:"role"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// Audience is the aud claim, which may be a single string or a list.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = Audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("aud must be a string or a list of strings")
+	}
+	*a = many
+	return nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+func (a *Authenticator) verifyToken(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Principal{}, fmt.Errorf("%w: bad header: %v", ErrInvalidCredentials, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: bad signature encoding", ErrInvalidCredentials)
+	}
+	signed := parts[0] + "." + parts[1]
+	if err := a.verifySignature(h.Alg, signed, signature); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("%w: bad claims: %v", ErrInvalidCredentials, err)
+	}
+	if err := a.checkClaims(claims, time.Now()); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	role, err := ParseRole(claims.Role)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	return Principal{Subject: claims.Subject, Role: role}, nil
+}
+
+// verifySignature only accepts the algorithms that have a key configured, so
+// a token cannot pick a weaker check than the server expects.
+func (a *Authenticator) verifySignature(alg string, signed string, signature []byte) error {
+	switch alg {
+	case "HS256":
+		if a.secret == nil {
+			return errors.New("HS256 tokens are not accepted")
+		}
+		if !hmac.Equal(signHS256(a.secret, signed), signature) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case "RS256":
+		if a.publicKey == nil {
+			return errors.New("RS256 tokens are not accepted")
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(a.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+func (a *Authenticator) checkClaims(c Claims, now time.Time) error {
+	if c.Subject == "" {
+		return errors.New("sub claim is required")
+	}
+	if c.ExpiresAt == 0 {
+		return errors.New("exp claim is required")
+	}
+	if now.Add(-clockSkew).Unix() >= c.ExpiresAt {
+		return errors.New("token expired")
+	}
+	if c.NotBefore != 0 && now.Add(clockSkew).Unix() < c.NotBefore {
+		return errors.New("token not valid yet")
+	}
+	if a.issuer != "" && c.Issuer != a.issuer {
+		return errors.New("unexpected issuer")
+	}
+	if a.audience != "" && !slices.Contains(c.Audience, a.audience) {
+		return errors.New("unexpected audience")
+	}
+	return nil
+}
+
+// SignHS256 issues an HS256 token with the given claims.
+func SignHS256(secret string, claims Claims) (string, error) {
+	h, err := json.Marshal(header{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signHS256([]byte(secret), signed)), nil
+}
+
+func signHS256(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
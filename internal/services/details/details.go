@@ -0,0 +1,263 @@
+package details
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("effective-mobile/internal/services/details")
+
+var (
+	// ErrBadRequest is returned when the provider rejects the group/song pair.
+	ErrBadRequest = errors.New("details provider rejected the request")
+	// ErrUnavailable is returned when the provider could not be reached or
+	// kept failing after all retries.
+	ErrUnavailable = errors.New("details provider unavailable")
+	// ErrCircuitOpen is returned without calling the provider while the
+	// circuit breaker is open.
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+)
+
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Provider fetches song details from the external music info API.
+type Provider interface {
+	SongDetails(ctx context.Context, group string, song string) (SongDetail, error)
+}
+
+type Config struct {
+	// BaseURL is the scheme and host of the API, e.g. http://localhost:8081.
+	BaseURL string
+	// Timeout bounds a single HTTP attempt.
+	Timeout time.Duration
+	// MaxRetries is the number of extra attempts after a 5xx or transport error.
+	MaxRetries int
+	// BackoffBase is the delay before the first retry; it doubles on every retry up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// BreakerThreshold is the number of consecutive failed calls that opens
+	// the circuit breaker. Zero disables the breaker.
+	BreakerThreshold int
+	// BreakerCooldown is how long the breaker stays open before a trial call.
+	BreakerCooldown time.Duration
+}
+
+type Client struct {
+	log     *slog.Logger
+	baseURL *url.URL
+	cfg     Config
+	http    *http.Client
+	breaker *breaker
+	metrics metrics
+}
+
+var _ Provider = (*Client)(nil)
+
+func New(log *slog.Logger, cfg Config) (*Client, error) {
+	const op = "services.details.New"
+	base, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("%s: base url %q must be absolute", op, cfg.BaseURL)
+	}
+	if cfg.BackoffMax < cfg.BackoffBase {
+		cfg.BackoffMax = cfg.BackoffBase
+	}
+	return &Client{
+		log:     log.With(slog.String("component", "services/details")),
+		baseURL: base,
+		cfg:     cfg,
+		http: &http.Client{
+			// The transport traces every attempt made within a trace, so not
+			// the pings of the readiness probe, and passes the trace on in
+			// the traceparent header.
+			Transport: otelhttp.NewTransport(http.DefaultTransport,
+				otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+					return "details " + r.Method + " " + r.URL.Path
+				}),
+				otelhttp.WithFilter(func(r *http.Request) bool {
+					return trace.SpanContextFromContext(r.Context()).IsValid()
+				}),
+			),
+		},
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		metrics: newMetrics(),
+	}, nil
+}
+
+func (c *Client) SongDetails(ctx context.Context, group string, song string) (SongDetail, error) {
+	ctx, span := tracer.Start(ctx, "services.details.SongDetails", trace.WithAttributes(
+		attribute.String("details.group", group),
+		attribute.String("details.song", song),
+	))
+	defer span.End()
+
+	start := time.Now()
+	detail, err := c.songDetails(ctx, group, song)
+	c.metrics.observe(start, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return detail, err
+}
+
+func (c *Client) songDetails(ctx context.Context, group string, song string) (SongDetail, error) {
+	const op = "services.details.SongDetails"
+	log := c.log.With(slog.String("op", op))
+
+	if !c.breaker.allow() {
+		return SongDetail{}, ErrCircuitOpen
+	}
+
+	endpoint := c.baseURL.JoinPath("info")
+	endpoint.RawQuery = url.Values{"group": {group}, "song": {song}}.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff(attempt)
+			log.Warn("Retrying details request",
+				slog.Int("attempt", attempt),
+				slog.String("delay", delay.String()),
+				slog.Any("error", lastErr),
+			)
+			select {
+			case <-ctx.Done():
+				c.breaker.abort()
+				return SongDetail{}, fmt.Errorf("%s: %w", op, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		detail, retry, err := c.do(ctx, endpoint.String())
+		if err == nil {
+			c.breaker.success()
+			return detail, nil
+		}
+		if !retry {
+			if errors.Is(err, ErrBadRequest) {
+				// the provider is healthy, the input is not
+				c.breaker.success()
+			} else {
+				c.breaker.failure()
+			}
+			return SongDetail{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if ctx.Err() != nil {
+			c.breaker.abort()
+			return SongDetail{}, fmt.Errorf("%s: %w", op, ctx.Err())
+		}
+		lastErr = err
+	}
+
+	c.breaker.failure()
+	return SongDetail{}, fmt.Errorf("%s: %w: %v", op, ErrUnavailable, lastErr)
+}
+
+// Ping checks that the provider answers HTTP requests at all, without
+// looking up a song or counting towards the circuit breaker. While the
+// breaker is open it fails with ErrCircuitOpen without calling the provider.
+func (c *Client) Ping(ctx context.Context) error {
+	const op = "services.details.Ping"
+	if c.breaker.open() {
+		return fmt.Errorf("%s: %w", op, ErrCircuitOpen)
+	}
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %v", op, ErrUnavailable, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%s: %w: status %d", op, ErrUnavailable, resp.StatusCode)
+	}
+	return nil
+}
+
+// do performs a single attempt and reports whether a failure is worth retrying.
+func (c *Client) do(ctx context.Context, endpoint string) (SongDetail, bool, error) {
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return SongDetail{}, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return SongDetail{}, true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 500:
+		return SongDetail{}, true, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound:
+		return SongDetail{}, false, fmt.Errorf("%w: status %d: %s", ErrBadRequest, resp.StatusCode, readSnippet(resp.Body))
+	default:
+		return SongDetail{}, false, fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var detail SongDetail
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		return SongDetail{}, false, fmt.Errorf("%w: failed to decode response: %v", ErrUnavailable, err)
+	}
+	return detail, false, nil
+}
+
+// backoff returns the delay before the given retry: BackoffBase doubled per
+// attempt, capped at BackoffMax, with up to 20% jitter.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.cfg.BackoffBase
+	for i := 1; i < attempt && delay < c.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > c.cfg.BackoffMax {
+		delay = c.cfg.BackoffMax
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay + rand.N(delay/5+1)
+}
+
+func readSnippet(r io.Reader) string {
+	b, _ := io.ReadAll(io.LimitReader(r, 256))
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,175 @@
+package details_test
+
+import (
+	"context"
+	"effective-mobile/internal/services/details"
+	"effective-mobile/internal/services/details/stub"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// provider is the stub API that fails the way fail says before answering.
+// fail gets the number of the attempt, from 1, and returns 0 to let the stub
+// answer, -1 to drop the connection or an HTTP status to reply with.
+type provider struct {
+	calls atomic.Int32
+	fail  atomic.Value
+}
+
+func newProvider(t *testing.T, fail func(call int) int) (*provider, string) {
+	t.Helper()
+	p := &provider{}
+	p.fail.Store(fail)
+	api := stub.NewHandler(discard)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(p.calls.Add(1))
+		switch status := p.fail.Load().(func(int) int)(call); {
+		case status < 0:
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		case status > 0:
+			http.Error(w, http.StatusText(status), status)
+		default:
+			api.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return p, srv.URL
+}
+
+func newClient(t *testing.T, url string, cfg details.Config) *details.Client {
+	t.Helper()
+	cfg.BaseURL = url
+	cfg.Timeout = time.Second
+	cfg.BackoffBase = time.Millisecond
+	cfg.BackoffMax = 5 * time.Millisecond
+	c, err := details.New(discard, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func always(status int) func(int) int {
+	return func(int) int { return status }
+}
+
+func TestSongDetailsRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      func(call int) int
+		wantCalls int32
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			fail:      always(0),
+			wantCalls: 1,
+		},
+		{
+			name: "retried 5xx",
+			fail: func(call int) int {
+				if call < 3 {
+					return http.StatusServiceUnavailable
+				}
+				return 0
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "retried transport error",
+			fail: func(call int) int {
+				if call == 1 {
+					return -1
+				}
+				return 0
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "4xx not retried",
+			fail:      always(http.StatusNotFound),
+			wantCalls: 1,
+			wantErr:   details.ErrBadRequest,
+		},
+		{
+			name:      "gives up after MaxRetries",
+			fail:      always(http.StatusInternalServerError),
+			wantCalls: 3,
+			wantErr:   details.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, url := newProvider(t, tt.fail)
+			c := newClient(t, url, details.Config{MaxRetries: 2})
+
+			got, err := c.SongDetails(context.Background(), "Muse", "Uprising")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("SongDetails: err = %v, want %v", err, tt.wantErr)
+			}
+			if calls := p.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", calls, tt.wantCalls)
+			}
+			if want := stub.Detail("Muse", "Uprising"); tt.wantErr == nil && got != want {
+				t.Errorf("SongDetails = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSongDetailsBreaker(t *testing.T) {
+	const cooldown = 50 * time.Millisecond
+	p, url := newProvider(t, always(http.StatusInternalServerError))
+	c := newClient(t, url, details.Config{BreakerThreshold: 2, BreakerCooldown: cooldown})
+	call := func() error {
+		_, err := c.SongDetails(context.Background(), "Muse", "Uprising")
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := call(); !errors.Is(err, details.ErrUnavailable) || errors.Is(err, details.ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want a provider failure", i+1, err)
+		}
+	}
+	if err := call(); !errors.Is(err, details.ErrCircuitOpen) {
+		t.Fatalf("call after %d failures: err = %v, want %v", 2, err, details.ErrCircuitOpen)
+	}
+	if err := c.Ping(context.Background()); !errors.Is(err, details.ErrCircuitOpen) {
+		t.Errorf("Ping while open: err = %v, want %v", err, details.ErrCircuitOpen)
+	}
+	if calls := p.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times, want 2: the open breaker must not call it", calls)
+	}
+
+	// A failed trial call after the cooldown opens the breaker again.
+	time.Sleep(cooldown)
+	if err := call(); errors.Is(err, details.ErrCircuitOpen) {
+		t.Fatalf("trial call after the cooldown: err = %v, want a provider failure", err)
+	}
+	if err := call(); !errors.Is(err, details.ErrCircuitOpen) {
+		t.Fatalf("call after a failed trial: err = %v, want %v", err, details.ErrCircuitOpen)
+	}
+
+	// A successful trial call closes it.
+	p.fail.Store(always(0))
+	time.Sleep(cooldown)
+	for i := 0; i < 3; i++ {
+		if err := call(); err != nil {
+			t.Fatalf("call %d after the provider recovered: %v", i+1, err)
+		}
+	}
+	if calls := p.calls.Load(); calls != 6 {
+		t.Errorf("provider called %d times, want 6", calls)
+	}
+}
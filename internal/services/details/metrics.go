@@ -0,0 +1,64 @@
+package details
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of a SongDetails call as the metrics label them.
+const (
+	outcomeSuccess     = "success"
+	outcomeRejected    = "rejected"
+	outcomeUnavailable = "unavailable"
+	outcomeCircuitOpen = "circuit_open"
+	outcomeCanceled    = "canceled"
+)
+
+type metrics struct {
+	calls    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newMetrics() metrics {
+	return metrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "details_calls_total",
+			Help: "Calls to the details provider by outcome, retries included in one call.",
+		}, []string{"outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "details_call_duration_seconds",
+			Help:    "Duration of calls to the details provider by outcome.",
+			Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"outcome"}),
+	}
+}
+
+func (m metrics) observe(start time.Time, err error) {
+	outcome := outcomeOf(err)
+	m.calls.WithLabelValues(outcome).Inc()
+	m.duration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+}
+
+func outcomeOf(err error) string {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.Is(err, ErrCircuitOpen):
+		return outcomeCircuitOpen
+	case errors.Is(err, ErrBadRequest):
+		return outcomeRejected
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return outcomeCanceled
+	default:
+		return outcomeUnavailable
+	}
+}
+
+// Collectors returns the metrics of the calls to the provider, for
+// registration with Prometheus.
+func (c *Client) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.metrics.calls, c.metrics.duration}
+}
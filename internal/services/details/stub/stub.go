@@ -0,0 +1,66 @@
+package stub
+
+import (
+	"effective-mobile/internal/services/details"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var known = map[string]details.SongDetail{
+	"muse|supermassive black hole": {
+		ReleaseDate: "16.07.2006",
+		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	},
+}
+
+// NewHandler returns a fake implementation of the music info API. It serves
+// GET /info?group=...&song=... and answers with deterministic details for any
+// pair, so the add flow can be exercised without the real provider.
+func NewHandler(log *slog.Logger) http.Handler {
+	log = log.With(slog.String("component", "services/details/stub"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /info", func(w http.ResponseWriter, r *http.Request) {
+		group := r.URL.Query().Get("group")
+		song := r.URL.Query().Get("song")
+		log.Info("Details requested", slog.String("group", group), slog.String("song", song))
+
+		if group == "" || song == "" {
+			http.Error(w, "group and song are required", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(Detail(group, song)); err != nil {
+			log.Error("Failed to encode details", slog.Any("error", err))
+		}
+	})
+	return mux
+}
+
+// Detail returns the details the stub serves for the pair.
+func Detail(group string, song string) details.SongDetail {
+	if detail, ok := known[strings.ToLower(group)+"|"+strings.ToLower(song)]; ok {
+		return detail
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(group + "|" + song))
+	sum := h.Sum32()
+	released := time.Date(1960, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(sum%22000))
+
+	return details.SongDetail{
+		ReleaseDate: released.Format("02.01.2006"),
+		Text: fmt.Sprintf(
+			"First verse of %s by %s\nStill the first verse\n\nChorus of %s\nChorus again\n\nLast verse of %s",
+			song, group, song, song,
+		),
+		Link: fmt.Sprintf("https://www.youtube.com/watch?v=%011x", sum),
+	}
+}
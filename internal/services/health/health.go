@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Statuses of a single check.
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+)
+
+// Statuses of a whole report.
+const (
+	StatusReady        = "ready"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check probes one dependency of the service.
+type Check struct {
+	Name string
+	// Critical checks make the service unavailable when they fail; the
+	// others only degrade it.
+	Critical bool
+	Probe    func(ctx context.Context) error
+}
+
+type Result struct {
+	Status    string  `json:"status" example:"up"`
+	LatencyMs float64 `json:"latencyMs" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of all checks. Status is StatusReady,
+// StatusDegraded when only checks that are not critical failed,
+// StatusUnavailable or StatusShuttingDown.
+type Report struct {
+	Status string            `json:"status" example:"ready"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Ready reports whether the service can take traffic.
+func (r Report) Ready() bool {
+	return r.Status == StatusReady || r.Status == StatusDegraded
+}
+
+// Checker runs the checks of the service's dependencies.
+type Checker struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// New creates a checker that bounds every check by timeout; zero leaves
+// checks bounded only by their context.
+func New(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// Drain makes every later report StatusShuttingDown, so load balancers stop
+// sending requests while the server finishes the ones it has.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check runs all checks in parallel.
+func (c *Checker) Check(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]Result, len(c.checks))}
+	for i, check := range c.checks {
+		report.Checks[check.Name] = results[i]
+		switch {
+		case results[i].Status == StatusDown:
+			report.Status = StatusUnavailable
+		case results[i].Status == StatusDegraded && report.Status == StatusReady:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run stops waiting for the probe when ctx is done, so a probe that ignores
+// its context cannot hold up the report; it finishes in the background.
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Probe(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := Result{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDegraded
+		if check.Critical {
+			result.Status = StatusDown
+		}
+		result.Error = err.Error()
+	}
+	return result
+}
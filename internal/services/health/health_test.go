@@ -0,0 +1,127 @@
+package health_test
+
+import (
+	"context"
+	"effective-mobile/internal/services/health"
+	"errors"
+	"testing"
+	"time"
+)
+
+const timeout = 50 * time.Millisecond
+
+func up(context.Context) error { return nil }
+
+// hang blocks until release is closed, whatever happens to its context.
+func hang(release <-chan struct{}) func(context.Context) error {
+	return func(context.Context) error {
+		<-release
+		return nil
+	}
+}
+
+// wait blocks until its context is done.
+func wait(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCheck(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name   string
+		checks []health.Check
+		status string
+		// down and degraded name the checks expected to fail.
+		down     string
+		degraded string
+	}{
+		{
+			name:   "all up",
+			checks: []health.Check{{Name: "storage", Critical: true, Probe: up}, {Name: "details", Probe: up}},
+			status: health.StatusReady,
+		},
+		{
+			name:   "critical check fails",
+			checks: []health.Check{{Name: "storage", Critical: true, Probe: func(context.Context) error { return errors.New("refused") }}, {Name: "details", Probe: up}},
+			status: health.StatusUnavailable,
+			down:   "storage",
+		},
+		{
+			name:     "other check fails",
+			checks:   []health.Check{{Name: "storage", Critical: true, Probe: up}, {Name: "details", Probe: func(context.Context) error { return errors.New("503") }}},
+			status:   health.StatusDegraded,
+			degraded: "details",
+		},
+		{
+			name:   "critical check hangs past the timeout",
+			checks: []health.Check{{Name: "storage", Critical: true, Probe: hang(release)}, {Name: "details", Probe: up}},
+			status: health.StatusUnavailable,
+			down:   "storage",
+		},
+		{
+			name:     "other check waits out the timeout",
+			checks:   []health.Check{{Name: "storage", Critical: true, Probe: up}, {Name: "details", Probe: wait}},
+			status:   health.StatusDegraded,
+			degraded: "details",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			report := health.New(timeout, tt.checks...).Check(context.Background())
+			if elapsed := time.Since(start); elapsed > 10*timeout {
+				t.Errorf("Check took %v, want about the timeout of %v at most", elapsed, timeout)
+			}
+			if report.Status != tt.status {
+				t.Errorf("status = %s, want %s", report.Status, tt.status)
+			}
+			for name, result := range report.Checks {
+				want := health.StatusUp
+				switch name {
+				case tt.down:
+					want = health.StatusDown
+				case tt.degraded:
+					want = health.StatusDegraded
+				}
+				if result.Status != want {
+					t.Errorf("%s = %s, want %s", name, result.Status, want)
+				}
+				if (want == health.StatusUp) != (result.Error == "") {
+					t.Errorf("%s error = %q", name, result.Error)
+				}
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Errorf("%d results, want %d", len(report.Checks), len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	report := health.New(timeout, health.Check{Name: "storage", Critical: true, Probe: hang(release)}).Check(context.Background())
+	result := report.Checks["storage"]
+	if result.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("error = %q, want %q", result.Error, context.DeadlineExceeded.Error())
+	}
+	if result.LatencyMs < float64(timeout.Milliseconds()) {
+		t.Errorf("latency = %vms, want at least the timeout", result.LatencyMs)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	checker := health.New(timeout, health.Check{Name: "storage", Critical: true, Probe: up})
+	if report := checker.Check(context.Background()); !report.Ready() {
+		t.Fatalf("status = %s before draining, want ready", report.Status)
+	}
+	checker.Drain()
+	report := checker.Check(context.Background())
+	if report.Status != health.StatusShuttingDown || report.Ready() || len(report.Checks) != 0 {
+		t.Errorf("report after Drain = %+v, want %s without checks", report, health.StatusShuttingDown)
+	}
+}
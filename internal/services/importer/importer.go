@@ -0,0 +1,335 @@
+package importer
+
+import (
+	"context"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/services/details"
+	"effective-mobile/internal/storage"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown import format")
+	// ErrInvalidFile is returned when the file cannot be read at all, e.g.
+	// the CSV header is wrong. Bad rows are reported per row instead.
+	ErrInvalidFile = errors.New("invalid import file")
+)
+
+// Row statuses of a Report.
+const (
+	StatusCreated = "created"
+	StatusUpdated = "updated"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// Row error codes that have no match among the problem codes.
+const (
+	CodeInvalidRow   = "invalid_row"
+	CodeDuplicateRow = "duplicate_row"
+)
+
+type Config struct {
+	// BatchSize is the number of songs stored per transaction.
+	BatchSize int
+	// Concurrency bounds the parallel calls to the details provider.
+	Concurrency int
+}
+
+type Options struct {
+	// Upsert overwrites songs that already exist instead of skipping them.
+	Upsert bool
+	// Enrich fills a missing release date, lyrics or link from the details
+	// provider.
+	Enrich bool
+	// Actor is recorded as the author of the stored songs.
+	Actor storage.Actor
+}
+
+// Report lists the outcome of every row of an import in file order.
+type Report struct {
+	Total   int         `json:"total"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Rows    []RowResult `json:"rows"`
+}
+
+type RowResult struct {
+	Line   int             `json:"line"`
+	Group  string          `json:"group,omitempty"`
+	Song   string          `json:"song,omitempty"`
+	Status string          `json:"status"`
+	ID     uint            `json:"id,omitempty"`
+	Code   string          `json:"code,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Errors validate.Errors `json:"errors,omitempty"`
+}
+
+// Importer streams songs from CSV or NDJSON files into the storage.
+type Importer struct {
+	log      *slog.Logger
+	store    storage.SongStore
+	provider details.Provider
+	cfg      Config
+}
+
+func New(log *slog.Logger, store storage.SongStore, provider details.Provider, cfg Config) *Importer {
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 500
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	return &Importer{
+		log:      log.With(slog.String("component", "services/importer")),
+		store:    store,
+		provider: provider,
+		cfg:      cfg,
+	}
+}
+
+// pending is a row on its way from the reader to the storage. A done row
+// already has its result and is only reported.
+type pending struct {
+	result RowResult
+	song   storage.Song
+	done   bool
+}
+
+// Import reads every row of the file, enriches the rows that miss details
+// with at most Concurrency provider calls at a time and stores them in
+// batches. A song that appears twice in the file is only stored once; songs
+// that already exist are skipped unless opts.Upsert is set. It fails only
+// when the file cannot be read or ctx is done; the batches stored until then
+// stay stored, and importing the file again skips them.
+func (im *Importer) Import(ctx context.Context, r io.Reader, format Format, opts Options) (Report, error) {
+	const op = "services.importer.Import"
+	log := im.log.With(slog.String("op", op))
+
+	rows, err := newRowReader(r, format)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ready := make(chan pending, im.cfg.BatchSize)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- im.read(ctx, rows, opts, ready)
+	}()
+
+	report := Report{Rows: []RowResult{}}
+	batch := make([]pending, 0, im.cfg.BatchSize)
+	finish := func(p pending) {
+		report.Rows = append(report.Rows, p.result)
+	}
+	for p := range ready {
+		if p.done {
+			finish(p)
+			continue
+		}
+		batch = append(batch, p)
+		if len(batch) == im.cfg.BatchSize {
+			im.flush(ctx, log, batch, opts, finish)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		im.flush(ctx, log, batch, opts, finish)
+	}
+	if err := <-readErr; err != nil {
+		return Report{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	slices.SortFunc(report.Rows, func(a, b RowResult) int { return a.Line - b.Line })
+	for _, row := range report.Rows {
+		switch row.Status {
+		case StatusCreated:
+			report.Created++
+		case StatusUpdated:
+			report.Updated++
+		case StatusSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	report.Total = len(report.Rows)
+	log.Info("import finished",
+		slog.Int("total", report.Total),
+		slog.Int("created", report.Created),
+		slog.Int("updated", report.Updated),
+		slog.Int("skipped", report.Skipped),
+		slog.Int("failed", report.Failed),
+	)
+	return report, nil
+}
+
+// read sends every row to ready, enriching rows in the background, and
+// closes ready once all of them are sent.
+func (im *Importer) read(ctx context.Context, rows rowReader, opts Options, ready chan<- pending) error {
+	var wg sync.WaitGroup
+	defer close(ready)
+	defer wg.Wait()
+
+	send := func(p pending) bool {
+		select {
+		case ready <- p:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	sem := make(chan struct{}, im.cfg.Concurrency)
+	seen := make(map[[2]string]int)
+
+	for {
+		line, row, rowErr, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		p := pending{result: RowResult{Line: line, Group: row.Group, Song: row.Song}}
+		if rowErr != nil {
+			p.fail(rowErr)
+			send(p)
+			continue
+		}
+		key := [2]string{storage.NormalizeName(row.Group), storage.NormalizeName(row.Song)}
+		if first, ok := seen[key]; ok {
+			p.result.Status = StatusSkipped
+			p.result.Code = CodeDuplicateRow
+			p.result.Error = "The song is already on line " + strconv.Itoa(first)
+			p.done = true
+			send(p)
+			continue
+		}
+		seen[key] = line
+
+		p.song = storage.Song{
+			GroupName:   row.Group,
+			SongName:    row.Song,
+			ReleaseDate: row.ReleaseDate,
+			Lyrics:      row.Lyrics,
+			YoutubeLink: row.YoutubeLink,
+		}
+		if !opts.Enrich || (p.song.ReleaseDate != "" && p.song.Lyrics != "" && p.song.YoutubeLink != "") {
+			if !send(p) {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			im.enrich(ctx, &p)
+			send(p)
+		}()
+	}
+}
+
+// enrich fills the missing fields of the song from the details provider or
+// fails the row.
+func (im *Importer) enrich(ctx context.Context, p *pending) {
+	detail, err := im.provider.SongDetails(ctx, p.song.GroupName, p.song.SongName)
+	if err != nil {
+		im.log.Warn("failed to fetch song details", slog.Int("line", p.result.Line), slog.Any("error", err))
+		p.fail(err)
+		return
+	}
+	if p.song.ReleaseDate == "" && detail.ReleaseDate != "" {
+		date, err := storage.FormatReleaseDate(detail.ReleaseDate)
+		if err != nil {
+			p.fail(fmt.Errorf("%w: %v", details.ErrUnavailable, err))
+			return
+		}
+		p.song.ReleaseDate = date
+	}
+	if p.song.Lyrics == "" {
+		p.song.Lyrics = detail.Text
+	}
+	if p.song.YoutubeLink == "" {
+		p.song.YoutubeLink = detail.Link
+	}
+}
+
+// flush writes one batch and reports every row of it. A failed batch fails
+// all of its rows so the file can be imported again later.
+func (im *Importer) flush(ctx context.Context, log *slog.Logger, batch []pending, opts Options, finish func(pending)) {
+	songs := make([]storage.Song, len(batch))
+	for i, p := range batch {
+		songs[i] = p.song
+	}
+	results, err := im.store.InsertSongs(ctx, songs, opts.Upsert, opts.Actor)
+	for i, p := range batch {
+		switch {
+		case err != nil:
+			p.result.Status = StatusFailed
+			p.result.Code = problem.CodeInternal
+			p.result.Error = "Failed to store the song"
+		case results[i].ID == 0:
+			p.result.Status = StatusSkipped
+			p.result.Code = problem.CodeSongExists
+			p.result.Error = "The song already exists"
+		case results[i].Created:
+			p.result.Status = StatusCreated
+			p.result.ID = results[i].ID
+		default:
+			p.result.Status = StatusUpdated
+			p.result.ID = results[i].ID
+		}
+		finish(p)
+	}
+	if err != nil {
+		log.Error("failed to store batch", slog.Int("size", len(batch)), slog.Any("error", err))
+	}
+}
+
+// fail reports the row as failed with the code of err.
+func (p *pending) fail(err error) {
+	p.done = true
+	p.result.Status = StatusFailed
+	var errs validate.Errors
+	switch {
+	case errors.As(err, &errs):
+		p.result.Code = problem.CodeValidationFailed
+		p.result.Error = "The row has invalid fields"
+		p.result.Errors = errs
+	case errors.Is(err, validate.ErrInvalidJSON):
+		p.result.Code = problem.CodeInvalidJSON
+		p.result.Error = "The line is not a valid JSON object"
+	case errors.Is(err, details.ErrBadRequest):
+		p.result.Code = problem.CodeUpstreamRejected
+		p.result.Error = "The music info API rejected the song"
+	case errors.Is(err, details.ErrUnavailable), errors.Is(err, context.DeadlineExceeded):
+		p.result.Code = problem.CodeUpstreamUnavailable
+		p.result.Error = "The music info API is unavailable"
+	default:
+		p.result.Code = CodeInvalidRow
+		p.result.Error = err.Error()
+	}
+}
@@ -0,0 +1,240 @@
+package importer_test
+
+import (
+	"context"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/services/details"
+	"effective-mobile/internal/services/importer"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/memory"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// batches records the size of every InsertSongs call.
+type batches struct {
+	storage.SongStore
+	sizes []int
+}
+
+func (b *batches) InsertSongs(ctx context.Context, songs []storage.Song, upsert bool, actor storage.Actor) ([]storage.SongBatchResult, error) {
+	b.sizes = append(b.sizes, len(songs))
+	return b.SongStore.InsertSongs(ctx, songs, upsert, actor)
+}
+
+// provider answers with the same details for every song, or with the error
+// set for the song.
+type provider struct {
+	mu     sync.Mutex
+	errs   map[string]error
+	called []string
+}
+
+func (p *provider) SongDetails(_ context.Context, group string, song string) (details.SongDetail, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.called = append(p.called, song)
+	if err := p.errs[song]; err != nil {
+		return details.SongDetail{}, err
+	}
+	return details.SongDetail{ReleaseDate: "16.07.2006", Text: "fetched", Link: "https://youtu.be/fetched"}, nil
+}
+
+func ndjson(rows ...string) io.Reader {
+	return strings.NewReader(strings.Join(rows, "\n") + "\n")
+}
+
+// statuses lists the line, status and code of every row of the report.
+func statuses(report importer.Report) []string {
+	var got []string
+	for _, row := range report.Rows {
+		got = append(got, fmt.Sprintf("%d %s %s", row.Line, row.Status, row.Code))
+	}
+	return got
+}
+
+func songNames(t *testing.T, store storage.SongStore) []string {
+	t.Helper()
+	songs, _, err := store.SelectSongs(context.Background(), storage.SongFilter{}, storage.Page{})
+	if err != nil {
+		t.Fatalf("SelectSongs: %v", err)
+	}
+	var names []string
+	for _, song := range songs {
+		names = append(names, song.SongName)
+	}
+	return names
+}
+
+func TestImportRows(t *testing.T) {
+	store := memory.New()
+	im := importer.New(discard, store, &provider{}, importer.Config{})
+
+	report, err := im.Import(context.Background(), ndjson(
+		`{"group":"Muse","song":"Uprising"}`,
+		`{"group":"muse","song":" UPRISING "}`,
+		`{"group":"Muse"}`,
+		`not json`,
+		`{"group":"Muse","song":"Resistance"}`,
+	), importer.FormatNDJSON, importer.Options{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	want := []string{
+		"1 created ",
+		"2 skipped " + importer.CodeDuplicateRow,
+		"3 failed " + problem.CodeValidationFailed,
+		"4 failed " + problem.CodeInvalidJSON,
+		"5 created ",
+	}
+	if got := statuses(report); !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+	if report.Total != 5 || report.Created != 2 || report.Skipped != 1 || report.Failed != 2 {
+		t.Errorf("report counts %d total, %d created, %d skipped, %d failed; want 5, 2, 1, 2",
+			report.Total, report.Created, report.Skipped, report.Failed)
+	}
+	if got := report.Rows[1].Error; !strings.Contains(got, "line 1") {
+		t.Errorf("duplicate row error = %q, want it to name line 1", got)
+	}
+	if got, want := songNames(t, store), []string{"Uprising", "Resistance"}; !slices.Equal(got, want) {
+		t.Errorf("stored songs = %q, want %q", got, want)
+	}
+}
+
+func TestImportExisting(t *testing.T) {
+	tests := []struct {
+		name   string
+		upsert bool
+		status string
+		lyrics string
+	}{
+		{name: "skip", upsert: false, status: importer.StatusSkipped, lyrics: "old"},
+		{name: "upsert", upsert: true, status: importer.StatusUpdated, lyrics: "new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.New()
+			id, err := store.InsertSong(context.Background(), storage.Song{GroupName: "Muse", SongName: "Uprising", Lyrics: "old"}, storage.Actor{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			im := importer.New(discard, store, &provider{}, importer.Config{})
+
+			report, err := im.Import(context.Background(), ndjson(
+				`{"group":"Muse","song":"Uprising","lyrics":"new"}`,
+			), importer.FormatNDJSON, importer.Options{Upsert: tt.upsert})
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			row := report.Rows[0]
+			if row.Status != tt.status {
+				t.Errorf("status = %s, want %s", row.Status, tt.status)
+			}
+			if tt.upsert && row.ID != id {
+				t.Errorf("id = %d, want %d", row.ID, id)
+			}
+			if !tt.upsert && row.Code != problem.CodeSongExists {
+				t.Errorf("code = %s, want %s", row.Code, problem.CodeSongExists)
+			}
+			song, err := store.GetSong(context.Background(), id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if song.Lyrics != tt.lyrics {
+				t.Errorf("lyrics = %q, want %q", song.Lyrics, tt.lyrics)
+			}
+		})
+	}
+}
+
+func TestImportBatches(t *testing.T) {
+	store := &batches{SongStore: memory.New()}
+	im := importer.New(discard, store, &provider{}, importer.Config{BatchSize: 2})
+
+	var rows []string
+	for i := 1; i <= 5; i++ {
+		rows = append(rows, fmt.Sprintf(`{"group":"Muse","song":"Song %d"}`, i))
+	}
+	// The duplicate is reported without reaching a batch.
+	rows = append(rows, `{"group":"Muse","song":"Song 1"}`)
+	report, err := im.Import(context.Background(), ndjson(rows...), importer.FormatNDJSON, importer.Options{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	if want := []int{2, 2, 1}; !slices.Equal(store.sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", store.sizes, want)
+	}
+	if report.Created != 5 || report.Skipped != 1 {
+		t.Errorf("report counts %d created, %d skipped; want 5, 1", report.Created, report.Skipped)
+	}
+	for i, row := range report.Rows {
+		if row.Line != i+1 {
+			t.Errorf("row %d is line %d, want the rows in file order", i, row.Line)
+		}
+	}
+}
+
+func TestImportEnrichment(t *testing.T) {
+	store := memory.New()
+	p := &provider{errs: map[string]error{
+		"Unavailable": fmt.Errorf("%w: 503", details.ErrUnavailable),
+		"Rejected":    fmt.Errorf("%w: 400", details.ErrBadRequest),
+	}}
+	im := importer.New(discard, store, p, importer.Config{BatchSize: 2, Concurrency: 2})
+
+	report, err := im.Import(context.Background(), ndjson(
+		`{"group":"Muse","song":"Complete","releaseDate":"2009-09-07","lyrics":"own","youtubeLink":"https://youtu.be/own"}`,
+		`{"group":"Muse","song":"Unavailable"}`,
+		`{"group":"Muse","song":"Partial","lyrics":"own"}`,
+		`{"group":"Muse","song":"Rejected"}`,
+		`{"group":"Muse","song":"Missing"}`,
+	), importer.FormatNDJSON, importer.Options{Enrich: true})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	want := []string{
+		"1 created ",
+		"2 failed " + problem.CodeUpstreamUnavailable,
+		"3 created ",
+		"4 failed " + problem.CodeUpstreamRejected,
+		"5 created ",
+	}
+	if got := statuses(report); !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+	if slices.Contains(p.called, "Complete") {
+		t.Error("the provider was called for a complete row")
+	}
+
+	songs, _, err := store.SelectSongs(context.Background(), storage.SongFilter{}, storage.Page{})
+	if err != nil {
+		t.Fatalf("SelectSongs: %v", err)
+	}
+	got := make(map[string]storage.Song)
+	for _, song := range songs {
+		got[song.SongName] = song
+	}
+	if len(got) != 3 {
+		t.Fatalf("stored %d songs, want 3", len(got))
+	}
+	if song := got["Complete"]; song.Lyrics != "own" || song.ReleaseDate != "2009-09-07" {
+		t.Errorf("Complete = %+v, want its own details", song)
+	}
+	if song := got["Partial"]; song.Lyrics != "own" || song.ReleaseDate != "2006-07-16" || song.YoutubeLink != "https://youtu.be/fetched" {
+		t.Errorf("Partial = %+v, want its lyrics and the fetched date and link", song)
+	}
+	if song := got["Missing"]; song.Lyrics != "fetched" {
+		t.Errorf("Missing = %+v, want the fetched lyrics", song)
+	}
+}
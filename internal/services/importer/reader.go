@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"effective-mobile/internal/http-server/validate"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat accepts a format name or the media type of a file.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "csv", "text/csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl", "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("%w %q, expected csv or ndjson", ErrUnknownFormat, s)
+	}
+}
+
+// Row is one song of an import file. CSV files name the same columns in a
+// header row; only group and song are required. The IDs and the version
+// written by GET /songs/export are accepted and ignored.
+type Row struct {
+	ID          uint   `json:"id,omitempty"`
+	GroupID     uint   `json:"groupId,omitempty"`
+	Version     int    `json:"version,omitempty"`
+	Group       string `json:"group" validate:"required,max=255"`
+	Song        string `json:"song" validate:"required,max=255"`
+	ReleaseDate string `json:"releaseDate,omitempty" validate:"date=2006-01-02"`
+	Lyrics      string `json:"lyrics,omitempty"`
+	YoutubeLink string `json:"youtubeLink,omitempty" validate:"max=255,youtube"`
+}
+
+// maxLineSize bounds one NDJSON line, which is mostly lyrics.
+const maxLineSize = 1 << 20
+
+// rowReader reads rows one at a time. next returns the line of the row and
+// either the row or a row error; a non-nil fatal error ends the file.
+type rowReader interface {
+	next() (line int, row Row, rowErr error, fatal error)
+}
+
+func newRowReader(r io.Reader, format Format) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+var csvColumns = []string{"id", "groupId", "group", "song", "releaseDate", "lyrics", "youtubeLink"}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the file is empty", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		for _, column := range csvColumns {
+			if strings.EqualFold(name, column) {
+				columns[i] = column
+			}
+		}
+		if columns[i] == "" {
+			return nil, fmt.Errorf("%w: unknown column %q, expected %s", ErrInvalidFile, name, strings.Join(csvColumns[2:], ", "))
+		}
+		if seen[columns[i]] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidFile, name)
+		}
+		seen[columns[i]] = true
+	}
+	if !seen["group"] || !seen["song"] {
+		return nil, fmt.Errorf("%w: the group and song columns are required", ErrInvalidFile)
+	}
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) next() (int, Row, error, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, Row{}, fmt.Errorf("invalid CSV: %v", parseErr.Err), nil
+		}
+		return 0, Row{}, nil, err
+	}
+	line, _ := c.r.FieldPos(0)
+
+	var row Row
+	for i, value := range record {
+		value = unescapeCSV(value)
+		switch c.columns[i] {
+		case "group":
+			row.Group = value
+		case "song":
+			row.Song = value
+		case "releaseDate":
+			row.ReleaseDate = value
+		case "lyrics":
+			row.Lyrics = value
+		case "youtubeLink":
+			row.YoutubeLink = value
+		}
+	}
+	return line, row, validate.Struct(row), nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonReader) next() (int, Row, error, error) {
+	for n.scanner.Scan() {
+		n.line++
+		text := bytes.TrimSpace(n.scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var row Row
+		if err := validate.Unmarshal(text, &row); err != nil {
+			return n.line, row, err, nil
+		}
+		return n.line, row, nil, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return n.line + 1, Row{}, nil, fmt.Errorf("%w: line %d is longer than %d bytes", ErrInvalidFile, n.line+1, maxLineSize)
+		}
+		return 0, Row{}, nil, err
+	}
+	return 0, Row{}, nil, io.EOF
+}
+
+// EscapeCSV guards a CSV cell against formula injection: a spreadsheet runs
+// a cell starting with =, +, -, @, a tab or a carriage return as a formula,
+// so such a cell gets a leading apostrophe. A cell that would read as
+// escaped, like '=x, gets one as well, so the CSV reader can undo every
+// escape exactly.
+func EscapeCSV(value string) string {
+	if escaped(value) {
+		return "'" + value
+	}
+	return value
+}
+
+func unescapeCSV(value string) string {
+	if rest, ok := strings.CutPrefix(value, "'"); ok && escaped(rest) {
+		return rest
+	}
+	return value
+}
+
+// escaped reports whether EscapeCSV adds an apostrophe to value.
+func escaped(value string) bool {
+	for {
+		if value == "" {
+			return false
+		}
+		if strings.IndexByte("=+-@\t\r", value[0]) >= 0 {
+			return true
+		}
+		rest, ok := strings.CutPrefix(value, "'")
+		if !ok {
+			return false
+		}
+		value = rest
+	}
+}
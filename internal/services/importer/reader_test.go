@@ -0,0 +1,245 @@
+package importer
+
+import (
+	"effective-mobile/internal/http-server/validate"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// read is one result of rowReader.next.
+type read struct {
+	line int
+	row  Row
+	err  error
+}
+
+func readAll(t *testing.T, rows rowReader) []read {
+	t.Helper()
+	var reads []read
+	for {
+		line, row, rowErr, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			return reads
+		}
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		reads = append(reads, read{line: line, row: row, err: rowErr})
+	}
+}
+
+func TestReaderColumns(t *testing.T) {
+	muse := Row{Group: "Muse", Song: "Uprising", ReleaseDate: "2009-09-07", Lyrics: "one\n\ntwo", YoutubeLink: "https://youtu.be/w8KQmps-Sog"}
+	placebo := Row{Group: "Placebo", Song: "Every You Every Me"}
+	tests := []struct {
+		name   string
+		format Format
+		file   string
+		want   []Row
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			file: "group,song,releaseDate,lyrics,youtubeLink\n" +
+				"Muse,Uprising,2009-09-07,\"one\n\ntwo\",https://youtu.be/w8KQmps-Sog\n" +
+				"Placebo,Every You Every Me,,,\n",
+			want: []Row{muse, placebo},
+		},
+		{
+			name:   "csv in another order with export ids",
+			format: FormatCSV,
+			file: "\ufeffYoutubeLink, Song ,id,groupId,lyrics,GROUP,releaseDate\n" +
+				"https://youtu.be/w8KQmps-Sog,Uprising,7,3,\"one\n\ntwo\",Muse,2009-09-07\n" +
+				",Every You Every Me,8,4,,Placebo,\n",
+			want: []Row{muse, placebo},
+		},
+		{
+			name:   "csv with only the required columns",
+			format: FormatCSV,
+			file:   "song,group\nEvery You Every Me,Placebo\n",
+			want:   []Row{placebo},
+		},
+		{
+			name:   "ndjson",
+			format: FormatNDJSON,
+			file: `{"id":7,"groupId":3,"group":"Muse","song":"Uprising","releaseDate":"2009-09-07","lyrics":"one\n\ntwo","youtubeLink":"https://youtu.be/w8KQmps-Sog"}` + "\n\n" +
+				`{"group":"Placebo","song":"Every You Every Me"}`,
+			want: []Row{muse, placebo},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := newRowReader(strings.NewReader(tt.file), tt.format)
+			if err != nil {
+				t.Fatalf("newRowReader: %v", err)
+			}
+			reads := readAll(t, rows)
+			if len(reads) != len(tt.want) {
+				t.Fatalf("read %d rows, want %d", len(reads), len(tt.want))
+			}
+			for i, got := range reads {
+				if got.err != nil {
+					t.Errorf("row %d: %v", i, got.err)
+				}
+				got.row.ID, got.row.GroupID = 0, 0
+				if got.row != tt.want[i] {
+					t.Errorf("row %d = %+v, want %+v", i, got.row, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReaderInvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "empty", file: ""},
+		{name: "unknown column", file: "group,song,rating\nMuse,Uprising,5\n"},
+		{name: "duplicate column", file: "group,song,Song\nMuse,Uprising,Uprising\n"},
+		{name: "missing song column", file: "group,lyrics\nMuse,one\n"},
+		{name: "broken header", file: "group,\"song\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRowReader(strings.NewReader(tt.file), FormatCSV)
+			if !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidFile)
+			}
+		})
+	}
+
+	t.Run("ndjson line too long", func(t *testing.T) {
+		file := `{"group":"Muse","song":"Uprising"}` + "\n" + strings.Repeat("x", maxLineSize+1) + "\n"
+		rows, err := newRowReader(strings.NewReader(file), FormatNDJSON)
+		if err != nil {
+			t.Fatalf("newRowReader: %v", err)
+		}
+		if _, _, _, err := rows.next(); err != nil {
+			t.Fatalf("first line: %v", err)
+		}
+		if _, _, _, err := rows.next(); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("error = %v, want %v", err, ErrInvalidFile)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := newRowReader(strings.NewReader(""), "xml"); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("error = %v, want %v", err, ErrUnknownFormat)
+		}
+	})
+}
+
+func TestReaderRowErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		file   string
+		// lines are the lines of the rows that fail, in order.
+		lines []int
+		// code is the code of the first field error, if the rows fail
+		// validation.
+		code string
+	}{
+		{
+			name:   "csv missing song",
+			format: FormatCSV,
+			file:   "group,song\nMuse,Uprising\nMuse,\nPlacebo,Pure Morning\n",
+			lines:  []int{3},
+			code:   validate.CodeRequired,
+		},
+		{
+			name:   "csv rows after a multiline field",
+			format: FormatCSV,
+			file:   "group,song,lyrics\nMuse,Uprising,\"one\n\ntwo\"\nMuse,Resistance,\nPlacebo,Pure Morning,\n,Bad,\n",
+			lines:  []int{7},
+			code:   validate.CodeRequired,
+		},
+		{
+			name:   "csv invalid date",
+			format: FormatCSV,
+			file:   "group,song,releaseDate\nMuse,Uprising,07.09.2009\n",
+			lines:  []int{2},
+			code:   validate.CodeInvalidDate,
+		},
+		{
+			name:   "csv wrong field count",
+			format: FormatCSV,
+			file:   "group,song\nMuse,Uprising\nMuse,Resistance,extra\n",
+			lines:  []int{3},
+		},
+		{
+			name:   "ndjson invalid json",
+			format: FormatNDJSON,
+			file:   "{\"group\":\"Muse\",\"song\":\"Uprising\"}\n\n{\"group\":\n",
+			lines:  []int{3},
+		},
+		{
+			name:   "ndjson unknown field",
+			format: FormatNDJSON,
+			file:   "{\"group\":\"Muse\",\"song\":\"Uprising\",\"rating\":5}\n",
+			lines:  []int{1},
+			code:   validate.CodeUnknownField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := newRowReader(strings.NewReader(tt.file), tt.format)
+			if err != nil {
+				t.Fatalf("newRowReader: %v", err)
+			}
+			var lines []int
+			var firstErr error
+			for _, got := range readAll(t, rows) {
+				if got.err != nil {
+					lines = append(lines, got.line)
+					if firstErr == nil {
+						firstErr = got.err
+					}
+				}
+			}
+			if !slices.Equal(lines, tt.lines) {
+				t.Fatalf("failed lines = %v, want %v", lines, tt.lines)
+			}
+			if tt.code == "" {
+				return
+			}
+			var errs validate.Errors
+			if !errors.As(firstErr, &errs) || len(errs) == 0 || errs[0].Code != tt.code {
+				t.Errorf("error = %v, want a field error with code %s", firstErr, tt.code)
+			}
+		})
+	}
+}
+
+func TestEscapeCSV(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Muse", want: "Muse"},
+		{value: "'Til Tuesday", want: "'Til Tuesday"},
+		{value: "=1+1", want: "'=1+1"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\tindented", want: "'\tindented"},
+		{value: "\rline", want: "'\rline"},
+		{value: "'=1+1", want: "''=1+1"},
+		{value: "''-1", want: "'''-1"},
+	}
+	for _, tt := range tests {
+		got := EscapeCSV(tt.value)
+		if got != tt.want {
+			t.Errorf("EscapeCSV(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if back := unescapeCSV(got); back != tt.value {
+			t.Errorf("unescapeCSV(%q) = %q, want %q", got, back, tt.value)
+		}
+	}
+}
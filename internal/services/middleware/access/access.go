@@ -0,0 +1,81 @@
+package access
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/services/auth"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// New authenticates every request that carries credentials and stores the
+// caller in the request context. Requests without credentials pass through
+// anonymously; routes that need a caller are wrapped with Require.
+func New(log *slog.Logger, authenticator *auth.Authenticator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "middleware/access"),
+		)
+
+		log.Info("access middleware enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				if errors.Is(err, auth.ErrNoCredentials) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				log.Warn("authentication failed",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Any("error", err),
+				)
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				problem.Error(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid credentials")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Require lets through only callers whose role allows the given one.
+func Require(log *slog.Logger, role auth.Role) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "middleware/access"),
+			slog.String("role", string(role)),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				problem.Error(w, r, http.StatusUnauthorized, problem.CodeUnauthorized, "Authentication required")
+				log.Warn("anonymous request to a protected route",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+				return
+			}
+			if !principal.Role.Allows(role) {
+				problem.Error(w, r, http.StatusForbidden, problem.CodeForbidden, "The "+string(role)+" role is required")
+				log.Warn("insufficient role",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("subject", principal.Subject),
+					slog.String("subject_role", string(principal.Role)),
+				)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
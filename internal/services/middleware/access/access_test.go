@@ -0,0 +1,67 @@
+package access_test
+
+import (
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/services/middleware/access"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccess(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	authenticator, err := auth.New(auth.Config{APIKeys: []auth.APIKey{
+		{Key: "k-reader", Subject: "reader", Role: "reader"},
+		{Key: "k-editor", Subject: "editor", Role: "editor"},
+	}})
+	if err != nil {
+		t.Fatalf("auth.New: %v", err)
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, auth.Subject(r.Context()))
+	})
+	// Reads are not public, as with AUTH_PUBLIC_READS=false.
+	mux := http.NewServeMux()
+	mux.Handle("GET /songs", access.Require(log, auth.RoleReader)(ok))
+	mux.Handle("POST /songs", access.Require(log, auth.RoleEditor)(ok))
+	mux.Handle("GET /open", ok)
+	handler := access.New(log, authenticator)(mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		apiKey     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"anonymous read", http.MethodGet, "/songs", "", http.StatusUnauthorized, ""},
+		{"reader read", http.MethodGet, "/songs", "k-reader", http.StatusOK, "reader"},
+		{"reader write", http.MethodPost, "/songs", "k-reader", http.StatusForbidden, ""},
+		{"editor write", http.MethodPost, "/songs", "k-editor", http.StatusOK, "editor"},
+		{"unknown key", http.MethodGet, "/open", "k-unknown", http.StatusUnauthorized, ""},
+		{"anonymous open route", http.MethodGet, "/open", "", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.apiKey != "" {
+				r.Header.Set(auth.APIKeyHeader, tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body, tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
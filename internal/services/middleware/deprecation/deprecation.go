@@ -0,0 +1,30 @@
+package deprecation
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// New marks the responses of a deprecated route with the Deprecation header
+// and links the route that replaces it.
+func New(log *slog.Logger, successor string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "middleware/deprecation"),
+			slog.String("successor", successor),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log.Warn("deprecated route called",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			)
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,187 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/problem"
+	"effective-mobile/internal/http-server/recorder"
+	"effective-mobile/internal/http-server/validate"
+	"effective-mobile/internal/services/auth"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// Header carries the client-chosen key of a request.
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks a response that was replayed from the cache.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// Cache remembers the responses of requests sent with an Idempotency-Key
+// for a while. Keys are scoped to the caller, method and path.
+type Cache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+type entry struct {
+	fingerprint [sha256.Size]byte
+	done        bool
+	expires     time.Time
+
+	status int
+	header http.Header
+	body   []byte
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: make(map[string]*entry)}
+}
+
+// New replays the stored response when a request is retried with the same
+// Idempotency-Key, query and body. A retry that arrives while the first
+// request is still running gets 409, and reusing a key with a different query
+// or body gets 422. Replays keep the X-Request-ID of the retry.
+// Server errors are not stored, so the client can retry them. Requests
+// without the header pass through.
+func New(log *slog.Logger, cache *Cache) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "middleware/idempotency"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(Header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				problem.Write(w, r, problem.Validation(validate.FieldError{
+					Field:   Header,
+					Code:    validate.CodeTooLong,
+					Message: "must be at most 255 characters",
+				}))
+				return
+			}
+
+			// The query and body are hashed to tell a retry from a different request;
+			// an oversized body is left for the handler to reject.
+			body, err := io.ReadAll(io.LimitReader(r.Body, validate.MaxBodySize+1))
+			if err != nil {
+				problem.Error(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Failed to read the request body")
+				log.Error("failed to read body", slog.Any("error", err))
+				return
+			}
+			if len(body) > validate.MaxBodySize {
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+				next.ServeHTTP(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := auth.Subject(r.Context()) + " " + r.Method + " " + r.URL.Path + " " + key
+			h := sha256.New()
+			io.WriteString(h, r.URL.RawQuery)
+			h.Write([]byte{0})
+			h.Write(body)
+			var fingerprint [sha256.Size]byte
+			h.Sum(fingerprint[:0])
+
+			e, fresh := cache.begin(scope, fingerprint)
+			if !fresh {
+				switch {
+				case e.fingerprint != fingerprint:
+					problem.Error(w, r, http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused, "The Idempotency-Key was already used for a different request")
+					log.Warn("idempotency key reused", slog.String("key", key))
+				case !e.done:
+					problem.Error(w, r, http.StatusConflict, problem.CodeIdempotencyKeyInUse, "A request with this Idempotency-Key is still being processed")
+					log.Warn("idempotency key in use", slog.String("key", key))
+				default:
+					log.Info("replaying response", slog.String("key", key), slog.Int("status", e.status))
+					for k, v := range e.header {
+						w.Header()[k] = v
+					}
+					w.Header().Set(ReplayedHeader, "true")
+					w.WriteHeader(e.status)
+					w.Write(e.body)
+				}
+				return
+			}
+
+			rec := recorder.New(w)
+			rec.Capture()
+			defer func() {
+				if p := recover(); p != nil {
+					cache.forget(scope)
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			// Server errors leave the key free for a retry.
+			if rec.Status() >= http.StatusInternalServerError {
+				cache.forget(scope)
+				return
+			}
+			// A replay answers a request of its own, with its own ID.
+			header := rec.SentHeader()
+			header.Del(audit.RequestIDHeader)
+			cache.finish(scope, rec.Status(), header, rec.Body())
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// begin returns the entry of the scope and false, or registers a new
+// in-progress entry and returns true.
+func (c *Cache) begin(scope string, fingerprint [sha256.Size]byte) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastSweep) > time.Minute {
+		for k, e := range c.entries {
+			if e.done && now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	if e, ok := c.entries[scope]; ok && (!e.done || now.Before(e.expires)) {
+		// finish changes the entry under the lock, so hand out a copy.
+		copied := *e
+		return &copied, false
+	}
+	c.entries[scope] = &entry{fingerprint: fingerprint}
+	return nil, true
+}
+
+func (c *Cache) finish(scope string, status int, header http.Header, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[scope]
+	if !ok {
+		return
+	}
+	e.done = true
+	e.expires = time.Now().Add(c.ttl)
+	e.status = status
+	e.header = header
+	e.body = body
+}
+
+func (c *Cache) forget(scope string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, scope)
+}
@@ -0,0 +1,171 @@
+package idempotency_test
+
+import (
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/services/auth"
+	"effective-mobile/internal/services/middleware/idempotency"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// songs answers every call with a new song, or with status when it is set.
+type songs struct {
+	calls  atomic.Int32
+	status int
+}
+
+func (s *songs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := s.calls.Add(1)
+	if s.status != 0 {
+		http.Error(w, "failed", s.status)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/songs/%d", n))
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"id":%d}`, n)
+}
+
+type request struct {
+	subject string
+	target  string
+	body    string
+	key     string
+}
+
+func send(h http.Handler, req request) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, req.target, strings.NewReader(req.body))
+	if req.key != "" {
+		r.Header.Set(idempotency.Header, req.key)
+	}
+	r.Header.Set(audit.RequestIDHeader, fmt.Sprint(time.Now().UnixNano()))
+	if req.subject != "" {
+		r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{Subject: req.subject, Role: auth.RoleEditor}))
+	}
+	w := httptest.NewRecorder()
+	w.Header().Set(audit.RequestIDHeader, r.Header.Get(audit.RequestIDHeader))
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestReplay(t *testing.T) {
+	next := &songs{}
+	h := idempotency.New(discard, idempotency.NewCache(time.Hour))(next)
+	req := request{subject: "alice", target: "/songs", body: `{"group":"Muse","song":"Uprising"}`, key: "k1"}
+
+	first := send(h, req)
+	retry := send(h, req)
+	if next.calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", next.calls.Load())
+	}
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if got := retry.Header().Get("Location"); got != "/songs/1" {
+		t.Errorf("replayed Location = %q, want %q", got, "/songs/1")
+	}
+	if retry.Header().Get(idempotency.ReplayedHeader) != "true" || first.Header().Get(idempotency.ReplayedHeader) != "" {
+		t.Errorf("%s set on the wrong response", idempotency.ReplayedHeader)
+	}
+	if retry.Header().Get(audit.RequestIDHeader) == first.Header().Get(audit.RequestIDHeader) {
+		t.Error("replay took over the request ID of the first request")
+	}
+
+	if w := send(h, request{subject: "alice", target: "/songs", body: req.body}); w.Code != http.StatusCreated || next.calls.Load() != 2 {
+		t.Errorf("request without a key: %d after %d calls, want a new song", w.Code, next.calls.Load())
+	}
+}
+
+func TestKeyReuse(t *testing.T) {
+	tests := []struct {
+		name      string
+		retry     request
+		wantCode  int
+		wantCalls int32
+	}{
+		{"different body", request{subject: "alice", target: "/songs", body: `{"song":"Resistance"}`, key: "k1"}, http.StatusUnprocessableEntity, 1},
+		{"different query", request{subject: "alice", target: "/songs?upsert=true", body: `{"song":"Uprising"}`, key: "k1"}, http.StatusUnprocessableEntity, 1},
+		{"different subject", request{subject: "bob", target: "/songs", body: `{"song":"Uprising"}`, key: "k1"}, http.StatusCreated, 2},
+		{"different path", request{subject: "alice", target: "/albums", body: `{"song":"Uprising"}`, key: "k1"}, http.StatusCreated, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &songs{}
+			h := idempotency.New(discard, idempotency.NewCache(time.Hour))(next)
+			send(h, request{subject: "alice", target: "/songs", body: `{"song":"Uprising"}`, key: "k1"})
+
+			w := send(h, tt.retry)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if calls := next.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestInProgress(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	h := idempotency.New(discard, idempotency.NewCache(time.Hour))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+	req := request{target: "/songs", body: `{}`, key: "k1"}
+
+	done := make(chan int)
+	go func() { done <- send(h, req).Code }()
+	<-entered
+	if w := send(h, req); w.Code != http.StatusConflict {
+		t.Errorf("retry while running: status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	close(release)
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("first request: status = %d, want %d", code, http.StatusCreated)
+	}
+	if w := send(h, req); w.Code != http.StatusCreated || w.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Errorf("retry after the first request: status = %d, want a replayed %d", w.Code, http.StatusCreated)
+	}
+}
+
+func TestServerErrorsNotStored(t *testing.T) {
+	next := &songs{status: http.StatusServiceUnavailable}
+	h := idempotency.New(discard, idempotency.NewCache(time.Hour))(next)
+	req := request{target: "/songs", body: `{}`, key: "k1"}
+
+	send(h, req)
+	next.status = 0
+	if w := send(h, req); w.Code != http.StatusCreated || next.calls.Load() != 2 {
+		t.Errorf("retry after a 5xx: status = %d after %d calls, want %d after 2", w.Code, next.calls.Load(), http.StatusCreated)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	const ttl = 20 * time.Millisecond
+	next := &songs{}
+	h := idempotency.New(discard, idempotency.NewCache(ttl))(next)
+	req := request{target: "/songs", body: `{}`, key: "k1"}
+
+	send(h, req)
+	time.Sleep(2 * ttl)
+	if w := send(h, req); w.Header().Get(idempotency.ReplayedHeader) != "" || next.calls.Load() != 2 {
+		t.Errorf("retry after the TTL was replayed, handler called %d times", next.calls.Load())
+	}
+}
+
+func TestKeyTooLong(t *testing.T) {
+	next := &songs{}
+	h := idempotency.New(discard, idempotency.NewCache(time.Hour))(next)
+	if w := send(h, request{target: "/songs", body: `{}`, key: strings.Repeat("k", 256)}); w.Code != http.StatusBadRequest || next.calls.Load() != 0 {
+		t.Errorf("status = %d after %d calls, want %d before any", w.Code, next.calls.Load(), http.StatusBadRequest)
+	}
+}
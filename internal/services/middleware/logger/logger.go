@@ -1,37 +1,101 @@
 package logger
 
 import (
+	crand "crypto/rand"
+	"effective-mobile/internal/http-server/audit"
+	"effective-mobile/internal/http-server/recorder"
+	"effective-mobile/internal/lib/logctx"
+	"encoding/hex"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
-func New(log *slog.Logger) func(next http.Handler) http.Handler {
+// maxRequestIDLength bounds the X-Request-ID a client may choose.
+const maxRequestIDLength = 128
+
+type Config struct {
+	// SuccessSampleRate is the share of successful requests whose completion
+	// is logged, from 0 to 1. Failed requests are always logged.
+	SuccessSampleRate float64
+}
+
+// New gives every request an ID, taken from a valid X-Request-ID header or
+// generated, and sends it back in the response. It stores a logger that
+// names the request in the context, for handlers and the storage to log
+// with, and logs the completion of the request with its status, size and
+// route. route names the pattern r matches, or "" when there is none.
+func New(log *slog.Logger, cfg Config, route func(r *http.Request) string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		log = log.With(
+		log.Info("logger middleware enabled",
 			slog.String("component", "middleware/logger"),
+			slog.Float64("success_sample_rate", cfg.SuccessSampleRate),
 		)
 
-		log.Info("logger middleware enabled")
-
 		fn := func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(audit.RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+				r.Header.Set(audit.RequestIDHeader, id)
+			}
+			w.Header().Set(audit.RequestIDHeader, id)
+
 			entry := log.With(
+				slog.String("request_id", id),
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
-				slog.String("remote_addr", r.RemoteAddr),
-				slog.String("user_agent", r.UserAgent()),
 			)
+			if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+				entry = entry.With(slog.String("trace_id", span.TraceID().String()))
+			}
 
+			rec := recorder.New(w)
 			t1 := time.Now()
 			defer func() {
-				entry.Info("request completed",
+				status := rec.Status()
+				if status < http.StatusBadRequest && rand.Float64() >= cfg.SuccessSampleRate {
+					return
+				}
+				level := slog.LevelInfo
+				if status >= http.StatusInternalServerError {
+					level = slog.LevelError
+				}
+				entry.Log(r.Context(), level, "request completed",
+					slog.String("route", route(r)),
+					slog.Int("status", status),
+					slog.Int64("bytes", rec.Size()),
 					slog.String("duration", time.Since(t1).String()),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.String("user_agent", r.UserAgent()),
 				)
 			}()
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(rec, r.WithContext(logctx.With(r.Context(), entry)))
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+// validRequestID accepts IDs of printable ASCII without spaces, so a client
+// cannot break log lines or headers with the ID it sends.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = crand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
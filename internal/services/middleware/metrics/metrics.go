@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"effective-mobile/internal/http-server/recorder"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatched labels requests that match no route, so unknown paths cannot
+// blow up the number of series.
+const unmatched = "unmatched"
+
+// New counts and times every request by method, route pattern and status
+// and tracks the requests in flight. route names the pattern r matches, or
+// "" when there is none. The collectors are registered with reg.
+func New(log *slog.Logger, reg prometheus.Registerer, route func(r *http.Request) string) func(next http.Handler) http.Handler {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by method, route pattern and status.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "route", "status"})
+	inFlight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests being served.",
+	})
+	reg.MustRegister(requests, duration, inFlight)
+
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "middleware/metrics"),
+		)
+
+		log.Info("metrics middleware enabled")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			pattern := route(r)
+			if pattern == "" {
+				pattern = unmatched
+			}
+
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			rec := recorder.New(w)
+			start := time.Now()
+			defer func() {
+				labels := prometheus.Labels{
+					"method": r.Method,
+					"route":  pattern,
+					"status": strconv.Itoa(rec.Status()),
+				}
+				requests.With(labels).Inc()
+				duration.With(labels).Observe(time.Since(start).Seconds())
+			}()
+
+			next.ServeHTTP(rec, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,51 @@
+package ready
+
+import (
+	"effective-mobile/internal/http-server/problem"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+)
+
+// Gate is closed while the service waits for its dependencies and opens
+// once they are reachable.
+type Gate struct {
+	open atomic.Bool
+}
+
+// Open lets requests through from now on.
+func (g *Gate) Open() {
+	g.open.Store(true)
+}
+
+// Ready reports whether the gate is open.
+func (g *Gate) Ready() bool {
+	return g.open.Load()
+}
+
+// New answers 503 Service Unavailable with a Retry-After header until gate
+// opens, so requests that arrive during startup fail fast instead of
+// waiting for the database.
+func New(log *slog.Logger, gate *Gate) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log = log.With(
+			slog.String("component", "middleware/ready"),
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !gate.Ready() {
+				log.Warn("request before the service is ready",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+				w.Header().Set("Retry-After", "5")
+				problem.Error(w, r, http.StatusServiceUnavailable, problem.CodeStorageUnavailable, "The service is starting")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
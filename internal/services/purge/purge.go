@@ -0,0 +1,68 @@
+package purge
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Store removes deleted songs for good.
+type Store interface {
+	PurgeSongs(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+type Config struct {
+	// Retention is how long a deleted song stays in the trash.
+	Retention time.Duration
+	// Interval is the time between two purges.
+	Interval time.Duration
+}
+
+// Purger empties the trash of songs deleted longer than Retention ago.
+type Purger struct {
+	log   *slog.Logger
+	store Store
+	cfg   Config
+}
+
+func New(log *slog.Logger, store Store, cfg Config) *Purger {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	return &Purger{
+		log:   log.With(slog.String("component", "services/purge")),
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Run purges the trash right away and then every Interval until ctx is done.
+// A failed purge is logged and retried on the next tick.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		p.Purge(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Purge removes the songs deleted before now minus Retention.
+func (p *Purger) Purge(ctx context.Context) {
+	const op = "services.purge.Purge"
+	log := p.log.With(slog.String("op", op))
+
+	before := time.Now().Add(-p.cfg.Retention)
+	n, err := p.store.PurgeSongs(ctx, before)
+	if err != nil {
+		log.Error("failed to purge deleted songs", slog.Any("error", err))
+		return
+	}
+	if n > 0 {
+		log.Info("deleted songs purged", slog.Int("count", n), slog.Time("deletedBefore", before))
+	}
+}
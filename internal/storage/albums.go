@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrAlbumNotFound = errors.New("album not found")
+	ErrTrackNotFound = errors.New("song is not on the album")
+	ErrTrackExists   = errors.New("song is already on the album")
+	// ErrInvalidTrackOrder is returned when a new track order is not a
+	// permutation of the songs on the album.
+	ErrInvalidTrackOrder = errors.New("track order must list every song of the album exactly once")
+)
+
+type Album struct {
+	ID        uint   `db:"id" json:"id"`
+	Title     string `db:"title" json:"title"`
+	GroupID   uint   `db:"group_id" json:"groupId"`
+	GroupName string `db:"group_name" json:"group"`
+	// ReleaseDate is in the storage format (YYYY-MM-DD).
+	ReleaseDate string `db:"release_date" json:"releaseDate"`
+	CoverURL    string `db:"cover_url" json:"coverUrl"`
+}
+
+// Track is a song on an album. Track numbers start at 1 and have no gaps.
+type Track struct {
+	Number int `db:"track_number" json:"number"`
+	Song
+}
+
+// AlbumStore is implemented by storage backends that keep albums.
+type AlbumStore interface {
+	// InsertAlbum fails with ErrGroupNotFound when the album's group does not exist.
+	InsertAlbum(ctx context.Context, album Album) (uint, error)
+	SelectAlbums(ctx context.Context, limit int, offset int) ([]Album, int, error)
+	GetAlbum(ctx context.Context, id uint) (Album, error)
+	DeleteAlbum(ctx context.Context, id uint) error
+
+	// SelectTracks returns the tracklist ordered by track number.
+	SelectTracks(ctx context.Context, albumID uint) ([]Track, error)
+	// AttachTrack puts the song on the album at the given track number,
+	// moving the following tracks down. Numbers past the end, or 0, append.
+	AttachTrack(ctx context.Context, albumID uint, songID uint, number int) error
+	// DetachTrack removes the song from the album and closes the gap.
+	DetachTrack(ctx context.Context, albumID uint, songID uint) error
+	// ReorderTracks numbers the tracks in the order of songIDs.
+	ReorderTracks(ctx context.Context, albumID uint, songIDs []uint) error
+}
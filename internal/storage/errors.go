@@ -0,0 +1,41 @@
+package storage
+
+import "errors"
+
+// Kinds of storage failures that callers handle apart from the errors of
+// particular entities.
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrExists is a write that breaks a uniqueness constraint.
+	ErrExists = errors.New("already exists")
+	// ErrConnectionLost means the backend could not be reached or dropped the
+	// connection; the call may or may not have taken effect.
+	ErrConnectionLost = errors.New("storage connection lost")
+	// ErrCanceled is a call abandoned because its context was canceled,
+	// usually because the client went away or the server is shutting down.
+	ErrCanceled = errors.New("storage call canceled")
+	// ErrTimeout is a call that ran out of its query timeout or the
+	// deadline of its context.
+	ErrTimeout = errors.New("storage call timed out")
+)
+
+// Error is a failed storage call. Op names the operation and Err is the
+// cause. Kind, when set, is one of the failure kinds above, so both
+// errors.Is(err, ErrTimeout) and errors.Is(err, context.DeadlineExceeded)
+// hold for a timed out query.
+type Error struct {
+	Op   string
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
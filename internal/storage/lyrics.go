@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrVerseOutOfRange is returned for a verse edit past the end of the lyrics.
+	ErrVerseOutOfRange = errors.New("verse out of range")
+	// ErrInvalidVerse is returned for a verse edit with text that is blank or
+	// would split into several verses.
+	ErrInvalidVerse = errors.New("invalid verse")
+)
+
+// VerseError reports the verse edit that could not be applied.
+type VerseError struct {
+	// Edit is the position of the edit in the list, from 0.
+	Edit    int
+	Err     error
+	Message string
+}
+
+func (e *VerseError) Error() string {
+	return fmt.Sprintf("verse edit %d: %v: %s", e.Edit, e.Err, e.Message)
+}
+
+func (e *VerseError) Unwrap() error {
+	return e.Err
+}
+
+// Verse edit operations.
+const (
+	VerseReplace = "replace"
+	VerseInsert  = "insert"
+	VerseDelete  = "delete"
+)
+
+// VerseEdit changes one verse of the lyrics. Verses are numbered from 1 as
+// GET /song/lyrics returns them. Insert puts the text before the verse, or
+// after the last verse when Verse is one past it.
+type VerseEdit struct {
+	Op    string `json:"op" validate:"required,oneof=replace insert delete"`
+	Verse int    `json:"verse" validate:"required,min=1"`
+	// Text is the new verse for replace and insert.
+	Text string `json:"text,omitempty"`
+}
+
+// SplitVerses splits lyrics into verses; empty lyrics have none.
+func SplitVerses(lyrics string) []string {
+	if lyrics == "" {
+		return nil
+	}
+	return strings.Split(lyrics, VerseSeparator)
+}
+
+// EditVerses applies the edits to the lyrics in order, so every edit sees
+// the verses as the edits before it left them. It fails with a *VerseError.
+func EditVerses(lyrics string, edits []VerseEdit) (string, error) {
+	verses := SplitVerses(lyrics)
+	for i, edit := range edits {
+		if edit.Op != VerseDelete {
+			if strings.TrimSpace(edit.Text) == "" || strings.Contains(edit.Text, VerseSeparator) {
+				return "", &VerseError{Edit: i, Err: ErrInvalidVerse, Message: "the text must be one verse without blank lines"}
+			}
+		}
+		last := len(verses)
+		if edit.Op == VerseInsert {
+			last++
+		}
+		if edit.Verse < 1 || edit.Verse > last {
+			return "", &VerseError{Edit: i, Err: ErrVerseOutOfRange, Message: fmt.Sprintf("there is no verse %d, the lyrics have %d verses", edit.Verse, len(verses))}
+		}
+		n := edit.Verse - 1
+		switch edit.Op {
+		case VerseReplace:
+			verses[n] = edit.Text
+		case VerseInsert:
+			verses = append(verses[:n], append([]string{edit.Text}, verses[n:]...)...)
+		case VerseDelete:
+			verses = append(verses[:n], verses[n+1:]...)
+		default:
+			return "", &VerseError{Edit: i, Err: ErrInvalidVerse, Message: fmt.Sprintf("unknown operation %q", edit.Op)}
+		}
+	}
+	return strings.Join(verses, VerseSeparator), nil
+}
@@ -0,0 +1,149 @@
+package storage_test
+
+import (
+	"effective-mobile/internal/storage"
+	"errors"
+	"testing"
+)
+
+func TestEditVerses(t *testing.T) {
+	const lyrics = "one\n\ntwo\n\nthree"
+	tests := []struct {
+		name   string
+		lyrics string
+		edits  []storage.VerseEdit
+		want   string
+		// edit is the position VerseError reports when err is set.
+		edit int
+		err  error
+	}{
+		{
+			name:   "replace",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseReplace, Verse: 2, Text: "TWO"}},
+			want:   "one\n\nTWO\n\nthree",
+		},
+		{
+			name:   "insert before",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseInsert, Verse: 1, Text: "zero"}},
+			want:   "zero\n\none\n\ntwo\n\nthree",
+		},
+		{
+			name:   "insert after the last verse",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseInsert, Verse: 4, Text: "four"}},
+			want:   "one\n\ntwo\n\nthree\n\nfour",
+		},
+		{
+			name:   "delete",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseDelete, Verse: 1}},
+			want:   "two\n\nthree",
+		},
+		{
+			name:   "delete every verse",
+			lyrics: "one",
+			edits:  []storage.VerseEdit{{Op: storage.VerseDelete, Verse: 1}},
+			want:   "",
+		},
+		{
+			name:   "edits see earlier edits",
+			lyrics: lyrics,
+			edits: []storage.VerseEdit{
+				{Op: storage.VerseDelete, Verse: 1},
+				{Op: storage.VerseReplace, Verse: 1, Text: "TWO"},
+				{Op: storage.VerseInsert, Verse: 3, Text: "four"},
+			},
+			want: "TWO\n\nthree\n\nfour",
+		},
+		{
+			name:   "replace out of range",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseReplace, Verse: 4, Text: "four"}},
+			err:    storage.ErrVerseOutOfRange,
+		},
+		{
+			name:   "insert out of range",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseInsert, Verse: 5, Text: "five"}},
+			err:    storage.ErrVerseOutOfRange,
+		},
+		{
+			name:   "delete out of range after an earlier delete",
+			lyrics: lyrics,
+			edits: []storage.VerseEdit{
+				{Op: storage.VerseDelete, Verse: 3},
+				{Op: storage.VerseDelete, Verse: 3},
+			},
+			edit: 1,
+			err:  storage.ErrVerseOutOfRange,
+		},
+		{
+			name:   "verse zero",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseDelete, Verse: 0}},
+			err:    storage.ErrVerseOutOfRange,
+		},
+		{
+			name:   "insert into empty lyrics",
+			lyrics: "",
+			edits:  []storage.VerseEdit{{Op: storage.VerseInsert, Verse: 1, Text: "one"}},
+			want:   "one",
+		},
+		{
+			name:   "replace in empty lyrics",
+			lyrics: "",
+			edits:  []storage.VerseEdit{{Op: storage.VerseReplace, Verse: 1, Text: "one"}},
+			err:    storage.ErrVerseOutOfRange,
+		},
+		{
+			name:   "delete in empty lyrics",
+			lyrics: "",
+			edits:  []storage.VerseEdit{{Op: storage.VerseDelete, Verse: 1}},
+			err:    storage.ErrVerseOutOfRange,
+		},
+		{
+			name:   "blank text",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseReplace, Verse: 1, Text: "  "}},
+			err:    storage.ErrInvalidVerse,
+		},
+		{
+			name:   "text with several verses",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: storage.VerseInsert, Verse: 1, Text: "a\n\nb"}},
+			err:    storage.ErrInvalidVerse,
+		},
+		{
+			name:   "unknown operation",
+			lyrics: lyrics,
+			edits:  []storage.VerseEdit{{Op: "move", Verse: 1, Text: "one"}},
+			err:    storage.ErrInvalidVerse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := storage.EditVerses(tt.lyrics, tt.edits)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+				return
+			}
+			var verseErr *storage.VerseError
+			if !errors.As(err, &verseErr) {
+				t.Fatalf("error = %v, want a *VerseError", err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+			if verseErr.Edit != tt.edit {
+				t.Errorf("edit = %d, want %d", verseErr.Edit, tt.edit)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"log/slog"
+	"slices"
+)
+
+func (s *Storage) InsertAlbum(ctx context.Context, album storage.Album) (uint, error) {
+	const op = "storage.memory.InsertAlbum"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.groupIndex(album.GroupID) < 0 {
+		return 0, storage.ErrGroupNotFound
+	}
+	album.ID = s.nextAlbumID
+	s.nextAlbumID++
+	s.albums = append(s.albums, album)
+	return album.ID, nil
+}
+
+func (s *Storage) SelectAlbums(ctx context.Context, limit int, offset int) ([]storage.Album, int, error) {
+	const op = "storage.memory.SelectAlbums"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	albums := make([]storage.Album, len(s.albums))
+	for i, album := range s.albums {
+		albums[i] = s.withGroupName(album)
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(albums, func(a, b storage.Album) int {
+		if c := cmp.Compare(a.Title, b.Title); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	total := len(albums)
+	albums = albums[min(offset, len(albums)):]
+	if limit > 0 && len(albums) > limit {
+		albums = albums[:limit]
+	}
+	return albums, total, nil
+}
+
+func (s *Storage) GetAlbum(ctx context.Context, id uint) (storage.Album, error) {
+	const op = "storage.memory.GetAlbum"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.albumIndex(id)
+	if i < 0 {
+		return storage.Album{}, storage.ErrAlbumNotFound
+	}
+	return s.withGroupName(s.albums[i]), nil
+}
+
+func (s *Storage) DeleteAlbum(ctx context.Context, id uint) error {
+	const op = "storage.memory.DeleteAlbum"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.albumIndex(id)
+	if i < 0 {
+		return storage.ErrAlbumNotFound
+	}
+	s.albums = slices.Delete(s.albums, i, i+1)
+	delete(s.tracks, id)
+	return nil
+}
+
+func (s *Storage) SelectTracks(ctx context.Context, albumID uint) ([]storage.Track, error) {
+	const op = "storage.memory.SelectTracks"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.albumIndex(albumID) < 0 {
+		return nil, storage.ErrAlbumNotFound
+	}
+	var tracks []storage.Track
+	for n, songID := range s.tracks[albumID] {
+		tracks = append(tracks, storage.Track{Number: n + 1, Song: s.songs[s.index(songID)]})
+	}
+	return tracks, nil
+}
+
+func (s *Storage) AttachTrack(ctx context.Context, albumID uint, songID uint, number int) error {
+	const op = "storage.memory.AttachTrack"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.albumIndex(albumID) < 0 {
+		return storage.ErrAlbumNotFound
+	}
+	tracks := s.tracks[albumID]
+	if slices.Contains(tracks, songID) {
+		return storage.ErrTrackExists
+	}
+	if s.index(songID) < 0 {
+		return storage.ErrSongNotFound
+	}
+	if number < 1 || number > len(tracks) {
+		number = len(tracks) + 1
+	}
+	s.tracks[albumID] = slices.Insert(tracks, number-1, songID)
+	return nil
+}
+
+func (s *Storage) DetachTrack(ctx context.Context, albumID uint, songID uint) error {
+	const op = "storage.memory.DetachTrack"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.albumIndex(albumID) < 0 {
+		return storage.ErrAlbumNotFound
+	}
+	tracks := s.tracks[albumID]
+	i := slices.Index(tracks, songID)
+	if i < 0 {
+		return storage.ErrTrackNotFound
+	}
+	s.tracks[albumID] = slices.Delete(tracks, i, i+1)
+	return nil
+}
+
+func (s *Storage) ReorderTracks(ctx context.Context, albumID uint, songIDs []uint) error {
+	const op = "storage.memory.ReorderTracks"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.albumIndex(albumID) < 0 {
+		return storage.ErrAlbumNotFound
+	}
+	current := slices.Clone(s.tracks[albumID])
+	order := slices.Clone(songIDs)
+	slices.Sort(current)
+	slices.Sort(order)
+	if !slices.Equal(current, order) {
+		return storage.ErrInvalidTrackOrder
+	}
+	s.tracks[albumID] = slices.Clone(songIDs)
+	return nil
+}
+
+// detachEverywhere removes a deleted song from all albums and playlists. The
+// caller must hold the write lock.
+func (s *Storage) detachEverywhere(songID uint) {
+	isSong := func(id uint) bool { return id == songID }
+	for albumID, tracks := range s.tracks {
+		s.tracks[albumID] = slices.DeleteFunc(tracks, isSong)
+	}
+	for playlistID, entries := range s.entries {
+		s.entries[playlistID] = slices.DeleteFunc(entries, isSong)
+	}
+}
+
+// withGroupName fills in the current name of the album's group. The caller must hold the lock.
+func (s *Storage) withGroupName(album storage.Album) storage.Album {
+	if i := s.groupIndex(album.GroupID); i >= 0 {
+		album.GroupName = s.groups[i].Name
+	}
+	return album
+}
+
+func (s *Storage) albumIndex(id uint) int {
+	return slices.IndexFunc(s.albums, func(album storage.Album) bool {
+		return album.ID == id
+	})
+}
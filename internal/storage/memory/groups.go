@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+func (s *Storage) InsertGroup(ctx context.Context, group storage.Group) (uint, error) {
+	const op = "storage.memory.InsertGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.groupIndexByName(group.Name) >= 0 {
+		return 0, storage.ErrGroupExists
+	}
+	group.ID = s.nextGroupID
+	s.nextGroupID++
+	group.Genres = slices.Clone(group.Genres)
+	if group.Genres == nil {
+		group.Genres = []string{}
+	}
+	s.groups = append(s.groups, group)
+	return group.ID, nil
+}
+
+func (s *Storage) SelectGroups(ctx context.Context, limit int, offset int) ([]storage.Group, int, error) {
+	const op = "storage.memory.SelectGroups"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	groups := slices.Clone(s.groups)
+	s.mu.RUnlock()
+
+	slices.SortFunc(groups, func(a, b storage.Group) int {
+		if c := cmp.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	total := len(groups)
+	groups = groups[min(offset, len(groups)):]
+	if limit > 0 && len(groups) > limit {
+		groups = groups[:limit]
+	}
+	return groups, total, nil
+}
+
+func (s *Storage) GetGroup(ctx context.Context, id uint) (storage.Group, error) {
+	const op = "storage.memory.GetGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.groupIndex(id)
+	if i < 0 {
+		return storage.Group{}, storage.ErrGroupNotFound
+	}
+	return s.groups[i], nil
+}
+
+func (s *Storage) UpdateGroup(ctx context.Context, id uint, update storage.GroupUpdate) error {
+	const op = "storage.memory.UpdateGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	if update.IsEmpty() {
+		return fmt.Errorf("%s: no fields to update", op)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.groupIndex(id)
+	if i < 0 {
+		return storage.ErrGroupNotFound
+	}
+	group := &s.groups[i]
+	if update.Name != nil && *update.Name != group.Name {
+		if j := s.groupIndexByName(*update.Name); j >= 0 && j != i {
+			return storage.ErrGroupExists
+		}
+		group.Name = *update.Name
+		for j := range s.songs {
+			if s.songs[j].GroupID == id {
+				s.songs[j].GroupName = group.Name
+				s.songs[j].Version++
+			}
+		}
+		for j := range s.trash {
+			if s.trash[j].GroupID == id {
+				s.trash[j].GroupName = group.Name
+				s.trash[j].Version++
+			}
+		}
+	}
+	if update.Country != nil {
+		group.Country = *update.Country
+	}
+	if update.FormedYear != nil {
+		group.FormedYear = *update.FormedYear
+	}
+	if update.Genres != nil {
+		group.Genres = slices.Clone(*update.Genres)
+		if group.Genres == nil {
+			group.Genres = []string{}
+		}
+	}
+	if update.Description != nil {
+		group.Description = *update.Description
+	}
+	return nil
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, id uint) error {
+	const op = "storage.memory.DeleteGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.groupIndex(id)
+	if i < 0 {
+		return storage.ErrGroupNotFound
+	}
+	if slices.ContainsFunc(s.songs, func(song storage.Song) bool { return song.GroupID == id }) ||
+		slices.ContainsFunc(s.trash, func(song storage.DeletedSong) bool { return song.GroupID == id }) ||
+		slices.ContainsFunc(s.albums, func(album storage.Album) bool { return album.GroupID == id }) {
+		return storage.ErrGroupInUse
+	}
+	s.groups = slices.Delete(s.groups, i, i+1)
+	return nil
+}
+
+// ensureGroup returns the ID of the group with the given name, creating it
+// if needed. The caller must hold the write lock.
+func (s *Storage) ensureGroup(name string) uint {
+	if i := s.groupIndexByName(name); i >= 0 {
+		return s.groups[i].ID
+	}
+	group := storage.Group{ID: s.nextGroupID, Name: name, Genres: []string{}}
+	s.nextGroupID++
+	s.groups = append(s.groups, group)
+	return group.ID
+}
+
+// assignGroup sets the group of the song by its GroupName, creating the group
+// if needed, and takes over the group's spelling of the name. The caller must
+// hold the write lock.
+func (s *Storage) assignGroup(song *storage.Song) {
+	song.GroupID = s.ensureGroup(song.GroupName)
+	song.GroupName = s.groups[s.groupIndex(song.GroupID)].Name
+}
+
+func (s *Storage) groupIndex(id uint) int {
+	return slices.IndexFunc(s.groups, func(group storage.Group) bool {
+		return group.ID == id
+	})
+}
+
+func (s *Storage) groupIndexByName(name string) int {
+	name = storage.NormalizeName(name)
+	return slices.IndexFunc(s.groups, func(group storage.Group) bool {
+		return storage.NormalizeName(group.Name) == name
+	})
+}
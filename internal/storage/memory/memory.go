@@ -0,0 +1,523 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Storage keeps songs in process memory. It is meant for tests and local
+// demos and loses all data on Stop.
+type Storage struct {
+	mu     sync.RWMutex
+	songs  []storage.Song
+	nextID uint
+	// trash holds the deleted songs in the order they were deleted.
+	trash []storage.DeletedSong
+	// revisions holds the history of every song, oldest first.
+	revisions   map[uint][]storage.SongRevision
+	groups      []storage.Group
+	nextGroupID uint
+	albums      []storage.Album
+	nextAlbumID uint
+	// tracks holds the song IDs of every album in track order.
+	tracks map[uint][]uint
+
+	playlists      []storage.Playlist
+	nextPlaylistID uint
+	// entries holds the song IDs of every playlist in order.
+	entries map[uint][]uint
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+func New() *Storage {
+	const op = "storage.memory.New"
+	slog.Log(context.TODO(), slog.LevelInfo, op)
+	return &Storage{
+		nextID:         1,
+		revisions:      make(map[uint][]storage.SongRevision),
+		nextGroupID:    1,
+		nextAlbumID:    1,
+		tracks:         make(map[uint][]uint),
+		nextPlaylistID: 1,
+		entries:        make(map[uint][]uint),
+	}
+}
+
+// Connect returns at once: memory is always there.
+func (s *Storage) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Storage) Stop() error {
+	const op = "storage.memory.Stop"
+	slog.Log(context.TODO(), slog.LevelInfo, op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.songs = nil
+	s.trash = nil
+	clear(s.revisions)
+	s.groups = nil
+	s.albums = nil
+	clear(s.tracks)
+	s.playlists = nil
+	clear(s.entries)
+	return nil
+}
+
+func (s *Storage) InsertSong(ctx context.Context, song storage.Song, actor storage.Actor) (uint, error) {
+	const op = "storage.memory.InsertSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.songIndexByGroupName(song.GroupName, song.SongName, 0) >= 0 {
+		return 0, storage.ErrSongExists
+	}
+	s.assignGroup(&song)
+	song.ID = s.nextID
+	song.Version = 1
+	s.nextID++
+	s.songs = append(s.songs, song)
+	s.record(storage.RevisionCreated, nil, &song, actor)
+	return song.ID, nil
+}
+
+func (s *Storage) UpsertSong(ctx context.Context, song storage.Song, actor storage.Actor) (uint, bool, error) {
+	const op = "storage.memory.UpsertSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assignGroup(&song)
+	if i := s.songIndexByName(song.GroupID, song.SongName, 0); i >= 0 {
+		song.ID = s.songs[i].ID
+		s.replace(i, song, storage.RevisionUpdated, actor)
+		return song.ID, false, nil
+	}
+	song.ID = s.nextID
+	song.Version = 1
+	s.nextID++
+	s.songs = append(s.songs, song)
+	s.record(storage.RevisionCreated, nil, &song, actor)
+	return song.ID, true, nil
+}
+
+func (s *Storage) InsertSongs(ctx context.Context, songs []storage.Song, upsert bool, actor storage.Actor) ([]storage.SongBatchResult, error) {
+	const op = "storage.memory.InsertSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := make([]storage.SongBatchResult, len(songs))
+	for i, song := range songs {
+		s.assignGroup(&song)
+		if j := s.songIndexByName(song.GroupID, song.SongName, 0); j >= 0 {
+			if upsert {
+				song.ID = s.songs[j].ID
+				s.replace(j, song, storage.RevisionUpdated, actor)
+				results[i] = storage.SongBatchResult{ID: song.ID}
+			}
+			continue
+		}
+		song.ID = s.nextID
+		song.Version = 1
+		s.nextID++
+		s.songs = append(s.songs, song)
+		s.record(storage.RevisionCreated, nil, &song, actor)
+		results[i] = storage.SongBatchResult{ID: song.ID, Created: true}
+	}
+	return results, nil
+}
+
+func (s *Storage) SelectSongs(ctx context.Context, filter storage.SongFilter, page storage.Page) ([]storage.Song, int, error) {
+	const op = "storage.memory.SelectSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	if err := storage.ValidatePage(page); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	s.mu.RLock()
+	var songs []storage.Song
+	for _, song := range s.songs {
+		if matches(song, filter) && s.onAlbums(song.ID, filter.AlbumIDs) {
+			songs = append(songs, song)
+		}
+	}
+	s.mu.RUnlock()
+	total := len(songs)
+
+	slices.SortStableFunc(songs, func(a, b storage.Song) int {
+		for _, sort := range page.Sort {
+			c := compareField(a, b, sort.Field)
+			if sort.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	_, idDesc := page.KeysetSort()
+	follows := func(song storage.Song, id uint) bool {
+		if idDesc {
+			return song.ID < id
+		}
+		return song.ID > id
+	}
+	if page.AfterID != 0 {
+		songs = slices.DeleteFunc(songs, func(song storage.Song) bool {
+			return !follows(song, page.AfterID)
+		})
+	}
+	if page.BeforeID != 0 {
+		songs = slices.DeleteFunc(songs, func(song storage.Song) bool {
+			return song.ID == page.BeforeID || follows(song, page.BeforeID)
+		})
+		if page.Limit > 0 && len(songs) > page.Limit {
+			songs = songs[len(songs)-page.Limit:]
+		}
+	}
+
+	songs = songs[min(page.Offset, len(songs)):]
+	if page.Limit > 0 && len(songs) > page.Limit {
+		songs = songs[:page.Limit]
+	}
+	return songs, total, nil
+}
+
+func (s *Storage) ExportSongs(ctx context.Context, filter storage.SongFilter, fn func(storage.Song) error) error {
+	const op = "storage.memory.ExportSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	var songs []storage.Song
+	for _, song := range s.songs {
+		if matches(song, filter) && s.onAlbums(song.ID, filter.AlbumIDs) {
+			songs = append(songs, song)
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(songs, func(a, b storage.Song) int { return cmp.Compare(a.ID, b.ID) })
+	for _, song := range songs {
+		if err := fn(song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onAlbums reports whether the song is on any of the albums; no albums match every song.
+func (s *Storage) onAlbums(songID uint, albumIDs []uint) bool {
+	if len(albumIDs) == 0 {
+		return true
+	}
+	for _, albumID := range albumIDs {
+		if slices.Contains(s.tracks[albumID], songID) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(song storage.Song, filter storage.SongFilter) bool {
+	if len(filter.GroupIDs) > 0 && !slices.Contains(filter.GroupIDs, song.GroupID) {
+		return false
+	}
+	if !matchesName(song.GroupName, filter.Groups, filter.NameMatch) ||
+		!matchesName(song.SongName, filter.Songs, filter.NameMatch) {
+		return false
+	}
+	if len(filter.ReleaseDates) > 0 && !slices.Contains(filter.ReleaseDates, song.ReleaseDate) {
+		return false
+	}
+	if filter.ReleasedFrom != "" && (song.ReleaseDate == "" || song.ReleaseDate < filter.ReleasedFrom) {
+		return false
+	}
+	if filter.ReleasedTo != "" && (song.ReleaseDate == "" || song.ReleaseDate > filter.ReleasedTo) {
+		return false
+	}
+	if filter.HasLyrics != nil && *filter.HasLyrics != (song.Lyrics != "") {
+		return false
+	}
+	if filter.HasLink != nil && *filter.HasLink != (song.YoutubeLink != "") {
+		return false
+	}
+	lyrics := strings.ToLower(song.Lyrics)
+	for _, word := range filter.LyricsWords {
+		if !strings.Contains(lyrics, strings.ToLower(word)) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesName(name string, values []string, match storage.MatchMode) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		switch match {
+		case storage.MatchPrefix:
+			if strings.HasPrefix(strings.ToLower(name), strings.ToLower(v)) {
+				return true
+			}
+		case storage.MatchContains:
+			if strings.Contains(strings.ToLower(name), strings.ToLower(v)) {
+				return true
+			}
+		default:
+			if name == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareField orders two songs by a sort field the way Postgres does: a
+// song without a release date sorts as if it had the greatest one, like a
+// NULL.
+func compareField(a, b storage.Song, field string) int {
+	switch field {
+	case storage.SortByID:
+		return cmp.Compare(a.ID, b.ID)
+	case storage.SortByReleaseDate:
+		if (a.ReleaseDate == "") != (b.ReleaseDate == "") {
+			if a.ReleaseDate == "" {
+				return 1
+			}
+			return -1
+		}
+	}
+	return cmp.Compare(sortKey(a, field), sortKey(b, field))
+}
+
+func sortKey(song storage.Song, field string) string {
+	switch field {
+	case storage.SortByGroup:
+		return song.GroupName
+	case storage.SortBySong:
+		return song.SongName
+	case storage.SortByReleaseDate:
+		return song.ReleaseDate
+	case storage.SortByLyrics:
+		return song.Lyrics
+	case storage.SortByYoutubeLink:
+		return song.YoutubeLink
+	default:
+		return ""
+	}
+}
+
+func (s *Storage) DeleteSong(ctx context.Context, song string, group string, version int, actor storage.Actor) error {
+	const op = "storage.memory.DeleteSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.songs, func(stored storage.Song) bool {
+		return sameSong(stored, song, group)
+	})
+	if i < 0 {
+		return storage.ErrSongNotFound
+	}
+	if err := s.checkVersion(i, version); err != nil {
+		return err
+	}
+	s.trashSong(i, actor)
+	return nil
+}
+
+func (s *Storage) GetLyrics(ctx context.Context, song string, group string) (string, error) {
+	const op = "storage.memory.GetLyrics"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, stored := range s.songs {
+		if sameSong(stored, song, group) {
+			return stored.Lyrics, nil
+		}
+	}
+	return "", storage.ErrSongNotFound
+}
+
+func (s *Storage) UpdateSong(ctx context.Context, song string, group string, update storage.SongUpdate, version int, actor storage.Actor) error {
+	const op = "storage.memory.UpdateSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	if update.IsEmpty() {
+		return fmt.Errorf("%s: no fields to update", op)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.songs, func(stored storage.Song) bool {
+		return sameSong(stored, song, group)
+	})
+	if i < 0 {
+		return storage.ErrSongNotFound
+	}
+	if err := s.checkVersion(i, version); err != nil {
+		return err
+	}
+	return s.update(i, update, actor)
+}
+
+func (s *Storage) GetSong(ctx context.Context, id uint) (storage.Song, error) {
+	const op = "storage.memory.GetSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.index(id)
+	if i < 0 {
+		return storage.Song{}, storage.ErrSongNotFound
+	}
+	return s.songs[i], nil
+}
+
+func (s *Storage) ReplaceSong(ctx context.Context, id uint, song storage.Song, version int, actor storage.Actor) error {
+	const op = "storage.memory.ReplaceSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return storage.ErrSongNotFound
+	}
+	if err := s.checkVersion(i, version); err != nil {
+		return err
+	}
+	song.ID = id
+	if s.songIndexByGroupName(song.GroupName, song.SongName, id) >= 0 {
+		return storage.ErrSongExists
+	}
+	s.assignGroup(&song)
+	s.replace(i, song, storage.RevisionUpdated, actor)
+	return nil
+}
+
+func (s *Storage) UpdateSongByID(ctx context.Context, id uint, update storage.SongUpdate, version int, actor storage.Actor) error {
+	const op = "storage.memory.UpdateSongByID"
+	logctx.From(ctx, slog.Default()).Info(op)
+	if update.IsEmpty() {
+		return fmt.Errorf("%s: no fields to update", op)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return storage.ErrSongNotFound
+	}
+	if err := s.checkVersion(i, version); err != nil {
+		return err
+	}
+	return s.update(i, update, actor)
+}
+
+// update applies a partial update to the song at position i. The caller must
+// hold the write lock.
+func (s *Storage) update(i int, update storage.SongUpdate, actor storage.Actor) error {
+	updated := s.songs[i]
+	set := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	set(&updated.GroupName, update.GroupName)
+	set(&updated.SongName, update.SongName)
+	set(&updated.ReleaseDate, update.ReleaseDate)
+	set(&updated.Lyrics, update.Lyrics)
+	set(&updated.YoutubeLink, update.YoutubeLink)
+	if len(update.Verses) > 0 {
+		lyrics, err := storage.EditVerses(updated.Lyrics, update.Verses)
+		if err != nil {
+			return err
+		}
+		updated.Lyrics = lyrics
+	}
+	if s.songIndexByGroupName(updated.GroupName, updated.SongName, updated.ID) >= 0 {
+		return storage.ErrSongExists
+	}
+	s.assignGroup(&updated)
+	s.replace(i, updated, storage.RevisionUpdated, actor)
+	return nil
+}
+
+// replace overwrites the song at position i, bumping its version and
+// recording the revision unless nothing changes. The caller must hold the
+// write lock.
+func (s *Storage) replace(i int, song storage.Song, action string, actor storage.Actor) {
+	old := s.songs[i]
+	song.Version = old.Version
+	if song == old {
+		return
+	}
+	song.Version++
+	s.record(action, &old, &song, actor)
+	s.songs[i] = song
+}
+
+// checkVersion fails with ErrVersionMismatch unless version is zero or the
+// version of the song at position i. The caller must hold the lock.
+func (s *Storage) checkVersion(i int, version int) error {
+	if version != 0 && s.songs[i].Version != version {
+		return storage.ErrVersionMismatch
+	}
+	return nil
+}
+
+func (s *Storage) DeleteSongByID(ctx context.Context, id uint, version int, actor storage.Actor) error {
+	const op = "storage.memory.DeleteSongByID"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return storage.ErrSongNotFound
+	}
+	if err := s.checkVersion(i, version); err != nil {
+		return err
+	}
+	s.trashSong(i, actor)
+	return nil
+}
+
+// index returns the position of the song with the given ID or -1. The caller must hold the lock.
+func (s *Storage) index(id uint) int {
+	return slices.IndexFunc(s.songs, func(song storage.Song) bool {
+		return song.ID == id
+	})
+}
+
+// songIndexByName returns the position of the group's song with the given
+// name, other than the song exceptID, or -1. The caller must hold the lock.
+func (s *Storage) songIndexByName(groupID uint, name string, exceptID uint) int {
+	name = storage.NormalizeName(name)
+	return slices.IndexFunc(s.songs, func(song storage.Song) bool {
+		return song.ID != exceptID && song.GroupID == groupID && storage.NormalizeName(song.SongName) == name
+	})
+}
+
+// songIndexByGroupName is songIndexByName for a group given by name; a group
+// that does not exist yet has no songs. It lets writes check for conflicts
+// before they create the group. The caller must hold the lock.
+func (s *Storage) songIndexByGroupName(group string, name string, exceptID uint) int {
+	g := s.groupIndexByName(group)
+	if g < 0 {
+		return -1
+	}
+	return s.songIndexByName(s.groups[g].ID, name, exceptID)
+}
+
+// sameSong reports whether the song has the given song and group names.
+func sameSong(stored storage.Song, song string, group string) bool {
+	return storage.NormalizeName(stored.SongName) == storage.NormalizeName(song) &&
+		storage.NormalizeName(stored.GroupName) == storage.NormalizeName(group)
+}
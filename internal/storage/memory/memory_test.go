@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/memory"
+	"effective-mobile/internal/storage/storagetest"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return memory.New()
+	})
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+func (s *Storage) InsertPlaylist(ctx context.Context, playlist storage.Playlist) (uint, error) {
+	const op = "storage.memory.InsertPlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	playlist.ID = s.nextPlaylistID
+	s.nextPlaylistID++
+	s.playlists = append(s.playlists, playlist)
+	return playlist.ID, nil
+}
+
+func (s *Storage) SelectPlaylists(ctx context.Context, filter storage.PlaylistFilter, limit int, offset int) ([]storage.Playlist, int, error) {
+	const op = "storage.memory.SelectPlaylists"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	var playlists []storage.Playlist
+	for _, playlist := range s.playlists {
+		if (filter.Owner == "" || playlist.Owner == filter.Owner) && playlist.Visible(filter.Viewer) {
+			playlists = append(playlists, playlist)
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(playlists, func(a, b storage.Playlist) int {
+		if c := cmp.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	total := len(playlists)
+	playlists = playlists[min(offset, len(playlists)):]
+	if limit > 0 && len(playlists) > limit {
+		playlists = playlists[:limit]
+	}
+	return playlists, total, nil
+}
+
+func (s *Storage) GetPlaylist(ctx context.Context, id uint) (storage.Playlist, error) {
+	const op = "storage.memory.GetPlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.playlistIndex(id)
+	if i < 0 {
+		return storage.Playlist{}, storage.ErrPlaylistNotFound
+	}
+	return s.playlists[i], nil
+}
+
+func (s *Storage) UpdatePlaylist(ctx context.Context, id uint, update storage.PlaylistUpdate) error {
+	const op = "storage.memory.UpdatePlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	if update.IsEmpty() {
+		return fmt.Errorf("%s: no fields to update", op)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.playlistIndex(id)
+	if i < 0 {
+		return storage.ErrPlaylistNotFound
+	}
+	playlist := &s.playlists[i]
+	if update.Name != nil {
+		playlist.Name = *update.Name
+	}
+	if update.Description != nil {
+		playlist.Description = *update.Description
+	}
+	if update.Public != nil {
+		playlist.Public = *update.Public
+	}
+	return nil
+}
+
+func (s *Storage) DeletePlaylist(ctx context.Context, id uint) error {
+	const op = "storage.memory.DeletePlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.playlistIndex(id)
+	if i < 0 {
+		return storage.ErrPlaylistNotFound
+	}
+	s.playlists = slices.Delete(s.playlists, i, i+1)
+	delete(s.entries, id)
+	return nil
+}
+
+func (s *Storage) SelectEntries(ctx context.Context, playlistID uint) ([]storage.Entry, error) {
+	const op = "storage.memory.SelectEntries"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.playlistIndex(playlistID) < 0 {
+		return nil, storage.ErrPlaylistNotFound
+	}
+	var entries []storage.Entry
+	for n, songID := range s.entries[playlistID] {
+		entries = append(entries, storage.Entry{Position: n + 1, Song: s.songs[s.index(songID)]})
+	}
+	return entries, nil
+}
+
+func (s *Storage) AddEntry(ctx context.Context, playlistID uint, songID uint, position int) error {
+	const op = "storage.memory.AddEntry"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.playlistIndex(playlistID) < 0 {
+		return storage.ErrPlaylistNotFound
+	}
+	entries := s.entries[playlistID]
+	if slices.Contains(entries, songID) {
+		return storage.ErrEntryExists
+	}
+	if s.index(songID) < 0 {
+		return storage.ErrSongNotFound
+	}
+	if position < 1 || position > len(entries) {
+		position = len(entries) + 1
+	}
+	s.entries[playlistID] = slices.Insert(entries, position-1, songID)
+	return nil
+}
+
+func (s *Storage) RemoveEntry(ctx context.Context, playlistID uint, songID uint) error {
+	const op = "storage.memory.RemoveEntry"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.playlistIndex(playlistID) < 0 {
+		return storage.ErrPlaylistNotFound
+	}
+	entries := s.entries[playlistID]
+	i := slices.Index(entries, songID)
+	if i < 0 {
+		return storage.ErrEntryNotFound
+	}
+	s.entries[playlistID] = slices.Delete(entries, i, i+1)
+	return nil
+}
+
+func (s *Storage) MoveEntry(ctx context.Context, playlistID uint, songID uint, position int) error {
+	const op = "storage.memory.MoveEntry"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.playlistIndex(playlistID) < 0 {
+		return storage.ErrPlaylistNotFound
+	}
+	entries := s.entries[playlistID]
+	i := slices.Index(entries, songID)
+	if i < 0 {
+		return storage.ErrEntryNotFound
+	}
+	if position < 1 || position > len(entries) {
+		position = len(entries)
+	}
+	entries = slices.Delete(entries, i, i+1)
+	s.entries[playlistID] = slices.Insert(entries, position-1, songID)
+	return nil
+}
+
+func (s *Storage) playlistIndex(id uint) int {
+	return slices.IndexFunc(s.playlists, func(playlist storage.Playlist) bool {
+		return playlist.ID == id
+	})
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+func (s *Storage) SongHistory(ctx context.Context, id uint, limit int, offset int) ([]storage.SongRevision, int, error) {
+	const op = "storage.memory.SongHistory"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revisions := slices.Clone(s.revisions[id])
+	if len(revisions) == 0 {
+		return nil, 0, storage.ErrSongNotFound
+	}
+	slices.Reverse(revisions)
+	total := len(revisions)
+	revisions = revisions[min(offset, total):]
+	if limit > 0 && len(revisions) > limit {
+		revisions = revisions[:limit]
+	}
+	return revisions, total, nil
+}
+
+func (s *Storage) RevertSong(ctx context.Context, id uint, revision int, actor storage.Actor) error {
+	const op = "storage.memory.RevertSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return storage.ErrSongNotFound
+	}
+	j := slices.IndexFunc(s.revisions[id], func(r storage.SongRevision) bool {
+		return r.Revision == revision
+	})
+	if j < 0 {
+		return storage.ErrRevisionNotFound
+	}
+	target := s.revisions[id][j].New
+	if target == nil {
+		return storage.ErrRevisionEmpty
+	}
+
+	reverted := *target
+	reverted.ID = id
+	// The group may have been renamed since; it is kept unless it is gone.
+	if g := s.groupIndex(reverted.GroupID); g >= 0 {
+		reverted.GroupName = s.groups[g].Name
+	}
+	if s.songIndexByGroupName(reverted.GroupName, reverted.SongName, id) >= 0 {
+		return storage.ErrSongExists
+	}
+	s.assignGroup(&reverted)
+	s.replace(i, reverted, storage.RevisionReverted, actor)
+	return nil
+}
+
+// record appends a revision to the history of the song before or after
+// refers to. Revisions hold the values of the song without its version.
+// The caller must hold the write lock.
+func (s *Storage) record(action string, before *storage.Song, after *storage.Song, actor storage.Actor) {
+	revision := storage.SongRevision{
+		Action:    action,
+		Actor:     actor.Subject,
+		RequestID: actor.RequestID,
+		CreatedAt: time.Now(),
+	}
+	if revision.Actor == "" {
+		revision.Actor = storage.SystemActor
+	}
+	if before != nil {
+		song := *before
+		song.Version = 0
+		revision.Old = &song
+		revision.SongID = song.ID
+	}
+	if after != nil {
+		song := *after
+		song.Version = 0
+		revision.New = &song
+		revision.SongID = song.ID
+	}
+	revision.Revision = len(s.revisions[revision.SongID]) + 1
+	s.revisions[revision.SongID] = append(s.revisions[revision.SongID], revision)
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SearchSongs approximates the postgres full-text search: every word of the
+// query must occur in the song name or lyrics, and songs are ranked by how
+// often the words occur, with hits in the name weighing more.
+func (s *Storage) SearchSongs(ctx context.Context, query string, limit int, offset int) ([]storage.SearchResult, int, error) {
+	const op = "storage.memory.SearchSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+	highlight := highlighter(terms)
+
+	s.mu.RLock()
+	var results []storage.SearchResult
+	for _, song := range s.songs {
+		nameTokens := tokenize(song.SongName)
+		lyricsTokens := tokenize(song.Lyrics)
+		rank := 0.0
+		found := true
+		for _, term := range terms {
+			inName := count(nameTokens, term)
+			inLyrics := count(lyricsTokens, term)
+			if inName+inLyrics == 0 {
+				found = false
+				break
+			}
+			rank += float64(inName)*1.0 + float64(inLyrics)*0.4
+		}
+		if !found {
+			continue
+		}
+
+		result := storage.SearchResult{Song: song, Rank: rank / float64(len(lyricsTokens)+len(nameTokens))}
+		for i, verse := range strings.Split(song.Lyrics, storage.VerseSeparator) {
+			verseTokens := tokenize(verse)
+			if slices.ContainsFunc(terms, func(term string) bool { return count(verseTokens, term) > 0 }) {
+				result.Snippets = append(result.Snippets, storage.Snippet{
+					Verse: i + 1,
+					Text:  highlight(verse),
+				})
+			}
+		}
+		if result.Snippets == nil {
+			result.Snippets = []storage.Snippet{}
+		}
+		results = append(results, result)
+	}
+	s.mu.RUnlock()
+
+	slices.SortStableFunc(results, func(a, b storage.SearchResult) int {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	total := len(results)
+	results = results[min(offset, len(results)):]
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, total, nil
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func count(tokens []string, term string) int {
+	n := 0
+	for _, token := range tokens {
+		if token == term {
+			n++
+		}
+	}
+	return n
+}
+
+// highlighter wraps whole-word occurrences of the terms in the highlight markers.
+func highlighter(terms []string) func(string) string {
+	return func(text string) string {
+		var b strings.Builder
+		start := -1
+		flush := func(end int) {
+			word := text[start:end]
+			if slices.Contains(terms, strings.ToLower(word)) {
+				word = storage.HighlightStart + word + storage.HighlightStop
+			}
+			b.WriteString(word)
+			start = -1
+		}
+		for i, r := range text {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			if start >= 0 {
+				flush(i)
+			}
+			b.WriteRune(r)
+		}
+		if start >= 0 {
+			flush(len(text))
+		}
+		return b.String()
+	}
+}
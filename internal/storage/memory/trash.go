@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+func (s *Storage) SelectTrash(ctx context.Context, limit int, offset int) ([]storage.DeletedSong, int, error) {
+	const op = "storage.memory.SelectTrash"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	songs := slices.Clone(s.trash)
+	slices.Reverse(songs)
+	total := len(songs)
+	songs = songs[min(offset, total):]
+	if limit > 0 && len(songs) > limit {
+		songs = songs[:limit]
+	}
+	return songs, total, nil
+}
+
+func (s *Storage) RestoreSong(ctx context.Context, id uint, actor storage.Actor) error {
+	const op = "storage.memory.RestoreSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.trash, func(song storage.DeletedSong) bool {
+		return song.ID == id
+	})
+	if i < 0 {
+		return storage.ErrSongNotFound
+	}
+	song := s.trash[i].Song
+	if s.songIndexByName(song.GroupID, song.SongName, 0) >= 0 {
+		return storage.ErrSongExists
+	}
+	s.trash = slices.Delete(s.trash, i, i+1)
+	song.Version++
+	s.songs = append(s.songs, song)
+	s.record(storage.RevisionRestored, nil, &song, actor)
+	return nil
+}
+
+func (s *Storage) PurgeSongs(ctx context.Context, deletedBefore time.Time) (int, error) {
+	const op = "storage.memory.PurgeSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := len(s.trash)
+	s.trash = slices.DeleteFunc(s.trash, func(song storage.DeletedSong) bool {
+		if !song.DeletedAt.Before(deletedBefore) {
+			return false
+		}
+		s.record(storage.RevisionPurged, &song.Song, nil, storage.Actor{Subject: storage.SystemActor})
+		return true
+	})
+	return before - len(s.trash), nil
+}
+
+// trashSong moves the song at position i to the trash. The caller must hold
+// the write lock.
+func (s *Storage) trashSong(i int, actor storage.Actor) {
+	song := s.songs[i]
+	s.record(storage.RevisionDeleted, &song, nil, actor)
+	song.Version++
+	s.songs = slices.Delete(s.songs, i, i+1)
+	s.detachEverywhere(song.ID)
+	s.trash = append(s.trash, storage.DeletedSong{Song: song, DeletedAt: time.Now()})
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrPlaylistNotFound = errors.New("playlist not found")
+	ErrEntryNotFound    = errors.New("song is not in the playlist")
+	ErrEntryExists      = errors.New("song is already in the playlist")
+)
+
+type Playlist struct {
+	ID          uint   `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+	// Owner is the name of the user the playlist belongs to.
+	Owner  string `db:"owner" json:"owner"`
+	Public bool   `db:"public" json:"public"`
+}
+
+// PlaylistUpdate holds the playlist fields to change. Nil fields are left as is.
+type PlaylistUpdate struct {
+	Name        *string
+	Description *string
+	Public      *bool
+}
+
+func (u PlaylistUpdate) IsEmpty() bool {
+	return u.Name == nil && u.Description == nil && u.Public == nil
+}
+
+// PlaylistFilter selects the playlists to list.
+type PlaylistFilter struct {
+	// Owner limits the listing to the playlists of one user when set.
+	Owner string
+	// Viewer is the user asking. Private playlists are only listed for their owner.
+	Viewer string
+}
+
+// Visible reports whether the playlist may be shown to the viewer.
+func (p Playlist) Visible(viewer string) bool {
+	return p.Public || (viewer != "" && p.Owner == viewer)
+}
+
+// Entry is a song in a playlist. Positions start at 1 and have no gaps.
+type Entry struct {
+	Position int `db:"position" json:"position"`
+	Song
+}
+
+// PlaylistStore is implemented by storage backends that keep playlists.
+type PlaylistStore interface {
+	InsertPlaylist(ctx context.Context, playlist Playlist) (uint, error)
+	// SelectPlaylists returns the playlists matching the filter ordered by name.
+	SelectPlaylists(ctx context.Context, filter PlaylistFilter, limit int, offset int) ([]Playlist, int, error)
+	GetPlaylist(ctx context.Context, id uint) (Playlist, error)
+	UpdatePlaylist(ctx context.Context, id uint, update PlaylistUpdate) error
+	DeletePlaylist(ctx context.Context, id uint) error
+
+	// SelectEntries returns the songs of the playlist ordered by position.
+	SelectEntries(ctx context.Context, playlistID uint) ([]Entry, error)
+	// AddEntry puts the song into the playlist at the given position, moving
+	// the following entries down. Positions past the end, or 0, append. A
+	// song can be in a playlist only once.
+	AddEntry(ctx context.Context, playlistID uint, songID uint, position int) error
+	// RemoveEntry removes the song from the playlist and closes the gap.
+	RemoveEntry(ctx context.Context, playlistID uint, songID uint) error
+	// MoveEntry moves the song to the given position. Positions past the
+	// end, or 0, move it to the end.
+	MoveEntry(ctx context.Context, playlistID uint, songID uint, position int) error
+}
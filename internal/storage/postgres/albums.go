@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/postgres/queries"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/lib/pq"
+)
+
+func (s *Storage) InsertAlbum(ctx context.Context, album storage.Album) (uint, error) {
+	const op = "storage.postgres.InsertAlbum"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var id uint
+	err := s.db.QueryRowContext(ctx, queries.InsertAlbum, album.Title, album.GroupID, album.ReleaseDate, album.CoverURL).Scan(&id)
+	if isPQError(err, foreignKeyViolation) {
+		err = storage.ErrGroupNotFound
+	}
+	if err != nil {
+		return 0, wrap(ctx, op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) SelectAlbums(ctx context.Context, limit int, offset int) ([]storage.Album, int, error) {
+	const op = "storage.postgres.SelectAlbums"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var total int
+	err := s.db.GetContext(ctx, &total, queries.CountAlbums)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	var albums []storage.Album
+	err = s.db.SelectContext(ctx, &albums, queries.GetAlbums, limit, offset)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	return albums, total, nil
+}
+
+func (s *Storage) GetAlbum(ctx context.Context, id uint) (storage.Album, error) {
+	const op = "storage.postgres.GetAlbum"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var album storage.Album
+	err := s.db.GetContext(ctx, &album, queries.GetAlbum, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = storage.ErrAlbumNotFound
+	}
+	if err != nil {
+		return storage.Album{}, wrap(ctx, op, err)
+	}
+	return album, nil
+}
+
+func (s *Storage) DeleteAlbum(ctx context.Context, id uint) error {
+	const op = "storage.postgres.DeleteAlbum"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, queries.DeleteAlbum, id)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+	return wrap(ctx, op, checkAffected(res, storage.ErrAlbumNotFound))
+}
+
+func (s *Storage) SelectTracks(ctx context.Context, albumID uint) ([]storage.Track, error) {
+	const op = "storage.postgres.SelectTracks"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	if _, err := s.GetAlbum(ctx, albumID); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var tracks []storage.Track
+	err := s.db.SelectContext(ctx, &tracks, queries.GetTracks, albumID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	return tracks, nil
+}
+
+func (s *Storage) AttachTrack(ctx context.Context, albumID uint, songID uint, number int) error {
+	const op = "storage.postgres.AttachTrack"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.WithTx(ctx, func(tx *Tx) error {
+		songIDs, err := lockTracks(tx, albumID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(songIDs, songID) {
+			return storage.ErrTrackExists
+		}
+		var exists bool
+		err = tx.Get(&exists, queries.SongExists, songID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrSongNotFound
+		}
+
+		number := number
+		if number < 1 || number > len(songIDs) {
+			number = len(songIDs) + 1
+		} else {
+			_, err = tx.Exec(queries.ShiftTracksDown, albumID, number)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(queries.InsertTrack, albumID, songID, number)
+		return err
+	})
+	return wrap(ctx, op, err)
+}
+
+func (s *Storage) DetachTrack(ctx context.Context, albumID uint, songID uint) error {
+	const op = "storage.postgres.DetachTrack"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.WithTx(ctx, func(tx *Tx) error {
+		if _, err := lockTracks(tx, albumID); err != nil {
+			return err
+		}
+		var number int
+		err := tx.Get(&number, queries.DeleteTrack, albumID, songID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storage.ErrTrackNotFound
+			}
+			return err
+		}
+		_, err = tx.Exec(queries.ShiftTracksUp, albumID, number)
+		return err
+	})
+	return wrap(ctx, op, err)
+}
+
+func (s *Storage) ReorderTracks(ctx context.Context, albumID uint, songIDs []uint) error {
+	const op = "storage.postgres.ReorderTracks"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.WithTx(ctx, func(tx *Tx) error {
+		current, err := lockTracks(tx, albumID)
+		if err != nil {
+			return err
+		}
+		if !samePermutation(current, songIDs) {
+			return storage.ErrInvalidTrackOrder
+		}
+		_, err = tx.Exec(queries.ReorderTracks, albumID, pq.Array(uintsToInt64(songIDs)))
+		return err
+	})
+	return wrap(ctx, op, err)
+}
+
+// lockTracks locks the album for the rest of the transaction and returns the
+// IDs of its songs.
+func lockTracks(tx *Tx, albumID uint) ([]uint, error) {
+	var id uint
+	err := tx.Get(&id, queries.LockAlbum, albumID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrAlbumNotFound
+		}
+		return nil, err
+	}
+	var songIDs []uint
+	err = tx.Select(&songIDs, queries.GetTrackSongIDs, albumID)
+	if err != nil {
+		return nil, err
+	}
+	return songIDs, nil
+}
+
+// samePermutation reports whether b lists exactly the elements of a.
+func samePermutation(a []uint, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"effective-mobile/internal/storage"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// whereBuilder accumulates AND-ed conditions with numbered placeholders.
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg registers a query argument and returns its placeholder.
+func (b *whereBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// where adds a condition; %s verbs in it are replaced by placeholders of values.
+func (b *whereBuilder) where(condition string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, v := range values {
+		placeholders[i] = b.arg(v)
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(condition, placeholders...))
+}
+
+// names adds a condition matching the column against any of the values.
+func (b *whereBuilder) names(column string, values []string, match storage.MatchMode) {
+	if len(values) == 0 {
+		return
+	}
+	if match == storage.MatchExact {
+		b.where(column+" = ANY(%s)", pq.Array(values))
+		return
+	}
+	patterns := make([]string, len(values))
+	for i, v := range values {
+		patterns[i] = escapeLike(v) + "%"
+		if match == storage.MatchContains {
+			patterns[i] = "%" + patterns[i]
+		}
+	}
+	b.where(column+" ILIKE ANY(%s)", pq.Array(patterns))
+}
+
+func (b *whereBuilder) presence(column string, present *bool) {
+	if present == nil {
+		return
+	}
+	if *present {
+		b.where("COALESCE(" + column + ", '') <> ''")
+	} else {
+		b.where("COALESCE(" + column + ", '') = ''")
+	}
+}
+
+// String renders the conditions to be appended after "WHERE 1=1".
+func (b *whereBuilder) String() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " AND " + strings.Join(b.conditions, " AND ")
+}
+
+func songFilterWhere(filter storage.SongFilter) *whereBuilder {
+	b := &whereBuilder{}
+	if len(filter.GroupIDs) > 0 {
+		b.where("group_id = ANY(%s)", pq.Array(uintsToInt64(filter.GroupIDs)))
+	}
+	if len(filter.AlbumIDs) > 0 {
+		b.where("id IN (SELECT song_id FROM album_songs WHERE album_id = ANY(%s))", pq.Array(uintsToInt64(filter.AlbumIDs)))
+	}
+	b.names("group_name", filter.Groups, filter.NameMatch)
+	b.names("song_name", filter.Songs, filter.NameMatch)
+	if len(filter.ReleaseDates) > 0 {
+		b.where("release_date = ANY(%s::date[])", pq.Array(filter.ReleaseDates))
+	}
+	if filter.ReleasedFrom != "" {
+		b.where("release_date >= %s", filter.ReleasedFrom)
+	}
+	if filter.ReleasedTo != "" {
+		b.where("release_date <= %s", filter.ReleasedTo)
+	}
+	b.presence("lyrics", filter.HasLyrics)
+	b.presence("youtube_link", filter.HasLink)
+	for _, word := range filter.LyricsWords {
+		b.where("lyrics ILIKE %s", "%"+escapeLike(word)+"%")
+	}
+	return b
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes user input match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// uintsToInt64 converts IDs to a type pq.Array can encode.
+func uintsToInt64(ids []uint) []int64 {
+	res := make([]int64, len(ids))
+	for i, id := range ids {
+		res[i] = int64(id)
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"effective-mobile/internal/storage"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Codes of the PostgreSQL errors the storage tells apart.
+const (
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+	queryCanceled        = "57014"
+	adminShutdown        = "57P01"
+	crashShutdown        = "57P02"
+	cannotConnectNow     = "57P03"
+	// connectionException is the class of the errors of a broken connection.
+	connectionException = "08"
+)
+
+// wrap turns err into a *storage.Error of op and sorts it into one of the
+// storage failure kinds. Errors that are wrapped already are returned as is.
+// The error also fails the span of the call in ctx.
+func wrap(ctx context.Context, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		return err
+	}
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return &storage.Error{Op: op, Kind: kind(ctx, err), Err: err}
+}
+
+func kind(ctx context.Context, err error) error {
+	var pqErr *pq.Error
+	isPQ := errors.As(err, &pqErr)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return storage.ErrTimeout
+	case errors.Is(err, context.Canceled):
+		return storage.ErrCanceled
+	case isPQ && pqErr.Code == queryCanceled:
+		// lib/pq cancels the statement of a done context on the server,
+		// which reports it as canceled whatever the reason.
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return storage.ErrCanceled
+		}
+		return storage.ErrTimeout
+	case errors.Is(err, sql.ErrNoRows):
+		return storage.ErrNotFound
+	case isPQ && pqErr.Code == uniqueViolation:
+		return storage.ErrExists
+	case isPQ:
+		switch pqErr.Code {
+		case adminShutdown, crashShutdown, cannotConnectNow:
+			return storage.ErrConnectionLost
+		}
+		if pqErr.Code.Class() == connectionException {
+			return storage.ErrConnectionLost
+		}
+		return nil
+	case connectionLost(err):
+		return storage.ErrConnectionLost
+	}
+	return nil
+}
+
+// connectionLost reports whether err comes from a connection that could not
+// be opened or broke during the call.
+func connectionLost(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+func isPQError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"effective-mobile/internal/storage"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestWrap(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-expired.Done()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		kind error
+	}{
+		{name: "deadline", err: context.DeadlineExceeded, kind: storage.ErrTimeout},
+		{name: "wrapped deadline", err: fmt.Errorf("begin: %w", context.DeadlineExceeded), kind: storage.ErrTimeout},
+		{name: "canceled", err: context.Canceled, kind: storage.ErrCanceled},
+		{name: "statement canceled by a timeout", ctx: expired, err: &pq.Error{Code: queryCanceled}, kind: storage.ErrTimeout},
+		{name: "statement canceled by the caller", ctx: canceled, err: &pq.Error{Code: queryCanceled}, kind: storage.ErrCanceled},
+		{name: "statement canceled on the server", err: &pq.Error{Code: queryCanceled}, kind: storage.ErrTimeout},
+		{name: "no rows", err: sql.ErrNoRows, kind: storage.ErrNotFound},
+		{name: "unique violation", err: &pq.Error{Code: uniqueViolation}, kind: storage.ErrExists},
+		{name: "admin shutdown", err: &pq.Error{Code: adminShutdown}, kind: storage.ErrConnectionLost},
+		{name: "crash shutdown", err: &pq.Error{Code: crashShutdown}, kind: storage.ErrConnectionLost},
+		{name: "cannot connect now", err: &pq.Error{Code: cannotConnectNow}, kind: storage.ErrConnectionLost},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, kind: storage.ErrConnectionLost},
+		{name: "bad connection", err: driver.ErrBadConn, kind: storage.ErrConnectionLost},
+		{name: "connection done", err: sql.ErrConnDone, kind: storage.ErrConnectionLost},
+		{name: "eof", err: io.EOF, kind: storage.ErrConnectionLost},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, kind: storage.ErrConnectionLost},
+		{name: "network", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, kind: storage.ErrConnectionLost},
+		{name: "foreign key violation", err: &pq.Error{Code: foreignKeyViolation}, kind: nil},
+		{name: "serialization failure", err: &pq.Error{Code: serializationFailure}, kind: nil},
+		{name: "other", err: errors.New("boom"), kind: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			err := wrap(ctx, "op", tt.err)
+			var storageErr *storage.Error
+			if !errors.As(err, &storageErr) {
+				t.Fatalf("error = %v, want a *storage.Error", err)
+			}
+			if storageErr.Kind != tt.kind {
+				t.Errorf("kind = %v, want %v", storageErr.Kind, tt.kind)
+			}
+			if storageErr.Op != "op" || !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v wrapped by op", err, tt.err)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		if err := wrap(context.Background(), "op", nil); err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+	})
+	t.Run("wrapped already", func(t *testing.T) {
+		inner := &storage.Error{Op: "inner", Kind: storage.ErrNotFound, Err: sql.ErrNoRows}
+		err := wrap(context.Background(), "outer", fmt.Errorf("tx: %w", inner))
+		var storageErr *storage.Error
+		if !errors.As(err, &storageErr) || storageErr != inner {
+			t.Errorf("error = %v, want the inner error", err)
+		}
+	})
+}
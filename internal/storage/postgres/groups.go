@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/postgres/queries"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type groupRow struct {
+	storage.Group
+	Genres pq.StringArray `db:"genres"`
+}
+
+func (r groupRow) group() storage.Group {
+	group := r.Group
+	group.Genres = []string(r.Genres)
+	if group.Genres == nil {
+		group.Genres = []string{}
+	}
+	return group
+}
+
+func (s *Storage) InsertGroup(ctx context.Context, group storage.Group) (uint, error) {
+	const op = "storage.postgres.InsertGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	genres := group.Genres
+	if genres == nil {
+		genres = []string{}
+	}
+	var id uint
+	err := s.db.QueryRowContext(ctx, queries.InsertGroup,
+		group.Name, group.Country, group.FormedYear, pq.Array(genres), group.Description,
+	).Scan(&id)
+	if isPQError(err, uniqueViolation) {
+		err = storage.ErrGroupExists
+	}
+	if err != nil {
+		return 0, wrap(ctx, op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) SelectGroups(ctx context.Context, limit int, offset int) ([]storage.Group, int, error) {
+	const op = "storage.postgres.SelectGroups"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var total int
+	err := s.db.GetContext(ctx, &total, queries.CountGroups)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	var rows []groupRow
+	err = s.db.SelectContext(ctx, &rows, queries.GetGroups, limit, offset)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	groups := make([]storage.Group, len(rows))
+	for i, row := range rows {
+		groups[i] = row.group()
+	}
+	return groups, total, nil
+}
+
+func (s *Storage) GetGroup(ctx context.Context, id uint) (storage.Group, error) {
+	const op = "storage.postgres.GetGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var row groupRow
+	err := s.db.GetContext(ctx, &row, queries.GetGroup, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = storage.ErrGroupNotFound
+	}
+	if err != nil {
+		return storage.Group{}, wrap(ctx, op, err)
+	}
+	return row.group(), nil
+}
+
+func (s *Storage) UpdateGroup(ctx context.Context, id uint, update storage.GroupUpdate) error {
+	const op = "storage.postgres.UpdateGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	if update.IsEmpty() {
+		return wrap(ctx, op, errors.New("no fields to update"))
+	}
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+
+	var setClauses []string
+	var params []interface{}
+	set := func(clause string, value interface{}) {
+		params = append(params, value)
+		setClauses = append(setClauses, fmt.Sprintf(clause, len(params)))
+	}
+	if update.Name != nil {
+		set("name = $%d", *update.Name)
+	}
+	if update.Country != nil {
+		set("country = NULLIF($%d, '')", *update.Country)
+	}
+	if update.FormedYear != nil {
+		set("formed_year = NULLIF($%d, 0)", *update.FormedYear)
+	}
+	if update.Genres != nil {
+		genres := *update.Genres
+		if genres == nil {
+			genres = []string{}
+		}
+		set("genres = $%d", pq.Array(genres))
+	}
+	if update.Description != nil {
+		set("description = NULLIF($%d, '')", *update.Description)
+	}
+
+	params = append(params, id)
+	query := queries.UpdateGroup + strings.Join(setClauses, ", ") + " WHERE id = $" + strconv.Itoa(len(params))
+	res, err := s.db.ExecContext(ctx, query, params...)
+	if isPQError(err, uniqueViolation) {
+		err = storage.ErrGroupExists
+	}
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+	return wrap(ctx, op, checkAffected(res, storage.ErrGroupNotFound))
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, id uint) error {
+	const op = "storage.postgres.DeleteGroup"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, queries.DeleteGroup, id)
+	if isPQError(err, foreignKeyViolation) {
+		err = storage.ErrGroupInUse
+	}
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+	return wrap(ctx, op, checkAffected(res, storage.ErrGroupNotFound))
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// observe records how long the call op, started at start, took.
+func (s *Storage) observe(op string, start time.Time) {
+	s.callDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+}
+
+// Collectors returns the latency of the storage calls by op and the
+// statistics of the connection pool, for registration with Prometheus.
+func (s *Storage) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		s.callDuration,
+		collectors.NewDBStatsCollector(s.db.DB.DB, "postgres"),
+	}
+}
+
+func newCallDuration() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "storage_call_duration_seconds",
+		Help:    "Duration of storage calls by operation, transactions and retries included.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"op"})
+}
@@ -0,0 +1,250 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/postgres/queries"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+func (s *Storage) InsertPlaylist(ctx context.Context, playlist storage.Playlist) (uint, error) {
+	const op = "storage.postgres.InsertPlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var id uint
+	err := s.db.QueryRowContext(ctx, queries.InsertPlaylist, playlist.Name, playlist.Description, playlist.Owner, playlist.Public).Scan(&id)
+	if err != nil {
+		return 0, wrap(ctx, op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) SelectPlaylists(ctx context.Context, filter storage.PlaylistFilter, limit int, offset int) ([]storage.Playlist, int, error) {
+	const op = "storage.postgres.SelectPlaylists"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var total int
+	err := s.db.GetContext(ctx, &total, queries.CountPlaylists, filter.Owner, filter.Viewer)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	var playlists []storage.Playlist
+	err = s.db.SelectContext(ctx, &playlists, queries.GetPlaylists, filter.Owner, filter.Viewer, limit, offset)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	return playlists, total, nil
+}
+
+func (s *Storage) GetPlaylist(ctx context.Context, id uint) (storage.Playlist, error) {
+	const op = "storage.postgres.GetPlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var playlist storage.Playlist
+	err := s.db.GetContext(ctx, &playlist, queries.GetPlaylist, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = storage.ErrPlaylistNotFound
+	}
+	if err != nil {
+		return storage.Playlist{}, wrap(ctx, op, err)
+	}
+	return playlist, nil
+}
+
+func (s *Storage) UpdatePlaylist(ctx context.Context, id uint, update storage.PlaylistUpdate) error {
+	const op = "storage.postgres.UpdatePlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	if update.IsEmpty() {
+		return wrap(ctx, op, errors.New("no fields to update"))
+	}
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+
+	var setClauses []string
+	var params []interface{}
+	set := func(clause string, value interface{}) {
+		params = append(params, value)
+		setClauses = append(setClauses, fmt.Sprintf(clause, len(params)))
+	}
+	if update.Name != nil {
+		set("name = $%d", *update.Name)
+	}
+	if update.Description != nil {
+		set("description = NULLIF($%d, '')", *update.Description)
+	}
+	if update.Public != nil {
+		set("public = $%d", *update.Public)
+	}
+
+	params = append(params, id)
+	query := queries.UpdatePlaylist + strings.Join(setClauses, ", ") + " WHERE id = $" + strconv.Itoa(len(params))
+	res, err := s.db.ExecContext(ctx, query, params...)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+	return wrap(ctx, op, checkAffected(res, storage.ErrPlaylistNotFound))
+}
+
+func (s *Storage) DeletePlaylist(ctx context.Context, id uint) error {
+	const op = "storage.postgres.DeletePlaylist"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, queries.DeletePlaylist, id)
+	if err != nil {
+		return wrap(ctx, op, err)
+	}
+	return wrap(ctx, op, checkAffected(res, storage.ErrPlaylistNotFound))
+}
+
+func (s *Storage) SelectEntries(ctx context.Context, playlistID uint) ([]storage.Entry, error) {
+	const op = "storage.postgres.SelectEntries"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	if _, err := s.GetPlaylist(ctx, playlistID); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var entries []storage.Entry
+	err := s.db.SelectContext(ctx, &entries, queries.GetEntries, playlistID)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	return entries, nil
+}
+
+func (s *Storage) AddEntry(ctx context.Context, playlistID uint, songID uint, position int) error {
+	const op = "storage.postgres.AddEntry"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.WithTx(ctx, func(tx *Tx) error {
+		count, err := lockEntries(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		var current int
+		err = tx.Get(&current, queries.GetEntryPosition, playlistID, songID)
+		if err == nil {
+			return storage.ErrEntryExists
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		var exists bool
+		err = tx.Get(&exists, queries.SongExists, songID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrSongNotFound
+		}
+
+		position := position
+		if position < 1 || position > count {
+			position = count + 1
+		} else {
+			_, err = tx.Exec(queries.ShiftEntriesDown, playlistID, position)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(queries.InsertEntry, playlistID, songID, position)
+		return err
+	})
+	return wrap(ctx, op, err)
+}
+
+func (s *Storage) RemoveEntry(ctx context.Context, playlistID uint, songID uint) error {
+	const op = "storage.postgres.RemoveEntry"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.WithTx(ctx, func(tx *Tx) error {
+		if _, err := lockEntries(tx, playlistID); err != nil {
+			return err
+		}
+		var position int
+		err := tx.Get(&position, queries.DeleteEntry, playlistID, songID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storage.ErrEntryNotFound
+			}
+			return err
+		}
+		_, err = tx.Exec(queries.ShiftEntriesUp, playlistID, position)
+		return err
+	})
+	return wrap(ctx, op, err)
+}
+
+func (s *Storage) MoveEntry(ctx context.Context, playlistID uint, songID uint, position int) error {
+	const op = "storage.postgres.MoveEntry"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.WithTx(ctx, func(tx *Tx) error {
+		count, err := lockEntries(tx, playlistID)
+		if err != nil {
+			return err
+		}
+		var current int
+		err = tx.Get(&current, queries.GetEntryPosition, playlistID, songID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storage.ErrEntryNotFound
+			}
+			return err
+		}
+		position := position
+		if position < 1 || position > count {
+			position = count
+		}
+		if position == current {
+			return nil
+		}
+		_, err = tx.Exec(queries.MoveEntry, playlistID, songID, current, position)
+		return err
+	})
+	return wrap(ctx, op, err)
+}
+
+// lockEntries locks the playlist for the rest of the transaction and returns
+// the number of its entries.
+func lockEntries(tx *Tx, playlistID uint) (int, error) {
+	var id uint
+	err := tx.Get(&id, queries.LockPlaylist, playlistID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrPlaylistNotFound
+		}
+		return 0, err
+	}
+	var count int
+	err = tx.Get(&count, queries.CountEntries, playlistID)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -3,150 +3,747 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
 	"effective-mobile/internal/storage/postgres/queries"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Storage struct {
-	db *sqlx.DB
+	db           tracedDB
+	cfg          Config
+	callDuration *prometheus.HistogramVec
 }
 
-var ErrSongNotFound = errors.New("song not found")
-
-type Song struct {
-	ID          uint   `db:"id"`
-	GroupName   string `db:"group_name"`
-	SongName    string `db:"song_name"`
-	ReleaseDate string `db:"release_date"`
-	Lyrics      string `db:"lyrics"`
-	YoutubeLink string `db:"youtube_link"`
+// Config tunes the connection to the database.
+type Config struct {
+	// QueryTimeout bounds every call, or every attempt of a transaction;
+	// zero leaves calls bounded only by their context.
+	QueryTimeout time.Duration
+	// MaxOpenConns and MaxIdleConns size the connection pool; zero means
+	// no limit on open and the database/sql default on idle connections.
+	MaxOpenConns int
+	MaxIdleConns int
+	// ConnMaxLifetime and ConnMaxIdleTime retire pooled connections; zero
+	// keeps them forever.
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ConnectBackoffBase is the delay before the second attempt of Connect;
+	// it doubles on every failed attempt up to ConnectBackoffMax.
+	ConnectBackoffBase time.Duration
+	ConnectBackoffMax  time.Duration
 }
+
+var _ storage.Storage = (*Storage)(nil)
+
+var (
+	ErrSongNotFound = storage.ErrSongNotFound
+	ErrSongExists   = storage.ErrSongExists
+)
+
+type Song = storage.Song
+
 type Lyrics struct {
 	Lyrics string `db:"lyrics"`
 }
 
-func New(storagePath string) (*Storage, error) {
+// New sets up the connection pool without connecting; Connect waits for the
+// database to come up.
+func New(ctx context.Context, storagePath string, cfg Config) (*Storage, error) {
 	const op = "storage.postgres.New"
-	slog.Log(context.TODO(), slog.LevelInfo, op)
-	db := sqlx.MustConnect("postgres", storagePath)
-	return &Storage{db: db}, nil
+	logctx.From(ctx, slog.Default()).Info(op)
+	db, err := sqlx.Open("postgres", storagePath)
+	if err != nil {
+		return nil, wrap(ctx, op, err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	if cfg.ConnectBackoffBase <= 0 {
+		cfg.ConnectBackoffBase = time.Second
+	}
+	if cfg.ConnectBackoffMax < cfg.ConnectBackoffBase {
+		cfg.ConnectBackoffMax = cfg.ConnectBackoffBase
+	}
+	return &Storage{db: tracedDB{db}, cfg: cfg, callDuration: newCallDuration()}, nil
+}
+
+// Connect pings the database until it answers, backing off exponentially
+// between the attempts. It gives up only when ctx is done, so a database
+// that starts after the service is waited for.
+func (s *Storage) Connect(ctx context.Context) error {
+	const op = "storage.postgres.Connect"
+	logctx.From(ctx, slog.Default()).Info(op)
+	delay := s.cfg.ConnectBackoffBase
+	for attempt := 1; ; attempt++ {
+		err := s.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return wrap(ctx, op, ctx.Err())
+		}
+		logctx.From(ctx, slog.Default()).Warn(op+": database unreachable",
+			slog.Int("attempt", attempt),
+			slog.String("delay", delay.String()),
+			slog.Any("error", err),
+		)
+		select {
+		case <-ctx.Done():
+			return wrap(ctx, op, ctx.Err())
+		case <-time.After(delay + rand.N(delay/5+1)):
+		}
+		delay = min(delay*2, s.cfg.ConnectBackoffMax)
+	}
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.postgres.Ping"
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	return wrap(ctx, op, s.db.PingContext(ctx))
+}
+
+// SchemaVersion returns the version of the newest migration applied to the
+// database.
+func (s *Storage) SchemaVersion(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.SchemaVersion"
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var version int64
+	if err := s.db.GetContext(ctx, &version, queries.GetSchemaVersion); err != nil {
+		return 0, wrap(ctx, op, err)
+	}
+	return version, nil
 }
 
 func (s *Storage) Stop() error {
 	const op = "storage.postgres.Stop"
-	slog.Log(context.TODO(), slog.LevelInfo, op)
-	return s.db.Close()
+	slog.Log(context.Background(), slog.LevelInfo, op)
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
 }
 
-func (s *Storage) InsertSong(song Song) error {
+func (s *Storage) InsertSong(ctx context.Context, song Song, actor storage.Actor) (uint, error) {
 	const op = "storage.postgres.InsertSong"
-	slog.Log(context.TODO(), slog.LevelInfo, op)
-	tx := s.db.MustBegin()
-	var args []interface{}
-	args = append(args, song.GroupName, song.SongName, song.ReleaseDate, song.Lyrics, song.YoutubeLink)
-	tx.MustExec(
-		queries.InsertSong, args...,
-	)
-	err := tx.Commit()
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	var id uint
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		groupID, err := ensureGroup(tx, song.GroupName)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRowx(
+			queries.InsertSong, groupID, song.SongName, song.ReleaseDate, song.Lyrics, song.YoutubeLink,
+		).Scan(&id)
+		return songConflict(err)
+	})
+	if err != nil {
+		return 0, wrap(ctx, op, err)
+	}
+	return id, nil
+}
+
+func (s *Storage) UpsertSong(ctx context.Context, song Song, actor storage.Actor) (uint, bool, error) {
+	const op = "storage.postgres.UpsertSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	var res struct {
+		ID      uint `db:"id"`
+		Created bool `db:"created"`
+	}
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		groupID, err := ensureGroup(tx, song.GroupName)
+		if err != nil {
+			return err
+		}
+		return tx.QueryRowx(queries.UpsertSong, groupID, song.SongName, song.ReleaseDate, song.Lyrics, song.YoutubeLink).StructScan(&res)
+	})
 	if err != nil {
+		return 0, false, wrap(ctx, op, err)
+	}
+	return res.ID, res.Created, nil
+}
+
+func (s *Storage) InsertSongs(ctx context.Context, songs []Song, upsert bool, actor storage.Actor) ([]storage.SongBatchResult, error) {
+	const op = "storage.postgres.InsertSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	var results []storage.SongBatchResult
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		var err error
+		results, err = insertSongs(tx, songs, upsert)
 		return err
+	})
+	if err != nil {
+		return nil, wrap(ctx, op, err)
 	}
-	return nil
+	return results, nil
+}
+
+func insertSongs(tx *Tx, songs []Song, upsert bool) ([]storage.SongBatchResult, error) {
+	groups := make(map[string]uint)
+	var groupIDs []int64
+	var names, dates, lyrics, links []string
+	for _, song := range songs {
+		key := storage.NormalizeName(song.GroupName)
+		groupID, ok := groups[key]
+		if !ok {
+			var err error
+			if groupID, err = ensureGroup(tx, song.GroupName); err != nil {
+				return nil, err
+			}
+			groups[key] = groupID
+		}
+		groupIDs = append(groupIDs, int64(groupID))
+		names = append(names, song.SongName)
+		dates = append(dates, song.ReleaseDate)
+		lyrics = append(lyrics, song.Lyrics)
+		links = append(links, song.YoutubeLink)
+	}
+
+	query := queries.InsertSongs
+	if upsert {
+		query = queries.UpsertSongs
+	}
+	rows, err := tx.Queryx(query, pq.Array(groupIDs), pq.Array(names), pq.Array(dates), pq.Array(lyrics), pq.Array(links))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type songKey struct {
+		groupID uint
+		name    string
+	}
+	index := make(map[songKey]int, len(songs))
+	for i := range songs {
+		index[songKey{uint(groupIDs[i]), names[i]}] = i
+	}
+	results := make([]storage.SongBatchResult, len(songs))
+	for rows.Next() {
+		var row struct {
+			ID       uint   `db:"id"`
+			GroupID  uint   `db:"group_id"`
+			SongName string `db:"song_name"`
+			Created  bool   `db:"created"`
+		}
+		if err := rows.StructScan(&row); err != nil {
+			return nil, err
+		}
+		results[index[songKey{row.GroupID, row.SongName}]] = storage.SongBatchResult{ID: row.ID, Created: row.Created}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (s *Storage) SelectSongs(query string, args []interface{}) ([]Song, error) {
+// sortColumns maps the sort fields to the ORDER BY terms. Text is compared
+// by bytes under the "C" collation, whatever the database collation is, to
+// order songs like the memory store.
+var sortColumns = map[string]string{
+	storage.SortByID:          "id",
+	storage.SortByGroup:       `group_name COLLATE "C"`,
+	storage.SortBySong:        `song_name COLLATE "C"`,
+	storage.SortByReleaseDate: "release_date",
+	storage.SortByLyrics:      `lyrics COLLATE "C"`,
+	storage.SortByYoutubeLink: `youtube_link COLLATE "C"`,
+}
+
+func (s *Storage) SelectSongs(ctx context.Context, filter storage.SongFilter, page storage.Page) ([]Song, int, error) {
 	const op = "storage.postgres.SelectSongs"
-	slog.Log(context.TODO(), slog.LevelInfo, op)
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	if err := storage.ValidatePage(page); err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+
+	b := songFilterWhere(filter)
+	where, args := b.String(), b.args
+
+	var total int
+	err := s.db.GetContext(ctx, &total, queries.CountLibrary+where, args...)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+
+	// Walking backwards from a cursor reads the rows in reverse order and
+	// flips them afterwards.
+	backwards := page.BeforeID != 0
+	_, idDesc := page.KeysetSort()
+	if page.AfterID != 0 {
+		args = append(args, page.AfterID)
+		where += fmt.Sprintf(" AND id %s $%v", keysetOperator(idDesc), len(args))
+	}
+	if backwards {
+		args = append(args, page.BeforeID)
+		where += fmt.Sprintf(" AND id %s $%v", keysetOperator(!idDesc), len(args))
+	}
+
+	var orderBy []string
+	hasID := false
+	for _, sort := range page.Sort {
+		orderBy = append(orderBy, sortColumns[sort.Field]+" "+direction(sort.Desc != backwards))
+		hasID = hasID || sort.Field == storage.SortByID
+	}
+	if !hasID {
+		orderBy = append(orderBy, "id "+direction(backwards))
+	}
+
+	query := queries.GetLibrary + where + " ORDER BY " + strings.Join(orderBy, ", ")
+	if page.Limit > 0 {
+		args = append(args, page.Limit)
+		query += fmt.Sprintf(" LIMIT $%v", len(args))
+	}
+	if page.Offset > 0 {
+		args = append(args, page.Offset)
+		query += fmt.Sprintf(" OFFSET $%v", len(args))
+	}
+
 	var songs []Song
-	query += " LIMIT 5"
-	err := s.db.Select(&songs, query, args...)
+	err = s.db.SelectContext(ctx, &songs, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, wrap(ctx, op, err)
+	}
+	if backwards {
+		slices.Reverse(songs)
 	}
-	return songs, nil
+	return songs, total, nil
 }
 
-func (s *Storage) DeleteSong(song string, group string) error {
-	const op = "storage.postgres.DeleteSong"
-	slog.Log(context.TODO(), slog.LevelInfo, op)
-	res, err := s.db.Exec(queries.DeleteSong, group, song)
+// exportBatchSize is the number of rows fetched from the export cursor at once.
+const exportBatchSize = 500
+
+// ExportSongs holds a transaction open for as long as the export runs, so
+// the query timeout bounds every statement rather than the whole call.
+func (s *Storage) ExportSongs(ctx context.Context, filter storage.SongFilter, fn func(Song) error) error {
+	const op = "storage.postgres.ExportSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	// A cursor only lives inside a transaction.
+	tx, err := s.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
 	if err != nil {
-		return err
+		return wrap(ctx, op, err)
 	}
-	rowsAffected, err := res.RowsAffected()
+	defer tx.Rollback()
+	exec := func(query func(tx *Tx) error) error {
+		ctx, cancel := s.timeout(ctx)
+		defer cancel()
+		return wrap(ctx, op, query(&Tx{tx: tx, ctx: ctx}))
+	}
+
+	b := songFilterWhere(filter)
+	err = exec(func(tx *Tx) error {
+		_, err := tx.Exec(queries.DeclareExport+b.String()+" ORDER BY id", b.args...)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+		return err
+	}
+	fetch := fmt.Sprintf(queries.FetchExport, exportBatchSize)
+	for {
+		var songs []Song
+		err := exec(func(tx *Tx) error {
+			return tx.Select(&songs, fetch)
+		})
+		if err != nil {
+			return err
+		}
+		for _, song := range songs {
+			if err := fn(song); err != nil {
+				return err
+			}
+		}
+		if len(songs) < exportBatchSize {
+			return nil
+		}
 	}
+}
 
-	if rowsAffected == 0 {
-		return ErrSongNotFound
+func keysetOperator(desc bool) string {
+	if desc {
+		return "<"
 	}
+	return ">"
+}
 
-	return nil
+func direction(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+func (s *Storage) DeleteSong(ctx context.Context, song string, group string, version int, actor storage.Actor) error {
+	const op = "storage.postgres.DeleteSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		return trashSong(tx, version, queries.LockSongByName, song, group)
+	})
+	return wrap(ctx, op, err)
 }
 
-func (s *Storage) GetLyrics(song string, group string) (string, error) {
+func (s *Storage) GetLyrics(ctx context.Context, song string, group string) (string, error) {
 	const op = "storage.postgres.GetLyrics"
-	slog.Log(context.TODO(), slog.LevelInfo, op)
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
 	var lyrics Lyrics
-	err := s.db.Get(&lyrics, queries.GetLyrics, song, group)
+	err := s.db.GetContext(ctx, &lyrics, queries.GetLyrics, song, group)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrSongNotFound
+	}
+	if err != nil {
+		return "", wrap(ctx, op, err)
+	}
+	return lyrics.Lyrics, nil
+}
 
+type searchRow struct {
+	Song
+	Rank     float64 `db:"rank"`
+	Snippets []byte  `db:"snippets"`
+}
+
+func (s *Storage) SearchSongs(ctx context.Context, query string, limit int, offset int) ([]storage.SearchResult, int, error) {
+	const op = "storage.postgres.SearchSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var total int
+	err := s.db.GetContext(ctx, &total, queries.CountSearchSongs, query)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", err
+		return nil, 0, wrap(ctx, op, err)
+	}
+
+	var rows []searchRow
+	err = s.db.SelectContext(ctx, &rows, queries.SearchSongs, query, limit, offset)
+	if err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+
+	results := make([]storage.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		result := storage.SearchResult{Song: row.Song, Rank: row.Rank}
+		if err := json.Unmarshal(row.Snippets, &result.Snippets); err != nil {
+			return nil, 0, wrap(ctx, op, fmt.Errorf("failed to decode snippets: %w", err))
 		}
-		return "", err
+		results = append(results, result)
 	}
-	return lyrics.Lyrics, nil
+	return results, total, nil
 }
 
-func (s *Storage) UpdateSong(firstSong, firstGroup, song string, group string, releaseDate string) error {
+func (s *Storage) UpdateSong(ctx context.Context, song string, group string, update storage.SongUpdate, version int, actor storage.Actor) error {
 	const op = "storage.postgres.UpdateSong"
-	slog.Log(context.TODO(), slog.LevelInfo, op)
-	query := queries.UpdateSong
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	if update.IsEmpty() {
+		return wrap(ctx, op, errors.New("no fields to update"))
+	}
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		id, err := lockSong(tx, version, queries.LockSongByName, song, group)
+		if err != nil {
+			return err
+		}
+		return updateSong(tx, id, update)
+	})
+	return wrap(ctx, op, err)
+}
+
+func (s *Storage) GetSong(ctx context.Context, id uint) (Song, error) {
+	const op = "storage.postgres.GetSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var song Song
+	err := s.db.GetContext(ctx, &song, queries.GetSong, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrSongNotFound
+	}
+	if err != nil {
+		return Song{}, wrap(ctx, op, err)
+	}
+	return song, nil
+}
+
+func (s *Storage) ReplaceSong(ctx context.Context, id uint, song Song, version int, actor storage.Actor) error {
+	const op = "storage.postgres.ReplaceSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		if _, err := lockSong(tx, version, queries.LockSong, id); err != nil {
+			return err
+		}
+		groupID, err := ensureGroup(tx, song.GroupName)
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(queries.ReplaceSong, groupID, song.SongName, song.ReleaseDate, song.Lyrics, song.YoutubeLink, id)
+		if err != nil {
+			return songConflict(err)
+		}
+		return checkAffected(res, ErrSongNotFound)
+	})
+	return wrap(ctx, op, err)
+}
+
+func (s *Storage) UpdateSongByID(ctx context.Context, id uint, update storage.SongUpdate, version int, actor storage.Actor) error {
+	const op = "storage.postgres.UpdateSongByID"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	if update.IsEmpty() {
+		return wrap(ctx, op, errors.New("no fields to update"))
+	}
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		if _, err := lockSong(tx, version, queries.LockSong, id); err != nil {
+			return err
+		}
+		return updateSong(tx, id, update)
+	})
+	return wrap(ctx, op, err)
+}
+
+// updateSong applies a partial update to the song locked in tx.
+func updateSong(tx *Tx, id uint, update storage.SongUpdate) error {
 	var setClauses []string
 	var params []interface{}
-	count := 1
-
-	if group != "" {
-		setClauses = append(setClauses, fmt.Sprintf("group_name = $%d", count))
-		params = append(params, group)
-		count++
+	set := func(column string, value interface{}) {
+		params = append(params, value)
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, len(params)))
+	}
+	// Empty values clear the nullable columns.
+	setNullable := func(column string, value string, cast string) {
+		params = append(params, value)
+		setClauses = append(setClauses, fmt.Sprintf("%s = NULLIF($%d, '')%s", column, len(params), cast))
+	}
+	if update.GroupName != nil {
+		groupID, err := ensureGroup(tx, *update.GroupName)
+		if err != nil {
+			return err
+		}
+		set("group_id", groupID)
+	}
+	if update.SongName != nil {
+		set("song_name", *update.SongName)
+	}
+	if update.ReleaseDate != nil {
+		setNullable("release_date", *update.ReleaseDate, "::date")
 	}
-	if song != "" {
-		setClauses = append(setClauses, fmt.Sprintf("song_name = $%d", count))
-		params = append(params, song)
-		count++
+	if update.YoutubeLink != nil {
+		setNullable("youtube_link", *update.YoutubeLink, "")
 	}
-	if releaseDate != "" {
-		t, err := time.Parse("02.01.2006", releaseDate)
+	lyrics := update.Lyrics
+	if len(update.Verses) > 0 {
+		var current string
+		if err := tx.Get(&current, queries.GetSongLyrics, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrSongNotFound
+			}
+			return err
+		}
+		edited, err := storage.EditVerses(current, update.Verses)
 		if err != nil {
-			fmt.Errorf("%s: %v", op, err)
+			return err
 		}
-		formattedDate := t.Format("2006-01-02")
-		setClauses = append(setClauses, fmt.Sprintf("release_date = $%d", count))
-		params = append(params, formattedDate)
-		count++
+		lyrics = &edited
+	}
+	if lyrics != nil {
+		setNullable("lyrics", *lyrics, "")
 	}
 
-	if len(setClauses) == 0 {
-		return fmt.Errorf("%s: no fields to update", op)
+	params = append(params, id)
+	query := queries.UpdateSong + strings.Join(setClauses, ", ") + " WHERE deleted_at IS NULL AND id = $" + strconv.Itoa(len(params))
+	res, err := tx.Exec(query, params...)
+	if err != nil {
+		return songConflict(err)
 	}
+	return checkAffected(res, ErrSongNotFound)
+}
+
+func (s *Storage) DeleteSongByID(ctx context.Context, id uint, version int, actor storage.Actor) error {
+	const op = "storage.postgres.DeleteSongByID"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		return trashSong(tx, version, queries.LockSong, id)
+	})
+	return wrap(ctx, op, err)
+}
 
-	query += strings.Join(setClauses, ", ")
-	query += " WHERE song_name = $" + strconv.Itoa(count) + " AND group_name = $" + strconv.Itoa(count+1)
-	params = append(params, firstSong, firstGroup)
-	_, err := s.db.Exec(query, params...)
+// trashSong moves the song the lock query selects to the trash and takes it
+// off its albums and playlists.
+func trashSong(tx *Tx, version int, lock string, args ...interface{}) error {
+	id, err := lockSong(tx, version, lock, args...)
 	if err != nil {
 		return err
 	}
+	if _, err := tx.Exec(queries.TrashSong, id); err != nil {
+		return err
+	}
+	var tracks []struct {
+		AlbumID     uint `db:"album_id"`
+		TrackNumber int  `db:"track_number"`
+	}
+	if err := tx.Select(&tracks, queries.DetachSongTracks, id); err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		if _, err := tx.Exec(queries.ShiftTracksUp, track.AlbumID, track.TrackNumber); err != nil {
+			return err
+		}
+	}
+	var entries []struct {
+		PlaylistID uint `db:"playlist_id"`
+		Position   int  `db:"position"`
+	}
+	if err := tx.Select(&entries, queries.DetachSongEntries, id); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := tx.Exec(queries.ShiftEntriesUp, entry.PlaylistID, entry.Position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockSong locks the song the query selects for the rest of tx and returns
+// its ID. It fails with ErrVersionMismatch unless version is zero or the
+// version of the song.
+func lockSong(tx *Tx, version int, query string, args ...interface{}) (uint, error) {
+	var locked struct {
+		ID      uint `db:"id"`
+		Version int  `db:"version"`
+	}
+	if err := tx.Get(&locked, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrSongNotFound
+		}
+		return 0, err
+	}
+	if version != 0 && locked.Version != version {
+		return 0, storage.ErrVersionMismatch
+	}
+	return locked.ID, nil
+}
+
+func (s *Storage) SelectTrash(ctx context.Context, limit int, offset int) ([]storage.DeletedSong, int, error) {
+	const op = "storage.postgres.SelectTrash"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var total int
+	if err := s.db.GetContext(ctx, &total, queries.CountTrash); err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	songs := []storage.DeletedSong{}
+	if err := s.db.SelectContext(ctx, &songs, queries.GetTrash, limit, offset); err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	return songs, total, nil
+}
+
+func (s *Storage) RestoreSong(ctx context.Context, id uint, actor storage.Actor) error {
+	const op = "storage.postgres.RestoreSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		res, err := tx.Exec(queries.RestoreSong, id)
+		if err != nil {
+			return songConflict(err)
+		}
+		return checkAffected(res, ErrSongNotFound)
+	})
+	return wrap(ctx, op, err)
+}
+
+func (s *Storage) PurgeSongs(ctx context.Context, deletedBefore time.Time) (int, error) {
+	const op = "storage.postgres.PurgeSongs"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, queries.PurgeSongs, deletedBefore)
+	if err != nil {
+		return 0, wrap(ctx, op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, wrap(ctx, op, fmt.Errorf("failed to retrieve rows affected: %w", err))
+	}
+	return int(n), nil
+}
+
+// checkAffected returns notFound when the statement did not touch any row.
+func checkAffected(res sql.Result, notFound error) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound
+	}
+
 	return nil
 }
+
+// songConflict turns a unique violation on songs into ErrSongExists.
+func songConflict(err error) error {
+	if isPQError(err, uniqueViolation) {
+		return ErrSongExists
+	}
+	return err
+}
+
+// ensureGroup returns the ID of the group with the given name, creating it if needed.
+func ensureGroup(tx *Tx, name string) (uint, error) {
+	var id uint
+	err := tx.Get(&id, queries.EnsureGroup, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve group %q: %w", name, err)
+	}
+	return id, nil
+}
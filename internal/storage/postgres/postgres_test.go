@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/storagetest"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestStorage runs the storage contract against the database at
+// TEST_STORAGE_PATH, which must be migrated with goose and is emptied before
+// every subtest. It is skipped when the variable is not set.
+func TestStorage(t *testing.T) {
+	dsn := os.Getenv("TEST_STORAGE_PATH")
+	if dsn == "" {
+		t.Skip("TEST_STORAGE_PATH is not set")
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s, err := New(ctx, dsn, Config{QueryTimeout: 5 * time.Second})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Stop() })
+		if err := s.Ping(ctx); err != nil {
+			t.Fatalf("Ping: %v", err)
+		}
+		const truncate = "TRUNCATE songs, groups, albums, album_songs, playlists, playlist_songs, song_revisions RESTART IDENTITY CASCADE"
+		if _, err := s.db.ExecContext(ctx, truncate); err != nil {
+			t.Fatalf("failed to empty the database: %v", err)
+		}
+		return s
+	})
+}
@@ -1,7 +1,181 @@
 package queries
 
-const InsertSong = "INSERT INTO songs (group_name, song_name, release_date, lyrics, youtube_link) VALUES ($1, $2, $3, $4, $5)"
-const GetLibrary = "SELECT * FROM songs WHERE 1=1"
-const GetLyrics = "SELECT lyrics FROM songs WHERE song_name = $1 AND group_name = $2"
-const DeleteSong = "DELETE FROM songs WHERE group_name = $1 AND song_name = $2"
+// Songs are read from songs_view, which joins the group name and leaves out
+// the songs in the trash; deleted_songs_view holds the trash. Writes only
+// touch songs that are not deleted. Text is ordered under the "C" collation
+// and a zero LIMIT argument means no limit, as in the memory store.
+
+// SongColumns selects a song row with nullable columns flattened to empty strings.
+const SongColumns = "id, group_id, group_name, song_name, COALESCE(to_char(release_date, 'YYYY-MM-DD'), '') AS release_date, COALESCE(lyrics, '') AS lyrics, COALESCE(youtube_link, '') AS youtube_link, version"
+
+const InsertSong = "INSERT INTO songs (group_id, song_name, release_date, lyrics, youtube_link) VALUES ($1, $2, NULLIF($3, '')::date, $4, $5) RETURNING id"
+
+// UpsertSong inserts a song or overwrites the group's song with the same
+// normalized name. created is false when an existing row was updated.
+const UpsertSong = `
+INSERT INTO songs (group_id, song_name, release_date, lyrics, youtube_link) VALUES ($1, $2, NULLIF($3, '')::date, $4, $5)
+ON CONFLICT (group_id, (lower(btrim(song_name)))) WHERE deleted_at IS NULL DO UPDATE
+SET song_name = EXCLUDED.song_name, release_date = EXCLUDED.release_date, lyrics = EXCLUDED.lyrics, youtube_link = EXCLUDED.youtube_link
+RETURNING id, xmax = 0 AS created`
+
+// InsertSongs inserts the songs given as parallel arrays of group IDs, names,
+// release dates, lyrics and links, skipping the ones that already exist.
+// UpsertSongs overwrites them instead. Both return the inserted name so rows
+// can be matched to the input.
+const InsertSongs = insertSongsFrom + "ON CONFLICT DO NOTHING" + insertSongsReturning
+const UpsertSongs = insertSongsFrom + `ON CONFLICT (group_id, (lower(btrim(song_name)))) WHERE deleted_at IS NULL DO UPDATE
+SET song_name = EXCLUDED.song_name, release_date = EXCLUDED.release_date, lyrics = EXCLUDED.lyrics, youtube_link = EXCLUDED.youtube_link` + insertSongsReturning
+
+const insertSongsFrom = `
+INSERT INTO songs (group_id, song_name, release_date, lyrics, youtube_link)
+SELECT group_id, song_name, NULLIF(release_date, '')::date, lyrics, youtube_link
+FROM unnest($1::integer[], $2::text[], $3::text[], $4::text[], $5::text[]) AS t(group_id, song_name, release_date, lyrics, youtube_link)
+`
+const insertSongsReturning = `
+RETURNING id, group_id, song_name, xmax = 0 AS created`
+
+const GetLibrary = "SELECT " + SongColumns + " FROM songs_view WHERE 1=1"
+
+// DeclareExport opens a cursor over the library; the filter and ORDER BY are
+// appended. FetchExport reads the next %d rows of it; FETCH takes no
+// placeholders.
+const DeclareExport = "DECLARE songs_export NO SCROLL CURSOR FOR " + GetLibrary
+const FetchExport = "FETCH FORWARD %d FROM songs_export"
+
+const CountLibrary = "SELECT COUNT(*) FROM songs_view WHERE 1=1"
+
+// Songs and groups looked up by name are matched on their normalized names,
+// which are indexed.
+const GetLyrics = "SELECT COALESCE(lyrics, '') AS lyrics FROM songs_view WHERE lower(btrim(song_name)) = lower(btrim($1)) AND group_id = (SELECT id FROM groups WHERE lower(btrim(name)) = lower(btrim($2)))"
+
 const UpdateSong = "UPDATE songs SET "
+
+// LockSongByName locks the song named $1 of the group named $2 and returns
+// its ID and version.
+const LockSongByName = "SELECT id, version FROM songs WHERE deleted_at IS NULL AND lower(btrim(song_name)) = lower(btrim($1)) AND group_id = (SELECT id FROM groups WHERE lower(btrim(name)) = lower(btrim($2))) FOR UPDATE"
+const GetSongLyrics = "SELECT COALESCE(lyrics, '') FROM songs WHERE id = $1"
+const GetSong = "SELECT " + SongColumns + " FROM songs_view WHERE id = $1"
+const ReplaceSong = "UPDATE songs SET group_id = $1, song_name = $2, release_date = NULLIF($3, '')::date, lyrics = $4, youtube_link = $5 WHERE id = $6 AND deleted_at IS NULL"
+
+// TrashSong moves a song locked by LockSong or LockSongByName to the trash.
+const TrashSong = "UPDATE songs SET deleted_at = now() WHERE id = $1"
+
+// A deleted song leaves its albums and playlists; the tracks and entries
+// after it move up.
+const DetachSongTracks = "DELETE FROM album_songs WHERE song_id = $1 RETURNING album_id, track_number"
+const DetachSongEntries = "DELETE FROM playlist_songs WHERE song_id = $1 RETURNING playlist_id, position"
+
+const GetTrash = "SELECT " + SongColumns + ", deleted_at FROM deleted_songs_view ORDER BY deleted_at DESC, id DESC LIMIT NULLIF($1, 0) OFFSET $2"
+const CountTrash = "SELECT COUNT(*) FROM deleted_songs_view"
+
+// RestoreSong takes a song out of the trash; it fails with a unique
+// violation when the group got a song with the same name meanwhile.
+const RestoreSong = "UPDATE songs SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+const PurgeSongs = "DELETE FROM songs WHERE deleted_at < $1"
+
+// SetActor names the actor and the request of the transaction's writes for
+// the song history; SetSongAction overrides the action of the next update.
+const SetActor = "SELECT set_config('app.actor', $1, true), set_config('app.request_id', $2, true)"
+const SetSongAction = "SELECT set_config('app.song_action', $1, true)"
+
+const RevisionColumns = "song_id, revision, action, old_values, new_values, actor, request_id, created_at"
+const GetSongHistory = "SELECT " + RevisionColumns + " FROM song_revisions WHERE song_id = $1 ORDER BY revision DESC LIMIT NULLIF($2, 0) OFFSET $3"
+const CountSongHistory = "SELECT COUNT(*) FROM song_revisions WHERE song_id = $1"
+const GetRevisionValues = "SELECT new_values FROM song_revisions WHERE song_id = $1 AND revision = $2"
+
+// LockSong locks a song that is not deleted and returns its ID and version.
+const LockSong = "SELECT id, version FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+const GroupExists = "SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1)"
+
+// SearchSongs ranks songs matching the web search style query $1 and returns
+// the matching verses with highlighted words. $2 and $3 are LIMIT and OFFSET.
+const SearchSongs = `
+SELECT ` + SongColumns + `,
+	ts_rank(search_vector, q) AS rank,
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'verse', v.n,
+			'text', ts_headline('simple', v.verse, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+		) ORDER BY v.n)
+		FROM unnest(string_to_array(lyrics, E'\n\n')) WITH ORDINALITY AS v(verse, n)
+		WHERE to_tsvector('simple', v.verse) @@ q
+	), '[]') AS snippets
+FROM songs_view, websearch_to_tsquery('simple', $1) AS q
+WHERE search_vector @@ q
+ORDER BY rank DESC, id
+LIMIT NULLIF($2, 0) OFFSET $3`
+
+const CountSearchSongs = "SELECT COUNT(*) FROM songs_view WHERE search_vector @@ websearch_to_tsquery('simple', $1)"
+
+// EnsureGroup returns the ID of the group whose normalized name matches $1,
+// creating the group if needed. The no-op update makes RETURNING see the
+// existing row.
+const EnsureGroup = "INSERT INTO groups (name) VALUES ($1) ON CONFLICT ((lower(btrim(name)))) DO UPDATE SET name = groups.name RETURNING id"
+
+const GroupColumns = "id, name, COALESCE(country, '') AS country, COALESCE(formed_year, 0) AS formed_year, genres, COALESCE(description, '') AS description"
+
+const InsertGroup = "INSERT INTO groups (name, country, formed_year, genres, description) VALUES ($1, NULLIF($2, ''), NULLIF($3, 0), $4, NULLIF($5, '')) RETURNING id"
+const GetGroups = "SELECT " + GroupColumns + " FROM groups ORDER BY name COLLATE \"C\", id LIMIT NULLIF($1, 0) OFFSET $2"
+const CountGroups = "SELECT COUNT(*) FROM groups"
+const GetGroup = "SELECT " + GroupColumns + " FROM groups WHERE id = $1"
+const UpdateGroup = "UPDATE groups SET "
+const DeleteGroup = "DELETE FROM groups WHERE id = $1"
+
+const AlbumColumns = "albums.id, albums.title, albums.group_id, groups.name AS group_name, COALESCE(to_char(albums.release_date, 'YYYY-MM-DD'), '') AS release_date, COALESCE(albums.cover_url, '') AS cover_url"
+
+const InsertAlbum = "INSERT INTO albums (title, group_id, release_date, cover_url) VALUES ($1, $2, NULLIF($3, '')::date, NULLIF($4, '')) RETURNING id"
+const GetAlbums = "SELECT " + AlbumColumns + " FROM albums JOIN groups ON groups.id = albums.group_id ORDER BY albums.title COLLATE \"C\", albums.id LIMIT NULLIF($1, 0) OFFSET $2"
+const CountAlbums = "SELECT COUNT(*) FROM albums"
+const GetAlbum = "SELECT " + AlbumColumns + " FROM albums JOIN groups ON groups.id = albums.group_id WHERE albums.id = $1"
+const DeleteAlbum = "DELETE FROM albums WHERE id = $1"
+
+// LockAlbum serializes changes of a tracklist.
+const LockAlbum = "SELECT id FROM albums WHERE id = $1 FOR UPDATE"
+const GetTracks = "SELECT album_songs.track_number, " + SongColumns + " FROM album_songs JOIN songs_view ON songs_view.id = album_songs.song_id WHERE album_songs.album_id = $1 ORDER BY album_songs.track_number"
+const GetTrackSongIDs = "SELECT song_id FROM album_songs WHERE album_id = $1"
+const SongExists = "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL)"
+const ShiftTracksDown = "UPDATE album_songs SET track_number = track_number + 1 WHERE album_id = $1 AND track_number >= $2"
+const InsertTrack = "INSERT INTO album_songs (album_id, song_id, track_number) VALUES ($1, $2, $3)"
+const DeleteTrack = "DELETE FROM album_songs WHERE album_id = $1 AND song_id = $2 RETURNING track_number"
+const ShiftTracksUp = "UPDATE album_songs SET track_number = track_number - 1 WHERE album_id = $1 AND track_number > $2"
+
+// ReorderTracks numbers the tracks of album $1 by the position of their song in the array $2.
+const ReorderTracks = `
+UPDATE album_songs SET track_number = v.n
+FROM unnest($2::int[]) WITH ORDINALITY AS v(song_id, n)
+WHERE album_songs.album_id = $1 AND album_songs.song_id = v.song_id`
+
+const PlaylistColumns = "id, name, COALESCE(description, '') AS description, owner, public"
+
+const InsertPlaylist = "INSERT INTO playlists (name, description, owner, public) VALUES ($1, NULLIF($2, ''), $3, $4) RETURNING id"
+const GetPlaylist = "SELECT " + PlaylistColumns + " FROM playlists WHERE id = $1"
+const UpdatePlaylist = "UPDATE playlists SET "
+const DeletePlaylist = "DELETE FROM playlists WHERE id = $1"
+
+// GetPlaylists and CountPlaylists take the owner ($1, empty for everyone) and the viewer ($2).
+const playlistsWhere = " FROM playlists WHERE ($1 = '' OR owner = $1) AND (public OR ($2 <> '' AND owner = $2))"
+const GetPlaylists = "SELECT " + PlaylistColumns + playlistsWhere + " ORDER BY name COLLATE \"C\", id LIMIT NULLIF($3, 0) OFFSET $4"
+const CountPlaylists = "SELECT COUNT(*)" + playlistsWhere
+
+// LockPlaylist serializes changes of the playlist entries.
+const LockPlaylist = "SELECT id FROM playlists WHERE id = $1 FOR UPDATE"
+const GetEntries = "SELECT playlist_songs.position, " + SongColumns + " FROM playlist_songs JOIN songs_view ON songs_view.id = playlist_songs.song_id WHERE playlist_songs.playlist_id = $1 ORDER BY playlist_songs.position"
+const CountEntries = "SELECT COUNT(*) FROM playlist_songs WHERE playlist_id = $1"
+const GetEntryPosition = "SELECT position FROM playlist_songs WHERE playlist_id = $1 AND song_id = $2"
+const ShiftEntriesDown = "UPDATE playlist_songs SET position = position + 1 WHERE playlist_id = $1 AND position >= $2"
+const InsertEntry = "INSERT INTO playlist_songs (playlist_id, song_id, position) VALUES ($1, $2, $3)"
+const DeleteEntry = "DELETE FROM playlist_songs WHERE playlist_id = $1 AND song_id = $2 RETURNING position"
+const ShiftEntriesUp = "UPDATE playlist_songs SET position = position - 1 WHERE playlist_id = $1 AND position > $2"
+
+// MoveEntry moves song $2 of playlist $1 from position $3 to $4 and shifts
+// the entries in between by one.
+const MoveEntry = `
+UPDATE playlist_songs SET position = CASE
+    WHEN song_id = $2 THEN $4
+    WHEN $4 < $3 THEN position + 1
+    ELSE position - 1
+END
+WHERE playlist_id = $1 AND position BETWEEN LEAST($3, $4) AND GREATEST($3, $4)`
+
+// GetSchemaVersion reads the version of the newest migration goose applied.
+const GetSchemaVersion = "SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied"
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"effective-mobile/internal/lib/logctx"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/postgres/queries"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type revisionRow struct {
+	SongID    uint      `db:"song_id"`
+	Revision  int       `db:"revision"`
+	Action    string    `db:"action"`
+	Old       []byte    `db:"old_values"`
+	New       []byte    `db:"new_values"`
+	Actor     string    `db:"actor"`
+	RequestID string    `db:"request_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (r revisionRow) revision() (storage.SongRevision, error) {
+	revision := storage.SongRevision{
+		SongID:    r.SongID,
+		Revision:  r.Revision,
+		Action:    r.Action,
+		Actor:     r.Actor,
+		RequestID: r.RequestID,
+		CreatedAt: r.CreatedAt,
+	}
+	var err error
+	if revision.Old, err = songValues(r.Old); err != nil {
+		return storage.SongRevision{}, err
+	}
+	if revision.New, err = songValues(r.New); err != nil {
+		return storage.SongRevision{}, err
+	}
+	return revision, nil
+}
+
+// songValues decodes the values of a song as song_revision_values stores them.
+func songValues(b []byte) (*Song, error) {
+	if b == nil {
+		return nil, nil
+	}
+	var song Song
+	if err := json.Unmarshal(b, &song); err != nil {
+		return nil, fmt.Errorf("invalid song revision values: %w", err)
+	}
+	return &song, nil
+}
+
+func (s *Storage) SongHistory(ctx context.Context, id uint, limit int, offset int) ([]storage.SongRevision, int, error) {
+	const op = "storage.postgres.SongHistory"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	var total int
+	if err := s.db.GetContext(ctx, &total, queries.CountSongHistory, id); err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	if total == 0 {
+		return nil, 0, wrap(ctx, op, ErrSongNotFound)
+	}
+	var rows []revisionRow
+	if err := s.db.SelectContext(ctx, &rows, queries.GetSongHistory, id, limit, offset); err != nil {
+		return nil, 0, wrap(ctx, op, err)
+	}
+	revisions := make([]storage.SongRevision, len(rows))
+	for i, row := range rows {
+		revision, err := row.revision()
+		if err != nil {
+			return nil, 0, wrap(ctx, op, err)
+		}
+		revisions[i] = revision
+	}
+	return revisions, total, nil
+}
+
+func (s *Storage) RevertSong(ctx context.Context, id uint, revision int, actor storage.Actor) error {
+	const op = "storage.postgres.RevertSong"
+	logctx.From(ctx, slog.Default()).Info(op)
+	ctx, end := s.begin(ctx, op)
+	defer end()
+	err := s.withTxAs(ctx, actor, func(tx *Tx) error {
+		if _, err := lockSong(tx, 0, queries.LockSong, id); err != nil {
+			return err
+		}
+		var values []byte
+		if err := tx.Get(&values, queries.GetRevisionValues, id, revision); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storage.ErrRevisionNotFound
+			}
+			return err
+		}
+		target, err := songValues(values)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return storage.ErrRevisionEmpty
+		}
+
+		// The group may have been renamed since; it is kept unless it is gone.
+		var exists bool
+		if err := tx.Get(&exists, queries.GroupExists, target.GroupID); err != nil {
+			return err
+		}
+		groupID := target.GroupID
+		if !exists {
+			if groupID, err = ensureGroup(tx, target.GroupName); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(queries.SetSongAction, storage.RevisionReverted); err != nil {
+			return err
+		}
+		_, err = tx.Exec(queries.ReplaceSong, groupID, target.SongName, target.ReleaseDate, target.Lyrics, target.YoutubeLink, id)
+		return songConflict(err)
+	})
+	return wrap(ctx, op, err)
+}
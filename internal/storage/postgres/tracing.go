@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("effective-mobile/internal/storage/postgres")
+
+// begin starts the span of the call op and returns the function that ends
+// it and records how long the call took.
+func (s *Storage) begin(ctx context.Context, op string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(semconv.DBSystemPostgreSQL))
+	return ctx, func() {
+		span.End()
+		s.observe(op, start)
+	}
+}
+
+// statement starts the span of one SQL statement, a child of the span of
+// the storage call, with the statement as its db.query.text.
+func statement(ctx context.Context, query string) (context.Context, trace.Span) {
+	name, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return tracer.Start(ctx, strings.ToUpper(name),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBQueryText(query)),
+	)
+}
+
+// finish ends the span of a statement and marks it failed by err. No rows
+// is an answer rather than a failure.
+func finish(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracedDB runs every statement in a span of its own.
+type tracedDB struct {
+	*sqlx.DB
+}
+
+func (db tracedDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := statement(ctx, query)
+	err := db.DB.GetContext(ctx, dest, query, args...)
+	finish(span, err)
+	return err
+}
+
+func (db tracedDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := statement(ctx, query)
+	err := db.DB.SelectContext(ctx, dest, query, args...)
+	finish(span, err)
+	return err
+}
+
+func (db tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := statement(ctx, query)
+	res, err := db.DB.ExecContext(ctx, query, args...)
+	finish(span, err)
+	return res, err
+}
+
+func (db tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := statement(ctx, query)
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	finish(span, row.Err())
+	return row
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"effective-mobile/internal/storage"
+	"effective-mobile/internal/storage/postgres/queries"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// txAttempts bounds how often a transaction runs when it keeps failing
+	// with serialization failures or deadlocks.
+	txAttempts = 3
+	// txRetryDelay is the pause before the second attempt; it doubles with
+	// every further one.
+	txRetryDelay = 20 * time.Millisecond
+)
+
+// Tx is a transaction whose statements run under the context of its
+// attempt, so they share its query timeout and cancellation.
+type Tx struct {
+	tx  *sqlx.Tx
+	ctx context.Context
+}
+
+func (t *Tx) Get(dest interface{}, query string, args ...interface{}) error {
+	ctx, span := statement(t.ctx, query)
+	err := t.tx.GetContext(ctx, dest, query, args...)
+	finish(span, err)
+	return err
+}
+
+func (t *Tx) Select(dest interface{}, query string, args ...interface{}) error {
+	ctx, span := statement(t.ctx, query)
+	err := t.tx.SelectContext(ctx, dest, query, args...)
+	finish(span, err)
+	return err
+}
+
+func (t *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := statement(t.ctx, query)
+	res, err := t.tx.ExecContext(ctx, query, args...)
+	finish(span, err)
+	return res, err
+}
+
+func (t *Tx) QueryRowx(query string, args ...interface{}) *sqlx.Row {
+	ctx, span := statement(t.ctx, query)
+	row := t.tx.QueryRowxContext(ctx, query, args...)
+	finish(span, row.Err())
+	return row
+}
+
+func (t *Tx) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	ctx, span := statement(t.ctx, query)
+	rows, err := t.tx.QueryxContext(ctx, query, args...)
+	finish(span, err)
+	return rows, err
+}
+
+// WithTx runs fn in a transaction and commits it when fn succeeds. The
+// transaction is rolled back when fn fails or panics. After a serialization
+// failure or a deadlock it runs again from the start, at most txAttempts
+// times, so fn must not carry state from one attempt to the next. Every
+// attempt is bounded by the query timeout.
+func (s *Storage) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	return retryTx(ctx, func() error {
+		return s.runTx(ctx, fn)
+	})
+}
+
+// retryTx calls attempt until it succeeds or fails with an error other than
+// a serialization failure or a deadlock, at most txAttempts times. When ctx
+// is done while it waits for the next attempt, it fails with a
+// *storage.Error of kind storage.ErrTimeout or storage.ErrCanceled.
+func retryTx(ctx context.Context, attempt func() error) error {
+	const op = "storage.postgres.WithTx"
+
+	delay := txRetryDelay
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n == txAttempts || !(isPQError(err, serializationFailure) || isPQError(err, deadlockDetected)) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return wrap(ctx, op, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (s *Storage) runTx(ctx context.Context, fn func(tx *Tx) error) error {
+	ctx, cancel := s.timeout(ctx)
+	defer cancel()
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(&Tx{tx: tx, ctx: ctx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withTxAs is WithTx for song writes, which the songs_revision trigger
+// records in the history as made by actor.
+func (s *Storage) withTxAs(ctx context.Context, actor storage.Actor, fn func(tx *Tx) error) error {
+	return s.WithTx(ctx, func(tx *Tx) error {
+		if _, err := tx.Exec(queries.SetActor, actor.Subject, actor.RequestID); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// timeout bounds ctx by the query timeout, if there is one.
+func (s *Storage) timeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.cfg.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.cfg.QueryTimeout)
+}
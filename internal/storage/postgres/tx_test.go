@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"effective-mobile/internal/storage"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestRetryTx(t *testing.T) {
+	serialization := &pq.Error{Code: serializationFailure}
+	deadlock := &pq.Error{Code: deadlockDetected}
+	unique := &pq.Error{Code: uniqueViolation}
+	tests := []struct {
+		name string
+		// errs are the results of the attempts in order; attempts past the
+		// end succeed.
+		errs  []error
+		calls int
+		want  error
+	}{
+		{name: "success", calls: 1},
+		{name: "serialization failure", errs: []error{serialization}, calls: 2},
+		{name: "deadlock", errs: []error{deadlock, deadlock}, calls: 3},
+		{name: "other error", errs: []error{unique}, calls: 1, want: unique},
+		{name: "gives up", errs: []error{serialization, deadlock, serialization, nil}, calls: txAttempts, want: serialization},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryTx(context.Background(), func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if err != tt.want {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetryTxContextDone(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-expired.Done()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		kind error
+	}{
+		{name: "canceled", ctx: canceled, kind: storage.ErrCanceled},
+		{name: "timed out", ctx: expired, kind: storage.ErrTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryTx(tt.ctx, func() error {
+				calls++
+				return &pq.Error{Code: serializationFailure}
+			})
+			var storageErr *storage.Error
+			if !errors.As(err, &storageErr) {
+				t.Fatalf("error = %v, want a *storage.Error", err)
+			}
+			if storageErr.Kind != tt.kind {
+				t.Errorf("kind = %v, want %v", storageErr.Kind, tt.kind)
+			}
+			if calls != 1 {
+				t.Errorf("calls = %d, want 1", calls)
+			}
+		})
+	}
+}
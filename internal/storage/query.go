@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type MatchMode int
+
+const (
+	// MatchExact compares names as is.
+	MatchExact MatchMode = iota
+	// MatchPrefix matches names starting with the value, ignoring case.
+	MatchPrefix
+	// MatchContains matches names containing the value, ignoring case.
+	MatchContains
+)
+
+// SongFilter describes the filters of the library listing. Zero fields are
+// ignored; a song must satisfy every set field, and any of the values of a
+// multi-valued field. Dates are in the storage format (YYYY-MM-DD).
+type SongFilter struct {
+	GroupIDs []uint
+	// AlbumIDs keeps the songs on any of the albums.
+	AlbumIDs []uint
+	Groups   []string
+	Songs    []string
+	// NameMatch selects how Groups and Songs are compared.
+	NameMatch    MatchMode
+	ReleaseDates []string
+	// ReleasedFrom and ReleasedTo bound the release date, both inclusive.
+	ReleasedFrom string
+	ReleasedTo   string
+	HasLyrics    *bool
+	HasLink      *bool
+	// LyricsWords must all occur in the lyrics, ignoring case.
+	LyricsWords []string
+}
+
+// SearchResult is a song found by a full-text search.
+type SearchResult struct {
+	Song
+	// Rank orders the results, higher is more relevant.
+	Rank float64 `json:"rank"`
+	// Snippets are the matching verses with the matched words wrapped in
+	// HighlightStart and HighlightStop.
+	Snippets []Snippet `json:"snippets"`
+}
+
+type Snippet struct {
+	// Verse is the 1-based number of the verse in the lyrics.
+	Verse int    `json:"verse"`
+	Text  string `json:"text"`
+}
+
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// Sortable song fields, named as in the API. Text fields sort by their bytes,
+// so capitals come before lower case in every backend.
+const (
+	SortByID          = "id"
+	SortByGroup       = "group"
+	SortBySong        = "song"
+	SortByReleaseDate = "releaseDate"
+	SortByLyrics      = "lyrics"
+	SortByYoutubeLink = "youtubeLink"
+)
+
+var SortFields = []string{SortByID, SortByGroup, SortBySong, SortByReleaseDate, SortByLyrics, SortByYoutubeLink}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Page selects a window of the library listing. Either Offset or one of the
+// AfterID/BeforeID cursors is used; cursors require the listing to be sorted
+// by ID only. Songs are always ordered by ID after the Sort fields, so pages
+// are stable.
+type Page struct {
+	Limit  int
+	Offset int
+	// AfterID returns the songs that follow this ID in the sort order.
+	AfterID uint
+	// BeforeID returns the songs that precede this ID in the sort order.
+	BeforeID uint
+	Sort     []Sort
+}
+
+// KeysetSort reports whether the page is ordered by ID alone, which is
+// required for cursor pagination, and whether that order is descending.
+func (p Page) KeysetSort() (ok bool, desc bool) {
+	switch {
+	case len(p.Sort) == 0:
+		return true, false
+	case len(p.Sort) == 1 && p.Sort[0].Field == SortByID:
+		return true, p.Sort[0].Desc
+	default:
+		return false, false
+	}
+}
+
+var ErrInvalidPage = errors.New("invalid page")
+
+// ValidatePage checks the sort fields and that cursors are only combined with ID ordering.
+func ValidatePage(page Page) error {
+	if page.Limit < 0 || page.Offset < 0 {
+		return fmt.Errorf("%w: negative limit or offset", ErrInvalidPage)
+	}
+	for _, sort := range page.Sort {
+		if !slices.Contains(SortFields, sort.Field) {
+			return fmt.Errorf("%w: unknown sort field %q", ErrInvalidPage, sort.Field)
+		}
+	}
+	if page.AfterID != 0 || page.BeforeID != 0 {
+		if page.AfterID != 0 && page.BeforeID != 0 {
+			return fmt.Errorf("%w: only one cursor may be set", ErrInvalidPage)
+		}
+		if page.Offset != 0 {
+			return fmt.Errorf("%w: cursor cannot be combined with offset", ErrInvalidPage)
+		}
+		if ok, _ := page.KeysetSort(); !ok {
+			return fmt.Errorf("%w: cursor pagination requires sorting by id", ErrInvalidPage)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRevisionNotFound is returned for a revision the song does not have.
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrRevisionEmpty is returned when reverting to a revision that took
+	// the song out of the library and so holds no values to revert to.
+	ErrRevisionEmpty = errors.New("revision holds no song")
+)
+
+// Actor names who makes a change and the request it is made in. Every write
+// of a song records it in the song's history.
+type Actor struct {
+	Subject   string
+	RequestID string
+}
+
+// SystemActor makes the changes nobody asked for, such as purging the trash.
+const SystemActor = "system"
+
+// Revision actions.
+const (
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+	RevisionReverted = "reverted"
+	RevisionPurged   = "purged"
+	// RevisionBaseline starts the history of songs that existed before
+	// revisions were recorded.
+	RevisionBaseline = "baseline"
+)
+
+// SongRevision is one recorded change of a song. Old is nil for changes that
+// brought the song into the library and New for changes that took it out.
+type SongRevision struct {
+	SongID    uint      `json:"songId"`
+	Revision  int       `json:"revision"`
+	Action    string    `json:"action"`
+	Old       *Song     `json:"old"`
+	New       *Song     `json:"new"`
+	Actor     string    `json:"actor"`
+	RequestID string    `json:"requestId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSongNotFound  = errors.New("song not found")
+	ErrSongExists    = errors.New("song already exists")
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group already exists")
+	ErrGroupInUse    = errors.New("group still has songs or albums")
+	// ErrVersionMismatch is returned by conditional writes when the song
+	// has changed since the version the caller has seen.
+	ErrVersionMismatch = errors.New("song has been changed meanwhile")
+)
+
+type Song struct {
+	ID uint `db:"id" json:"id"`
+	// GroupID is set by the storage; songs are assigned to groups by GroupName,
+	// and a group with that name is created when it does not exist yet.
+	GroupID     uint   `db:"group_id" json:"groupId"`
+	GroupName   string `db:"group_name" json:"group"`
+	SongName    string `db:"song_name" json:"song"`
+	ReleaseDate string `db:"release_date" json:"releaseDate"`
+	Lyrics      string `db:"lyrics" json:"lyrics"`
+	YoutubeLink string `db:"youtube_link" json:"youtubeLink"`
+	// Version is set by the storage; it starts at 1 and goes up with every
+	// write that changes the song, renaming its group included.
+	Version int `db:"version" json:"version,omitempty"`
+}
+
+// DeletedSong is a song in the trash.
+type DeletedSong struct {
+	Song
+	DeletedAt time.Time `db:"deleted_at" json:"deletedAt"`
+}
+
+// SongUpdate holds a partial update of a song. Nil fields are left unchanged;
+// an empty ReleaseDate, Lyrics or YoutubeLink clears the field. ReleaseDate
+// is in the storage format (YYYY-MM-DD).
+type SongUpdate struct {
+	GroupName   *string
+	SongName    *string
+	ReleaseDate *string
+	Lyrics      *string
+	YoutubeLink *string
+	// Verses edits the stored lyrics verse by verse; it cannot be combined
+	// with Lyrics.
+	Verses []VerseEdit
+}
+
+func (u SongUpdate) IsEmpty() bool {
+	return u.GroupName == nil && u.SongName == nil && u.ReleaseDate == nil && u.Lyrics == nil && u.YoutubeLink == nil && len(u.Verses) == 0
+}
+
+// SongBatchResult is the outcome of one song of SongStore.InsertSongs. A
+// zero ID means the song already existed and was skipped.
+type SongBatchResult struct {
+	ID      uint
+	Created bool
+}
+
+// SongStore is implemented by every song storage backend. A group has at
+// most one song with a given name; names are compared by NormalizeName.
+// Writes that would break this fail with ErrSongExists. Every write that
+// changes a song records a SongRevision in the same transaction.
+//
+// The writes taking a version only apply when it is zero or the current
+// version of the song, and fail with ErrVersionMismatch otherwise.
+type SongStore interface {
+	// InsertSong stores a new song and returns its ID.
+	InsertSong(ctx context.Context, song Song, actor Actor) (uint, error)
+	// UpsertSong stores a new song or, when the group already has a song
+	// with that name, overwrites the existing one. It returns the ID of the
+	// song and whether it was created.
+	UpsertSong(ctx context.Context, song Song, actor Actor) (id uint, created bool, err error)
+	// InsertSongs stores a batch of songs in one transaction. Songs that
+	// already exist are skipped with a zero result, or overwritten when
+	// upsert is set. The batch must not hold the same song twice.
+	InsertSongs(ctx context.Context, songs []Song, upsert bool, actor Actor) ([]SongBatchResult, error)
+	// SelectSongs returns one page of the songs matching the filter and the
+	// total number of matching songs.
+	SelectSongs(ctx context.Context, filter SongFilter, page Page) ([]Song, int, error)
+	// ExportSongs calls fn for every song matching the filter in ID order
+	// without holding all of them in memory, and stops at the first error
+	// fn returns.
+	ExportSongs(ctx context.Context, filter SongFilter, fn func(Song) error) error
+	GetLyrics(ctx context.Context, song string, group string) (string, error)
+	// SearchSongs runs a full-text search over song names and lyrics and
+	// returns one page of results by relevance and the total number of hits.
+	SearchSongs(ctx context.Context, query string, limit int, offset int) ([]SearchResult, int, error)
+	// DeleteSong and DeleteSongByID move the song to the trash and take it
+	// off its albums and playlists. Songs in the trash are left out of every
+	// read and may be added again.
+	DeleteSong(ctx context.Context, song string, group string, version int, actor Actor) error
+	// UpdateSong and UpdateSongByID apply a partial update to a song. Verse
+	// edits fail with ErrVerseOutOfRange or ErrInvalidVerse and change
+	// nothing.
+	UpdateSong(ctx context.Context, song string, group string, update SongUpdate, version int, actor Actor) error
+
+	GetSong(ctx context.Context, id uint) (Song, error)
+	// ReplaceSong overwrites every column of the song with the given ID.
+	ReplaceSong(ctx context.Context, id uint, song Song, version int, actor Actor) error
+	UpdateSongByID(ctx context.Context, id uint, update SongUpdate, version int, actor Actor) error
+	DeleteSongByID(ctx context.Context, id uint, version int, actor Actor) error
+
+	// SelectTrash returns one page of the deleted songs, most recently
+	// deleted first, and the number of songs in the trash.
+	SelectTrash(ctx context.Context, limit int, offset int) ([]DeletedSong, int, error)
+	// RestoreSong takes a song out of the trash. It fails with
+	// ErrSongNotFound when the song is not in the trash and with
+	// ErrSongExists when its group got a song with the same name meanwhile.
+	RestoreSong(ctx context.Context, id uint, actor Actor) error
+	// PurgeSongs removes the songs deleted before the given time for good
+	// and returns their number. Their history is kept.
+	PurgeSongs(ctx context.Context, deletedBefore time.Time) (int, error)
+
+	// SongHistory returns one page of the revisions of a song, newest first,
+	// and their number. It fails with ErrSongNotFound when the song has no
+	// history, and works for deleted and purged songs too.
+	SongHistory(ctx context.Context, id uint, limit int, offset int) ([]SongRevision, int, error)
+	// RevertSong overwrites the song with the values it had after the given
+	// revision and records that as a new revision. The song must not be
+	// deleted.
+	RevertSong(ctx context.Context, id uint, revision int, actor Actor) error
+}
+
+type Group struct {
+	ID          uint     `db:"id" json:"id"`
+	Name        string   `db:"name" json:"name"`
+	Country     string   `db:"country" json:"country"`
+	FormedYear  int      `db:"formed_year" json:"formedYear"`
+	Genres      []string `db:"genres" json:"genres"`
+	Description string   `db:"description" json:"description"`
+}
+
+// GroupUpdate holds a partial update of a group. Nil fields are left unchanged.
+type GroupUpdate struct {
+	Name        *string
+	Country     *string
+	FormedYear  *int
+	Genres      *[]string
+	Description *string
+}
+
+func (u GroupUpdate) IsEmpty() bool {
+	return u.Name == nil && u.Country == nil && u.FormedYear == nil && u.Genres == nil && u.Description == nil
+}
+
+// GroupStore is implemented by storage backends that keep groups as
+// entities. Renaming a group renames it for all of its songs. Group names
+// are unique as compared by NormalizeName, so songs added under "muse" land
+// in the group "Muse".
+type GroupStore interface {
+	InsertGroup(ctx context.Context, group Group) (uint, error)
+	SelectGroups(ctx context.Context, limit int, offset int) ([]Group, int, error)
+	GetGroup(ctx context.Context, id uint) (Group, error)
+	UpdateGroup(ctx context.Context, id uint, update GroupUpdate) error
+	// DeleteGroup fails with ErrGroupInUse while songs, including the ones in
+	// the trash, or albums refer to the group.
+	DeleteGroup(ctx context.Context, id uint) error
+}
+
+// Storage is a backend serving the whole API.
+type Storage interface {
+	SongStore
+	GroupStore
+	AlbumStore
+	PlaylistStore
+	// Connect waits until the backend can serve calls, retrying as long as
+	// ctx allows.
+	Connect(ctx context.Context) error
+	// Ping checks that the backend answers right now.
+	Ping(ctx context.Context) error
+	Stop() error
+}
+
+// VerseSeparator splits lyrics into verses.
+const VerseSeparator = "\n\n"
+
+// NormalizeName returns the form of a group or song name that tells names
+// apart: lower case without surrounding spaces.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Trim(name, " "))
+}
+
+// FormatReleaseDate converts a date from the API format (DD.MM.YYYY) to the storage format (YYYY-MM-DD).
+func FormatReleaseDate(releaseDate string) (string, error) {
+	t, err := time.Parse("02.01.2006", releaseDate)
+	if err != nil {
+		return "", fmt.Errorf("invalid release date %q: %w", releaseDate, err)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// ValidateReleaseDate checks that a date is in the storage format (YYYY-MM-DD).
+func ValidateReleaseDate(releaseDate string) error {
+	if _, err := time.Parse("2006-01-02", releaseDate); err != nil {
+		return fmt.Errorf("invalid release date %q: %w", releaseDate, err)
+	}
+	return nil
+}
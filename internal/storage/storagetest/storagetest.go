@@ -0,0 +1,517 @@
+// Package storagetest holds the contract every storage backend must pass, so
+// the backends cannot drift apart in what they return.
+package storagetest
+
+import (
+	"context"
+	"effective-mobile/internal/storage"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Run runs the contract against the stores newStore returns. Every subtest
+// gets a store of its own, which must be empty.
+func Run(t *testing.T, newStore func(t *testing.T) storage.Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Storage)
+	}{
+		{"InsertAndGet", testInsertAndGet},
+		{"InsertExisting", testInsertExisting},
+		{"SelectSongs", testSelectSongs},
+		{"SortByReleaseDate", testSortByReleaseDate},
+		{"SortByName", testSortByName},
+		{"UpdateSong", testUpdateSong},
+		{"UpdateConflict", testUpdateConflict},
+		{"RejectedWriteKeepsGroups", testRejectedWriteKeepsGroups},
+		{"DeleteSong", testDeleteSong},
+		{"Trash", testTrash},
+		{"Revert", testRevert},
+		{"Versions", testVersions},
+		{"NotFound", testNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStore(t))
+		})
+	}
+}
+
+var actor = storage.Actor{Subject: "storagetest"}
+
+func insert(t *testing.T, s storage.Storage, song storage.Song) uint {
+	t.Helper()
+	id, err := s.InsertSong(context.Background(), song, actor)
+	if err != nil {
+		t.Fatalf("InsertSong(%q, %q): %v", song.GroupName, song.SongName, err)
+	}
+	return id
+}
+
+func get(t *testing.T, s storage.Storage, id uint) storage.Song {
+	t.Helper()
+	song, err := s.GetSong(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetSong(%d): %v", id, err)
+	}
+	return song
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func testInsertAndGet(t *testing.T, s storage.Storage) {
+	want := storage.Song{
+		GroupName:   "Muse",
+		SongName:    "Supermassive Black Hole",
+		ReleaseDate: "2006-07-16",
+		Lyrics:      "Ooh baby, don't you know I suffer?\n\nOoh baby, can you hear me moan?",
+		YoutubeLink: "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+	id := insert(t, s, want)
+	if id == 0 {
+		t.Fatal("InsertSong returned ID 0")
+	}
+
+	got := get(t, s, id)
+	if got.GroupID == 0 {
+		t.Error("GetSong: GroupID not set")
+	}
+	want.ID, want.GroupID, want.Version = id, got.GroupID, 1
+	if got != want {
+		t.Errorf("GetSong = %+v, want %+v", got, want)
+	}
+
+	lyrics, err := s.GetLyrics(context.Background(), "supermassive black hole", " muse ")
+	if err != nil {
+		t.Fatalf("GetLyrics: %v", err)
+	}
+	if lyrics != want.Lyrics {
+		t.Errorf("GetLyrics = %q, want %q", lyrics, want.Lyrics)
+	}
+
+	other := insert(t, s, storage.Song{GroupName: "MUSE", SongName: "Uprising"})
+	if got := get(t, s, other); got.GroupID != want.GroupID || got.GroupName != "Muse" {
+		t.Errorf("song of group %q got group %d %q, want %d %q", "MUSE", got.GroupID, got.GroupName, want.GroupID, "Muse")
+	}
+}
+
+func testInsertExisting(t *testing.T, s storage.Storage) {
+	insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising"})
+	_, err := s.InsertSong(context.Background(), storage.Song{GroupName: " muse", SongName: "UPRISING "}, actor)
+	if !errors.Is(err, storage.ErrSongExists) {
+		t.Errorf("InsertSong of an existing song: err = %v, want %v", err, storage.ErrSongExists)
+	}
+	insert(t, s, storage.Song{GroupName: "Placebo", SongName: "Uprising"})
+
+	songs, total, err := s.SelectSongs(context.Background(), storage.SongFilter{}, storage.Page{})
+	if err != nil {
+		t.Fatalf("SelectSongs: %v", err)
+	}
+	if total != 2 || len(songs) != 2 {
+		t.Errorf("SelectSongs returned %d songs of %d, want 2 of 2", len(songs), total)
+	}
+}
+
+func testSelectSongs(t *testing.T, s storage.Storage) {
+	var ids []uint
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		ids = append(ids, insert(t, s, storage.Song{GroupName: "Muse", SongName: name}))
+	}
+	insert(t, s, storage.Song{GroupName: "Placebo", SongName: "f"})
+
+	filter := storage.SongFilter{Groups: []string{"Muse"}}
+	page := storage.Page{Limit: 2, Offset: 1, Sort: []storage.Sort{{Field: storage.SortByID, Desc: true}}}
+	songs, total, err := s.SelectSongs(context.Background(), filter, page)
+	if err != nil {
+		t.Fatalf("SelectSongs: %v", err)
+	}
+	if total != 5 {
+		t.Errorf("SelectSongs total = %d, want 5", total)
+	}
+	if got, want := songIDs(songs), []uint{ids[3], ids[2]}; !slices.Equal(got, want) {
+		t.Errorf("SelectSongs IDs = %v, want %v", got, want)
+	}
+
+	page = storage.Page{Limit: 2, AfterID: ids[1]}
+	songs, _, err = s.SelectSongs(context.Background(), filter, page)
+	if err != nil {
+		t.Fatalf("SelectSongs after %d: %v", ids[1], err)
+	}
+	if got, want := songIDs(songs), []uint{ids[2], ids[3]}; !slices.Equal(got, want) {
+		t.Errorf("SelectSongs after %d IDs = %v, want %v", ids[1], got, want)
+	}
+
+	_, _, err = s.SelectSongs(context.Background(), filter, storage.Page{Sort: []storage.Sort{{Field: "rating"}}})
+	if !errors.Is(err, storage.ErrInvalidPage) {
+		t.Errorf("SelectSongs by an unknown field: err = %v, want %v", err, storage.ErrInvalidPage)
+	}
+}
+
+// testSortByReleaseDate checks that songs without a release date come last
+// in ascending order and first in descending order.
+func testSortByReleaseDate(t *testing.T, s storage.Storage) {
+	undated := insert(t, s, storage.Song{GroupName: "Muse", SongName: "a"})
+	later := insert(t, s, storage.Song{GroupName: "Muse", SongName: "b", ReleaseDate: "2009-09-07"})
+	earlier := insert(t, s, storage.Song{GroupName: "Muse", SongName: "c", ReleaseDate: "2006-07-16"})
+
+	for _, tt := range []struct {
+		desc bool
+		want []uint
+	}{
+		{false, []uint{earlier, later, undated}},
+		{true, []uint{undated, later, earlier}},
+	} {
+		page := storage.Page{Sort: []storage.Sort{{Field: storage.SortByReleaseDate, Desc: tt.desc}}}
+		songs, _, err := s.SelectSongs(context.Background(), storage.SongFilter{}, page)
+		if err != nil {
+			t.Fatalf("SelectSongs: %v", err)
+		}
+		if got := songIDs(songs); !slices.Equal(got, tt.want) {
+			t.Errorf("SelectSongs desc=%t IDs = %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
+
+// testSortByName checks that names sort by their bytes, capitals first,
+// whatever the collation of the backend is.
+func testSortByName(t *testing.T, s storage.Storage) {
+	names := []string{"cherry", "Banana", "apple", "Date"}
+	want := []string{"Banana", "Date", "apple", "cherry"}
+	for _, name := range names {
+		insert(t, s, storage.Song{GroupName: name, SongName: name})
+	}
+
+	for _, field := range []string{storage.SortByGroup, storage.SortBySong} {
+		page := storage.Page{Sort: []storage.Sort{{Field: field}}}
+		songs, _, err := s.SelectSongs(context.Background(), storage.SongFilter{}, page)
+		if err != nil {
+			t.Fatalf("SelectSongs: %v", err)
+		}
+		var got []string
+		for _, song := range songs {
+			got = append(got, song.SongName)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("SelectSongs by %s = %q, want %q", field, got, want)
+		}
+	}
+
+	groups, _, err := s.SelectGroups(context.Background(), len(names), 0)
+	if err != nil {
+		t.Fatalf("SelectGroups: %v", err)
+	}
+	var got []string
+	for _, group := range groups {
+		got = append(got, group.Name)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("SelectGroups = %q, want %q", got, want)
+	}
+}
+
+func testUpdateSong(t *testing.T, s storage.Storage) {
+	id := insert(t, s, storage.Song{
+		GroupName:   "Muse",
+		SongName:    "Uprising",
+		ReleaseDate: "2009-09-07",
+		Lyrics:      "Paranoia is in bloom",
+	})
+
+	update := storage.SongUpdate{SongName: ptr("Resistance"), ReleaseDate: ptr("")}
+	if err := s.UpdateSong(context.Background(), "uprising", "muse", update, 0, actor); err != nil {
+		t.Fatalf("UpdateSong: %v", err)
+	}
+	got := get(t, s, id)
+	if got.SongName != "Resistance" || got.ReleaseDate != "" || got.Lyrics != "Paranoia is in bloom" {
+		t.Errorf("UpdateSong left %+v, want the song renamed and the release date cleared", got)
+	}
+
+	update = storage.SongUpdate{GroupName: ptr("Placebo"), YoutubeLink: ptr("https://youtu.be/w8KQmps-Sog")}
+	if err := s.UpdateSongByID(context.Background(), id, update, 0, actor); err != nil {
+		t.Fatalf("UpdateSongByID: %v", err)
+	}
+	got = get(t, s, id)
+	if got.GroupName != "Placebo" || got.YoutubeLink != "https://youtu.be/w8KQmps-Sog" {
+		t.Errorf("UpdateSongByID left %+v, want the song moved to Placebo with the link set", got)
+	}
+
+	song := storage.Song{GroupName: "Muse", SongName: "Uprising", Lyrics: "They will not force us"}
+	if err := s.ReplaceSong(context.Background(), id, song, 0, actor); err != nil {
+		t.Fatalf("ReplaceSong: %v", err)
+	}
+	got = get(t, s, id)
+	song.ID, song.GroupID, song.Version = id, got.GroupID, got.Version
+	if got != song {
+		t.Errorf("ReplaceSong left %+v, want %+v", got, song)
+	}
+}
+
+func testUpdateConflict(t *testing.T, s storage.Storage) {
+	insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising"})
+	id := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Resistance"})
+	before := get(t, s, id)
+
+	err := s.UpdateSongByID(context.Background(), id, storage.SongUpdate{SongName: ptr(" uprising")}, 0, actor)
+	if !errors.Is(err, storage.ErrSongExists) {
+		t.Errorf("UpdateSongByID to an existing name: err = %v, want %v", err, storage.ErrSongExists)
+	}
+	err = s.ReplaceSong(context.Background(), id, storage.Song{GroupName: "MUSE", SongName: "Uprising"}, 0, actor)
+	if !errors.Is(err, storage.ErrSongExists) {
+		t.Errorf("ReplaceSong with an existing name: err = %v, want %v", err, storage.ErrSongExists)
+	}
+	if got := get(t, s, id); got != before {
+		t.Errorf("rejected writes changed the song to %+v, want %+v", got, before)
+	}
+}
+
+// testRejectedWriteKeepsGroups checks that a write that fails does not leave
+// behind the group it named.
+func testRejectedWriteKeepsGroups(t *testing.T, s storage.Storage) {
+	id := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising", Lyrics: "Paranoia is in bloom"})
+
+	update := storage.SongUpdate{
+		GroupName: ptr("Placebo"),
+		Verses:    []storage.VerseEdit{{Op: "delete", Verse: 5}},
+	}
+	if err := s.UpdateSongByID(context.Background(), id, update, 0, actor); err == nil {
+		t.Fatal("UpdateSongByID deleting a missing verse: err = nil")
+	}
+
+	groups, total, err := s.SelectGroups(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatalf("SelectGroups: %v", err)
+	}
+	if total != 1 || len(groups) != 1 || groups[0].Name != "Muse" {
+		t.Errorf("SelectGroups = %+v of %d, want only Muse", groups, total)
+	}
+}
+
+func testDeleteSong(t *testing.T, s storage.Storage) {
+	byName := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising"})
+	byID := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Resistance"})
+
+	if err := s.DeleteSong(context.Background(), " UPRISING", "muse", 0, actor); err != nil {
+		t.Fatalf("DeleteSong: %v", err)
+	}
+	if err := s.DeleteSongByID(context.Background(), byID, 0, actor); err != nil {
+		t.Fatalf("DeleteSongByID: %v", err)
+	}
+	for _, id := range []uint{byName, byID} {
+		if _, err := s.GetSong(context.Background(), id); !errors.Is(err, storage.ErrSongNotFound) {
+			t.Errorf("GetSong(%d) after delete: err = %v, want %v", id, err, storage.ErrSongNotFound)
+		}
+	}
+	if err := s.DeleteSongByID(context.Background(), byID, 0, actor); !errors.Is(err, storage.ErrSongNotFound) {
+		t.Errorf("DeleteSongByID twice: err = %v, want %v", err, storage.ErrSongNotFound)
+	}
+
+	songs, total, err := s.SelectSongs(context.Background(), storage.SongFilter{}, storage.Page{})
+	if err != nil {
+		t.Fatalf("SelectSongs: %v", err)
+	}
+	if total != 0 || len(songs) != 0 {
+		t.Errorf("SelectSongs after delete returned %d songs of %d, want none", len(songs), total)
+	}
+
+	insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising"})
+}
+
+// testTrash checks that purging removes only the songs deleted before the
+// cutoff and that restoring brings a song back unless its name is taken.
+// The cutoff is read from the trash, so it follows the clock of the backend.
+func testTrash(t *testing.T, s storage.Storage) {
+	first := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising"})
+	second := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Resistance"})
+	if err := s.DeleteSongByID(context.Background(), first, 0, actor); err != nil {
+		t.Fatalf("DeleteSongByID(%d): %v", first, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := s.DeleteSongByID(context.Background(), second, 0, actor); err != nil {
+		t.Fatalf("DeleteSongByID(%d): %v", second, err)
+	}
+
+	trash, total, err := s.SelectTrash(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("SelectTrash: %v", err)
+	}
+	if total != 2 || len(trash) != 2 || trash[0].ID != second || trash[1].ID != first {
+		t.Fatalf("SelectTrash = %+v of %d, want %d and then %d", trash, total, second, first)
+	}
+
+	if n, err := s.PurgeSongs(context.Background(), trash[1].DeletedAt); err != nil || n != 0 {
+		t.Errorf("PurgeSongs at the first deletion = %d, %v; want 0 since the cutoff is exclusive", n, err)
+	}
+	if n, err := s.PurgeSongs(context.Background(), trash[0].DeletedAt); err != nil || n != 1 {
+		t.Errorf("PurgeSongs at the second deletion = %d, %v; want 1", n, err)
+	}
+	if err := s.RestoreSong(context.Background(), first, actor); !errors.Is(err, storage.ErrSongNotFound) {
+		t.Errorf("RestoreSong of a purged song: err = %v, want %v", err, storage.ErrSongNotFound)
+	}
+
+	taken := insert(t, s, storage.Song{GroupName: "muse", SongName: "resistance"})
+	if err := s.RestoreSong(context.Background(), second, actor); !errors.Is(err, storage.ErrSongExists) {
+		t.Errorf("RestoreSong over a song with its name: err = %v, want %v", err, storage.ErrSongExists)
+	}
+	if err := s.DeleteSongByID(context.Background(), taken, 0, actor); err != nil {
+		t.Fatalf("DeleteSongByID(%d): %v", taken, err)
+	}
+	if err := s.RestoreSong(context.Background(), second, actor); err != nil {
+		t.Fatalf("RestoreSong: %v", err)
+	}
+	if got := get(t, s, second); got.SongName != "Resistance" || got.Version != 3 {
+		t.Errorf("restored song = %+v, want Resistance at version 3", got)
+	}
+	trash, total, err = s.SelectTrash(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("SelectTrash: %v", err)
+	}
+	if total != 1 || len(trash) != 1 || trash[0].ID != taken {
+		t.Errorf("SelectTrash after restore = %+v of %d, want only %d", trash, total, taken)
+	}
+}
+
+// testRevert checks that reverting records a new revision made by the
+// reverting actor instead of rewriting the history.
+func testRevert(t *testing.T, s storage.Storage) {
+	id := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising", Lyrics: "one"})
+	update := storage.SongUpdate{SongName: ptr("Resistance"), Lyrics: ptr("two")}
+	if err := s.UpdateSongByID(context.Background(), id, update, 0, actor); err != nil {
+		t.Fatalf("UpdateSongByID: %v", err)
+	}
+
+	reverter := storage.Actor{Subject: "reverter", RequestID: "request-1"}
+	if err := s.RevertSong(context.Background(), id, 1, reverter); err != nil {
+		t.Fatalf("RevertSong: %v", err)
+	}
+	got := get(t, s, id)
+	if got.SongName != "Uprising" || got.Lyrics != "one" || got.Version != 3 {
+		t.Errorf("reverted song = %+v, want Uprising with lyrics one at version 3", got)
+	}
+
+	history, total, err := s.SongHistory(context.Background(), id, 10, 0)
+	if err != nil {
+		t.Fatalf("SongHistory: %v", err)
+	}
+	if total != 3 || len(history) != 3 {
+		t.Fatalf("SongHistory = %+v of %d, want 3 revisions", history, total)
+	}
+	latest := history[0]
+	if latest.Revision != 3 || latest.Action != storage.RevisionReverted {
+		t.Errorf("latest revision = %d %s, want 3 %s", latest.Revision, latest.Action, storage.RevisionReverted)
+	}
+	if latest.Actor != reverter.Subject || latest.RequestID != reverter.RequestID {
+		t.Errorf("latest revision by %q in %q, want %q in %q", latest.Actor, latest.RequestID, reverter.Subject, reverter.RequestID)
+	}
+	if latest.Old == nil || latest.Old.SongName != "Resistance" || latest.New == nil || latest.New.SongName != "Uprising" {
+		t.Errorf("latest revision goes from %+v to %+v, want Resistance to Uprising", latest.Old, latest.New)
+	}
+	if history[1].Action != storage.RevisionUpdated || history[1].Actor != actor.Subject {
+		t.Errorf("earlier revision = %s by %q, want it unchanged", history[1].Action, history[1].Actor)
+	}
+
+	if err := s.RevertSong(context.Background(), id, 9, reverter); !errors.Is(err, storage.ErrRevisionNotFound) {
+		t.Errorf("RevertSong to a missing revision: err = %v, want %v", err, storage.ErrRevisionNotFound)
+	}
+}
+
+func testVersions(t *testing.T, s storage.Storage) {
+	id := insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising"})
+
+	if err := s.UpdateSongByID(context.Background(), id, storage.SongUpdate{Lyrics: ptr("Paranoia is in bloom")}, 1, actor); err != nil {
+		t.Fatalf("UpdateSongByID at version 1: %v", err)
+	}
+	if got := get(t, s, id).Version; got != 2 {
+		t.Errorf("version after update = %d, want 2", got)
+	}
+
+	if err := s.UpdateSongByID(context.Background(), id, storage.SongUpdate{Lyrics: ptr("Paranoia is in bloom")}, 2, actor); err != nil {
+		t.Fatalf("UpdateSongByID without changes: %v", err)
+	}
+	if got := get(t, s, id).Version; got != 2 {
+		t.Errorf("version after an update without changes = %d, want 2", got)
+	}
+
+	stale := []struct {
+		name string
+		call func() error
+	}{
+		{"UpdateSong", func() error {
+			return s.UpdateSong(context.Background(), "Uprising", "Muse", storage.SongUpdate{Lyrics: ptr("")}, 1, actor)
+		}},
+		{"UpdateSongByID", func() error {
+			return s.UpdateSongByID(context.Background(), id, storage.SongUpdate{Lyrics: ptr("")}, 1, actor)
+		}},
+		{"ReplaceSong", func() error {
+			return s.ReplaceSong(context.Background(), id, storage.Song{GroupName: "Muse", SongName: "Uprising"}, 1, actor)
+		}},
+		{"DeleteSong", func() error {
+			return s.DeleteSong(context.Background(), "Uprising", "Muse", 1, actor)
+		}},
+		{"DeleteSongByID", func() error {
+			return s.DeleteSongByID(context.Background(), id, 1, actor)
+		}},
+	}
+	for _, tt := range stale {
+		if err := tt.call(); !errors.Is(err, storage.ErrVersionMismatch) {
+			t.Errorf("%s at a stale version: err = %v, want %v", tt.name, err, storage.ErrVersionMismatch)
+		}
+	}
+	if got := get(t, s, id); got.Version != 2 || got.Lyrics != "Paranoia is in bloom" {
+		t.Errorf("writes at a stale version changed the song to %+v", got)
+	}
+
+	if err := s.DeleteSongByID(context.Background(), id, 2, actor); err != nil {
+		t.Errorf("DeleteSongByID at the current version: %v", err)
+	}
+}
+
+func testNotFound(t *testing.T, s storage.Storage) {
+	insert(t, s, storage.Song{GroupName: "Muse", SongName: "Uprising"})
+	const missing = 1 << 30
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"GetSong", func() error {
+			_, err := s.GetSong(context.Background(), missing)
+			return err
+		}},
+		{"GetLyrics", func() error {
+			_, err := s.GetLyrics(context.Background(), "Resistance", "Muse")
+			return err
+		}},
+		{"UpdateSong", func() error {
+			return s.UpdateSong(context.Background(), "Uprising", "Placebo", storage.SongUpdate{Lyrics: ptr("")}, 0, actor)
+		}},
+		{"UpdateSongByID", func() error {
+			return s.UpdateSongByID(context.Background(), missing, storage.SongUpdate{Lyrics: ptr("")}, 0, actor)
+		}},
+		{"ReplaceSong", func() error {
+			return s.ReplaceSong(context.Background(), missing, storage.Song{GroupName: "Muse", SongName: "Resistance"}, 0, actor)
+		}},
+		{"DeleteSong", func() error {
+			return s.DeleteSong(context.Background(), "Resistance", "Muse", 0, actor)
+		}},
+		{"DeleteSongByID", func() error {
+			return s.DeleteSongByID(context.Background(), missing, 0, actor)
+		}},
+	}
+	for _, tt := range calls {
+		if err := tt.call(); !errors.Is(err, storage.ErrSongNotFound) {
+			t.Errorf("%s of a missing song: err = %v, want %v", tt.name, err, storage.ErrSongNotFound)
+		}
+	}
+}
+
+func songIDs(songs []storage.Song) []uint {
+	ids := make([]uint, len(songs))
+	for i, song := range songs {
+		ids[i] = song.ID
+	}
+	return ids
+}
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE songs
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(song_name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(lyrics, '')), 'B')
+    ) STORED;
+
+CREATE INDEX songs_search_vector_idx ON songs USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX IF EXISTS songs_search_vector_idx;
+ALTER TABLE songs DROP COLUMN IF EXISTS search_vector;
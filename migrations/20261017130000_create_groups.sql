@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE groups (
+                        id SERIAL PRIMARY KEY,
+                        name VARCHAR(255) NOT NULL UNIQUE,
+                        country VARCHAR(255),
+                        formed_year INTEGER,
+                        genres TEXT[] NOT NULL DEFAULT '{}',
+                        description TEXT
+);
+
+INSERT INTO groups (name)
+SELECT DISTINCT group_name FROM songs;
+
+ALTER TABLE songs ADD COLUMN group_id INTEGER REFERENCES groups (id) ON DELETE RESTRICT;
+
+UPDATE songs SET group_id = groups.id
+FROM groups
+WHERE groups.name = songs.group_name;
+
+ALTER TABLE songs ALTER COLUMN group_id SET NOT NULL;
+ALTER TABLE songs DROP COLUMN group_name;
+
+CREATE INDEX songs_group_id_idx ON songs (group_id);
+
+-- songs_view exposes songs with the name of their group for reads. The group
+-- name goes first so that columns added to songs later can be appended with
+-- CREATE OR REPLACE VIEW.
+CREATE VIEW songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id;
+
+-- +goose Down
+DROP VIEW IF EXISTS songs_view;
+
+ALTER TABLE songs ADD COLUMN group_name VARCHAR(255);
+
+UPDATE songs SET group_name = groups.name
+FROM groups
+WHERE groups.id = songs.group_id;
+
+ALTER TABLE songs ALTER COLUMN group_name SET NOT NULL;
+ALTER TABLE songs DROP COLUMN group_id;
+
+DROP TABLE IF EXISTS groups;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE albums (
+                        id SERIAL PRIMARY KEY,
+                        title VARCHAR(255) NOT NULL,
+                        group_id INTEGER NOT NULL REFERENCES groups (id) ON DELETE RESTRICT,
+                        release_date DATE,
+                        cover_url TEXT
+);
+
+CREATE INDEX albums_group_id_idx ON albums (group_id);
+
+-- The track number constraint is checked at the end of each statement, so
+-- tracks can be renumbered with a single UPDATE.
+CREATE TABLE album_songs (
+                             album_id INTEGER NOT NULL REFERENCES albums (id) ON DELETE CASCADE,
+                             song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+                             track_number INTEGER NOT NULL CHECK (track_number > 0),
+                             PRIMARY KEY (album_id, song_id),
+                             CONSTRAINT album_songs_track_number_key UNIQUE (album_id, track_number) DEFERRABLE INITIALLY IMMEDIATE
+);
+
+CREATE INDEX album_songs_song_id_idx ON album_songs (song_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS album_songs;
+DROP TABLE IF EXISTS albums;
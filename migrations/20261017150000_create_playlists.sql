@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE playlists (
+                           id SERIAL PRIMARY KEY,
+                           name VARCHAR(255) NOT NULL,
+                           description TEXT,
+                           owner VARCHAR(255) NOT NULL,
+                           public BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX playlists_owner_idx ON playlists (owner);
+
+-- The primary key keeps a song from being added to a playlist twice. As with
+-- album tracks, positions are checked at the end of each statement so entries
+-- can be moved with a single UPDATE.
+CREATE TABLE playlist_songs (
+                                playlist_id INTEGER NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+                                song_id INTEGER NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+                                position INTEGER NOT NULL CHECK (position > 0),
+                                PRIMARY KEY (playlist_id, song_id),
+                                CONSTRAINT playlist_songs_position_key UNIQUE (playlist_id, position) DEFERRABLE INITIALLY IMMEDIATE
+);
+
+CREATE INDEX playlist_songs_song_id_idx ON playlist_songs (song_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS playlist_songs;
+DROP TABLE IF EXISTS playlists;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Groups and songs are told apart by their names in lower case without
+-- surrounding spaces. Rows that already clash by that rule are not merged or
+-- dropped here: the migration fails and lists them, so an operator can rename
+-- or remove them and run it again.
+-- +goose StatementBegin
+DO $$
+DECLARE
+    conflicts text;
+BEGIN
+    SELECT string_agg(format('%L: ids %s', name, ids), '; ')
+    INTO conflicts
+    FROM (SELECT lower(btrim(name)) AS name, string_agg(id::text, ', ' ORDER BY id) AS ids
+          FROM groups
+          GROUP BY lower(btrim(name))
+          HAVING count(*) > 1) AS dup;
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'groups with the same normalized name: %', conflicts
+            USING HINT = 'Rename or merge these groups, then run the migration again.';
+    END IF;
+
+    SELECT string_agg(format('group %s, %L: ids %s', group_id, name, ids), '; ')
+    INTO conflicts
+    FROM (SELECT group_id, lower(btrim(song_name)) AS name, string_agg(id::text, ', ' ORDER BY id) AS ids
+          FROM songs
+          GROUP BY group_id, lower(btrim(song_name))
+          HAVING count(*) > 1) AS dup;
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'songs with the same normalized name in one group: %', conflicts
+            USING HINT = 'Rename or delete these songs, then run the migration again.';
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE groups DROP CONSTRAINT groups_name_key;
+CREATE UNIQUE INDEX groups_name_normalized_key ON groups (lower(btrim(name)));
+CREATE UNIQUE INDEX songs_group_id_song_name_key ON songs (group_id, lower(btrim(song_name)));
+
+-- +goose Down
+DROP INDEX IF EXISTS songs_group_id_song_name_key;
+DROP INDEX IF EXISTS groups_name_normalized_key;
+ALTER TABLE groups ADD CONSTRAINT groups_name_key UNIQUE (name);
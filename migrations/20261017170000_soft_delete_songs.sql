@@ -0,0 +1,36 @@
+-- +goose Up
+-- Deleted songs stay in the trash until they are restored or purged. Only
+-- songs that are not deleted take part in the unique names, so a song can be
+-- added again while an older copy is in the trash.
+ALTER TABLE songs ADD COLUMN deleted_at TIMESTAMPTZ;
+
+DROP INDEX songs_group_id_song_name_key;
+CREATE UNIQUE INDEX songs_group_id_song_name_key ON songs (group_id, lower(btrim(song_name))) WHERE deleted_at IS NULL;
+CREATE INDEX songs_deleted_at_idx ON songs (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE VIEW songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id
+WHERE songs.deleted_at IS NULL;
+
+CREATE VIEW deleted_songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id
+WHERE songs.deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP VIEW IF EXISTS deleted_songs_view;
+DROP VIEW IF EXISTS songs_view;
+
+DELETE FROM songs WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS songs_deleted_at_idx;
+DROP INDEX IF EXISTS songs_group_id_song_name_key;
+CREATE UNIQUE INDEX songs_group_id_song_name_key ON songs (group_id, lower(btrim(song_name)));
+ALTER TABLE songs DROP COLUMN deleted_at;
+
+CREATE VIEW songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id;
@@ -0,0 +1,109 @@
+-- +goose Up
+-- Every change of a song is recorded as an immutable revision in the same
+-- transaction. Writes name the actor and the request in the transaction
+-- local settings app.actor and app.request_id; revisions without an actor
+-- are made by the system. Revisions outlive purged songs, so song_id has
+-- no foreign key.
+CREATE TABLE song_revisions (
+    id         BIGSERIAL PRIMARY KEY,
+    song_id    INTEGER     NOT NULL,
+    revision   INTEGER     NOT NULL,
+    action     VARCHAR(16) NOT NULL,
+    old_values JSONB,
+    new_values JSONB,
+    actor      TEXT        NOT NULL,
+    request_id TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (song_id, revision)
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION song_revision_values(s songs) RETURNS JSONB AS $$
+SELECT jsonb_build_object(
+    'id', s.id,
+    'groupId', s.group_id,
+    'group', (SELECT name FROM groups WHERE id = s.group_id),
+    'song', s.song_name,
+    'releaseDate', COALESCE(to_char(s.release_date, 'YYYY-MM-DD'), ''),
+    'lyrics', COALESCE(s.lyrics, ''),
+    'youtubeLink', COALESCE(s.youtube_link, '')
+)
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION record_song_revision() RETURNS trigger AS $$
+DECLARE
+    target     INTEGER;
+    act        TEXT;
+    old_values JSONB;
+    new_values JSONB;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        target := NEW.id;
+        act := 'created';
+        new_values := song_revision_values(NEW);
+    ELSIF TG_OP = 'DELETE' THEN
+        target := OLD.id;
+        act := 'purged';
+        old_values := song_revision_values(OLD);
+    ELSE
+        target := NEW.id;
+        IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+            act := 'deleted';
+            old_values := song_revision_values(OLD);
+        ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+            act := 'restored';
+            new_values := song_revision_values(NEW);
+        ELSE
+            act := COALESCE(NULLIF(current_setting('app.song_action', true), ''), 'updated');
+            old_values := song_revision_values(OLD);
+            new_values := song_revision_values(NEW);
+            IF old_values = new_values THEN
+                RETURN NULL;
+            END IF;
+        END IF;
+    END IF;
+
+    INSERT INTO song_revisions (song_id, revision, action, old_values, new_values, actor, request_id)
+    VALUES (
+        target,
+        (SELECT COALESCE(max(revision), 0) + 1 FROM song_revisions WHERE song_id = target),
+        act,
+        old_values,
+        new_values,
+        COALESCE(NULLIF(current_setting('app.actor', true), ''), 'system'),
+        COALESCE(current_setting('app.request_id', true), '')
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER songs_revision
+    AFTER INSERT OR UPDATE OR DELETE ON songs
+    FOR EACH ROW EXECUTE FUNCTION record_song_revision();
+
+-- +goose StatementBegin
+CREATE FUNCTION reject_revision_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'song revisions are immutable';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER song_revisions_immutable
+    BEFORE UPDATE OR DELETE ON song_revisions
+    FOR EACH ROW EXECUTE FUNCTION reject_revision_change();
+
+-- Songs that exist already start their history with a baseline revision.
+INSERT INTO song_revisions (song_id, revision, action, new_values, actor)
+SELECT id, 1, 'baseline', song_revision_values(songs), 'migration'
+FROM songs;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS songs_revision ON songs;
+DROP TABLE IF EXISTS song_revisions;
+DROP FUNCTION IF EXISTS reject_revision_change();
+DROP FUNCTION IF EXISTS record_song_revision();
+DROP FUNCTION IF EXISTS song_revision_values(songs);
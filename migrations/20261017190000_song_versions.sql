@@ -0,0 +1,69 @@
+-- +goose Up
+-- version counts the writes of a song for optimistic concurrency control.
+-- Every update that changes the row bumps it, and so does renaming the
+-- group, which changes how the song reads.
+ALTER TABLE songs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose StatementBegin
+CREATE FUNCTION bump_song_version() RETURNS trigger AS $$
+BEGIN
+    IF ROW(NEW.group_id, NEW.song_name, NEW.release_date, NEW.lyrics, NEW.youtube_link, NEW.deleted_at, NEW.version)
+        IS DISTINCT FROM ROW(OLD.group_id, OLD.song_name, OLD.release_date, OLD.lyrics, OLD.youtube_link, OLD.deleted_at, OLD.version) THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER songs_version
+    BEFORE UPDATE ON songs
+    FOR EACH ROW EXECUTE FUNCTION bump_song_version();
+
+-- +goose StatementBegin
+CREATE FUNCTION bump_group_song_versions() RETURNS trigger AS $$
+BEGIN
+    UPDATE songs SET version = version + 1 WHERE group_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER groups_rename_song_versions
+    AFTER UPDATE OF name ON groups
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION bump_group_song_versions();
+
+-- The views pick up the new column.
+CREATE OR REPLACE VIEW songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id
+WHERE songs.deleted_at IS NULL;
+
+CREATE OR REPLACE VIEW deleted_songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id
+WHERE songs.deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS groups_rename_song_versions ON groups;
+DROP FUNCTION IF EXISTS bump_group_song_versions();
+DROP TRIGGER IF EXISTS songs_version ON songs;
+DROP FUNCTION IF EXISTS bump_song_version();
+DROP VIEW IF EXISTS deleted_songs_view;
+DROP VIEW IF EXISTS songs_view;
+ALTER TABLE songs DROP COLUMN version;
+
+CREATE VIEW songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id
+WHERE songs.deleted_at IS NULL;
+
+CREATE VIEW deleted_songs_view AS
+SELECT groups.name AS group_name, songs.*
+FROM songs
+         JOIN groups ON groups.id = songs.group_id
+WHERE songs.deleted_at IS NOT NULL;
@@ -0,0 +1,34 @@
+// Package migrations embeds the goose migrations, so the service can tell
+// whether the schema of its database is current.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// Latest returns the version of the newest migration, the numeric prefix of
+// its file name.
+func Latest() (int64, error) {
+	const op = "migrations.Latest"
+	files, err := fs.Glob(FS, "*.sql")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	var latest int64
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(file, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: migration %q has no version: %w", op, file, err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}